@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
 
 	"github.com/dedpnd/GophKeeper/internal/agent/client"
 	"github.com/dedpnd/GophKeeper/internal/agent/config"
@@ -26,6 +27,11 @@ func main() {
 		log.Fatalln(err)
 	}
 
+	// Non-interactive subcommands keep stdout for their results
+	if len(eCfg.Args) != 0 {
+		os.Exit(runSubcommand(eCfg))
+	}
+
 	fmt.Println("*************************************")
 	fmt.Println("Welcome GophKepeer client")
 	fmt.Printf("Build version: %v \n", buildVersion)
@@ -39,6 +45,39 @@ func main() {
 		fmt.Println("read-file - read all files on your account")
 		fmt.Println("write-file - write file on your account")
 		fmt.Println("delete-file - delete file from your account")
+		fmt.Println("update-file - replace file on your account or shared with you")
+		fmt.Println("sync - sync changed files to the local cache")
+		fmt.Println("watch - print changes of files until interrupted")
+		fmt.Println("resolve - resolve conflicting versions of file")
+		fmt.Println("tui - browse and edit files in full-screen terminal interface")
+		fmt.Println("share-file - share file with another user")
+		fmt.Println("unshare-file - revoke access of another user to file")
+		fmt.Println("shared-files - list files shared with you")
+		fmt.Println("create-org - create organization")
+		fmt.Println("create-vault - create vault in organization")
+		fmt.Println("invite-member - add user to organization")
+		fmt.Println("remove-member - remove user from organization")
+		fmt.Println("list-vaults - list available vaults")
+		fmt.Println("add-emergency - nominate emergency contact")
+		fmt.Println("remove-emergency - remove emergency contact")
+		fmt.Println("list-emergency - list emergency contacts")
+		fmt.Println("request-emergency - request emergency access")
+		fmt.Println("reject-emergency - reject emergency access request")
+		fmt.Println("create-token - create personal access token")
+		fmt.Println("list-tokens - list personal access tokens")
+		fmt.Println("revoke-token - revoke personal access token")
+		fmt.Println("Subcommands for scripts, see README:")
+		fmt.Println("login, ls [prefix], get <id|name>, put, rm <id|name>, search")
+		fmt.Println("folders, mkdir <path>, rmdir <path>, mvdir <path> <name>, mv <id|name> <folder>, tag, untag")
+		fmt.Println("trash, restore <id|name>, empty-trash")
+		fmt.Println("expire <id|name> -at <date> -rotate <days>, expiring [-days n], otp <id|name>")
+		fmt.Println("ssh-agent [-confirm] [-timeout 1h] <id|name>... - serve ssh keys to ssh")
+		fmt.Println("git-credential <get|store|erase> - git credential helper")
+		fmt.Println("run -env NAME=<name>/<field>... -- <command> - run command with secrets in environment")
+		fmt.Println("inject <template> [-out path] - render template with {{ gk \"name\" \"field\" }} references")
+		fmt.Println("import -format keepass|bitwarden|csv|gophkeeper [-type login] [-map field=column,...] <file> - import records")
+		fmt.Println("export -out <path> - write encrypted archive, import -format gophkeeper <path> restores it")
+		fmt.Println("-output json|yaml|table - output format of subcommands")
 		fmt.Println("*************************************")
 	}
 
@@ -47,7 +86,12 @@ func main() {
 		lg.Sugar().Fatalf("failed create client: %s", err.Error())
 	}
 
-	err = core.Run(cl, eCfg.Command)
+	// Target file commands at a team vault
+	cl.Vault = int32(eCfg.Vault)
+	// Read files of the user who granted emergency access
+	cl.Grantor = eCfg.Grantor
+
+	err = core.Run(cl, eCfg)
 	if err != nil {
 		lg.Sugar().Fatalf("failed command from client: %s", err.Error())
 	}
@@ -57,3 +101,31 @@ func main() {
 		lg.Sugar().Fatalf("failed close client: %s", err.Error())
 	}
 }
+
+// runSubcommand runs the non-interactive subcommand and returns the exit
+// code of the process. Errors are written to stderr, or to stdout in the
+// structured output formats.
+func runSubcommand(eCfg *config.ConfigENV) int {
+	cl, err := client.NewClient(eCfg.ServerAddr, eCfg.Certificate, eCfg.JWT)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed create client: %s\n", err.Error())
+		return core.ExitError
+	}
+
+	cl.Vault = int32(eCfg.Vault)
+	cl.Grantor = eCfg.Grantor
+
+	streams := core.StdStreams()
+	streams.Output = eCfg.Output
+
+	err = core.Exec(cl, eCfg, eCfg.Args, streams)
+	if errClose := cl.Close(); errClose != nil && err == nil {
+		err = errClose
+	}
+
+	if err != nil {
+		core.PrintError(streams, err)
+	}
+
+	return core.ExitCode(err)
+}
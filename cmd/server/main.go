@@ -44,7 +44,8 @@ func main() {
 		lg.Fatal(err.Error())
 	}
 
-	err = core.RunGRPCserver(lg, eCfg.Host, eCfg.CertificatePath, eCfg.CertificateKeyPath, eCfg.JWTkey, eCfg.MasterKey, repo)
+	err = core.RunGRPCserver(lg, eCfg.Host, eCfg.CertificatePath, eCfg.CertificateKeyPath, eCfg.JWTkey, eCfg.MasterKey,
+		eCfg.LegacyAuth, eCfg.TrashRetentionDays, repo)
 	if err != nil {
 		lg.Fatal(err.Error())
 	}
@@ -8,6 +8,7 @@ import (
 	"log"
 	"net"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -20,13 +21,16 @@ import (
 	"github.com/dedpnd/GophKeeper/internal/server/core/services"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/recovery"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/selector"
 	"github.com/ory/dockertest/v3"
 	"github.com/ory/dockertest/v3/docker"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
 
 	_ "github.com/lib/pq"
@@ -38,6 +42,12 @@ var testMasterKey = "1234567812345678"
 var testUser = "test"
 var testUserID = 1
 
+// Sizes of the pages of lists, the default one and the maximum of the server.
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
 func TestMain(m *testing.M) {
 	// uses a sensible default on windows (tcp/http) and linux/osx (socket)
 	pool, err := dockertest.NewPool("")
@@ -112,6 +122,7 @@ func TestMain(m *testing.M) {
 type clients struct {
 	user    proto.UserClient
 	storage proto.StorageClient
+	vault   proto.VaultClient
 }
 
 func testServer(ctx context.Context) (clients, func()) {
@@ -128,37 +139,69 @@ func testServer(ctx context.Context) (clients, func()) {
 		lg.Fatal(err.Error())
 	}
 
+	userSvc := services.NewUserService(repo)
+	vaultSvc := services.NewVaultService(repo)
+
 	baseServer := grpc.NewServer(
 		grpc.ChainUnaryInterceptor(
+			recovery.UnaryServerInterceptor(recovery.WithRecoveryHandler(interceptors.RecoveryHandler(lg))),
 			selector.UnaryServerInterceptor(
-				auth.UnaryServerInterceptor(interceptors.GetAuthenticator(testJWTkey)),
+				auth.UnaryServerInterceptor(interceptors.GetAuthenticator(testJWTkey, userSvc)),
 				selector.MatchFunc(interceptors.AuthMatcher),
 			),
+			interceptors.VaultUnaryInterceptor(vaultSvc),
 		),
 		grpc.ChainStreamInterceptor(
+			recovery.StreamServerInterceptor(recovery.WithRecoveryHandler(interceptors.RecoveryHandler(lg))),
 			selector.StreamServerInterceptor(
-				auth.StreamServerInterceptor(interceptors.GetAuthenticator(testJWTkey)),
+				auth.StreamServerInterceptor(interceptors.GetAuthenticator(testJWTkey, userSvc)),
 				selector.MatchFunc(interceptors.AuthMatcher),
 			),
+			interceptors.VaultStreamInterceptor(vaultSvc),
 		),
 	)
-	userSvc := services.NewUserService(repo)
 
 	// Create user service
 	proto.RegisterUserServer(baseServer, &handler.UserHandler{
-		Svc:    *userSvc,
-		Logger: lg,
-		JWTkey: testJWTkey,
+		Svc:        *userSvc,
+		Logger:     lg,
+		SRP:        handler.NewSRPSessions(),
+		JWTkey:     testJWTkey,
+		LegacyAuth: true,
 	})
 
+	// Create event service
+	eventSvc := services.NewEventService(repo)
+	eventCtx, stopEvents := context.WithCancel(ctx)
+	go func() {
+		if err := eventSvc.Run(eventCtx); err != nil {
+			log.Printf("error listening events: %v", err)
+		}
+	}()
+
+	select {
+	case <-eventSvc.Ready():
+	case <-time.After(10 * time.Second):
+		log.Printf("error listening events: timeout")
+	}
+
 	// Create storage service
 	storageSvc := services.NewStorageService(repo)
 	proto.RegisterStorageServer(baseServer, &handler.StorageHandler{
 		Svc:       *storageSvc,
+		UserSvc:   *userSvc,
+		Events:    eventSvc,
 		Logger:    lg,
 		MasterKey: testMasterKey,
 	})
 
+	// Create vault service
+	proto.RegisterVaultServer(baseServer, &handler.VaultHandler{
+		Svc:     *vaultSvc,
+		UserSvc: *userSvc,
+		Logger:  lg,
+	})
+
 	go func() {
 		if err := baseServer.Serve(lis); err != nil {
 			log.Printf("error serving server: %v", err)
@@ -180,14 +223,17 @@ func testServer(ctx context.Context) (clients, func()) {
 			log.Printf("error closing listener: %v", err)
 		}
 		baseServer.Stop()
+		stopEvents()
 	}
 
 	uClient := proto.NewUserClient(conn)
 	sClient := proto.NewStorageClient(conn)
+	vClient := proto.NewVaultClient(conn)
 
 	return clients{
 		user:    uClient,
 		storage: sClient,
+		vault:   vClient,
 	}, closer
 }
 
@@ -661,7 +707,914 @@ func TestDeleteFileStorage(t *testing.T) {
 	}
 }
 
+func TestAccessTokenStorage(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := testServer(ctx)
+	defer closer()
+
+	tkn, err := getJWT(testJWTkey, testUserID, testUser)
+	assert.NoError(t, err)
+
+	md := metadata.Pairs("authorization", fmt.Sprintf("bearer %s", *tkn))
+	jwtCtx := metadata.NewOutgoingContext(context.Background(), md)
+
+	// Create read only token
+	created, err := client.user.CreateAccessToken(jwtCtx, &proto.CreateAccessTokenRequest{
+		Name:   "ci",
+		Scopes: []string{"read"},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, created.Error)
+	assert.NotEmpty(t, created.Token)
+
+	md = metadata.Pairs("authorization", fmt.Sprintf("bearer %s", created.Token))
+	patCtx := metadata.NewOutgoingContext(context.Background(), md)
+
+	t.Run("Access token must read records", func(t *testing.T) {
+		out, err := client.storage.ReadAllRecord(patCtx, &proto.ReadAllRecordRequest{})
+		assert.NoError(t, err)
+		assert.Empty(t, out.Error)
+	})
+
+	t.Run("Access token without write scope must not write", func(t *testing.T) {
+		out := writeRecord(patCtx, t, client.storage, &proto.WriteRecordRequest{Name: "ci", Type: "text",
+			Data: []byte("test")})
+		assert.Equal(t, "access denied", out.Error)
+	})
+
+	t.Run("Access token must not create tokens", func(t *testing.T) {
+		out, err := client.user.CreateAccessToken(patCtx, &proto.CreateAccessTokenRequest{
+			Name:   "ci2",
+			Scopes: []string{"read"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "access tokens cannot manage access tokens", out.Error)
+	})
+
+	t.Run("Revoked access token must be rejected", func(t *testing.T) {
+		out, err := client.user.RevokeAccessToken(jwtCtx, &proto.RevokeAccessTokenRequest{Id: created.Id})
+		assert.NoError(t, err)
+		assert.Empty(t, out.Error)
+
+		_, err = client.storage.ReadAllRecord(patCtx, &proto.ReadAllRecordRequest{})
+		assert.Error(t, err)
+	})
+
+	t.Run("Access token with TTL must be accepted", func(t *testing.T) {
+		out, err := client.user.CreateAccessToken(jwtCtx, &proto.CreateAccessTokenRequest{
+			Name:    "ci-ttl",
+			Scopes:  []string{"read"},
+			TtlDays: 1,
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, out.Error)
+
+		ttlCtx := metadata.NewOutgoingContext(context.Background(),
+			metadata.Pairs("authorization", fmt.Sprintf("bearer %s", out.Token)))
+		all, err := client.storage.ReadAllRecord(ttlCtx, &proto.ReadAllRecordRequest{})
+		assert.NoError(t, err)
+		assert.Empty(t, all.Error)
+
+		list, err := client.user.ListAccessTokens(jwtCtx, &proto.ListAccessTokensRequest{})
+		assert.NoError(t, err)
+		for _, v := range list.Tokens {
+			if v.Id == out.Id {
+				assert.Greater(t, v.ExpiresAt, time.Now().Unix())
+			}
+		}
+	})
+
+	t.Run("Access token restricted to records must update but not create", func(t *testing.T) {
+		id := createRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{Name: "pat-record", Type: "text",
+			Data: []byte("test")})
+
+		out, err := client.user.CreateAccessToken(jwtCtx, &proto.CreateAccessTokenRequest{
+			Name:      "ci-records",
+			Scopes:    []string{"read", "write"},
+			RecordIds: []int32{id},
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, out.Error)
+
+		recCtx := metadata.NewOutgoingContext(context.Background(),
+			metadata.Pairs("authorization", fmt.Sprintf("bearer %s", out.Token)))
+
+		resp := writeRecord(recCtx, t, client.storage, &proto.WriteRecordRequest{Id: id, Type: "text",
+			Data: []byte("changed")})
+		assert.Empty(t, resp.Error)
+
+		resp = writeRecord(recCtx, t, client.storage, &proto.WriteRecordRequest{Name: "pat-record-new", Type: "text",
+			Data: []byte("test")})
+		assert.Equal(t, "access token is restricted to existing records", resp.Error)
+	})
+
+	t.Run("Revoke of unknown access token must return not found", func(t *testing.T) {
+		out, err := client.user.RevokeAccessToken(jwtCtx, &proto.RevokeAccessTokenRequest{Id: 1_000_000})
+		assert.NoError(t, err)
+		assert.Equal(t, "access token not found", out.Error)
+	})
+}
+
+func TestShareRecordStorage(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := testServer(ctx)
+	defer closer()
+
+	// Owner
+	tkn, err := getJWT(testJWTkey, testUserID, testUser)
+	assert.NoError(t, err)
+	ownerCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("authorization", fmt.Sprintf("bearer %s", *tkn)))
+
+	// Grantee
+	reg, err := client.user.Register(ctx, &proto.RegiserRequest{Login: "grantee", Password: "test"})
+	assert.NoError(t, err)
+	assert.Empty(t, reg.Error)
+	granteeCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("authorization", fmt.Sprintf("bearer %s", reg.Jwt)))
+
+	id := createRecord(ownerCtx, t, client.storage, &proto.WriteRecordRequest{Name: "shared", Type: "text",
+		Data: []byte("test")})
+
+	t.Run("Owner must share record", func(t *testing.T) {
+		out, err := client.storage.ShareRecord(ownerCtx, &proto.ShareRecordRequest{
+			Id: id, Login: "grantee", Permission: "read",
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, out.Error)
+	})
+
+	t.Run("Grantee must read shared record", func(t *testing.T) {
+		out, err := client.storage.ListSharedWithMe(granteeCtx, &proto.ListSharedWithMeRequest{})
+		assert.NoError(t, err)
+		assert.Len(t, out.Units, 1)
+		assert.Equal(t, testUser, out.Units[0].OwnerLogin)
+
+		rec, err := client.storage.ReadRecord(granteeCtx, &proto.ReadRecordRequest{Id: id})
+		assert.NoError(t, err)
+		assert.Empty(t, rec.Error)
+		assert.Equal(t, []byte("test"), rec.Data)
+	})
+
+	t.Run("Grantee with read permission must not update record", func(t *testing.T) {
+		out := writeRecord(granteeCtx, t, client.storage, &proto.WriteRecordRequest{Id: id, Type: "text",
+			Data: []byte("changed")})
+		assert.Equal(t, "record not found or not writable", out.Error)
+	})
+
+	t.Run("Grantee with write permission must update value only", func(t *testing.T) {
+		out, err := client.storage.ShareRecord(ownerCtx, &proto.ShareRecordRequest{
+			Id: id, Login: "grantee", Permission: "write",
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, out.Error)
+
+		resp := writeRecord(granteeCtx, t, client.storage, &proto.WriteRecordRequest{Id: id, Type: "text",
+			Data: []byte("changed")})
+		assert.Empty(t, resp.Error)
+
+		resp = writeRecord(granteeCtx, t, client.storage, &proto.WriteRecordRequest{Id: id, Name: "renamed",
+			Type: "text", Data: []byte("changed")})
+		assert.Equal(t, "shared record can be updated by value only", resp.Error)
+
+		resp = writeRecord(granteeCtx, t, client.storage, &proto.WriteRecordRequest{Id: id, Type: "login",
+			Data: []byte(`{"password":"test"}`)})
+		assert.Equal(t, "shared record can be updated by value only", resp.Error)
+
+		rec, err := client.storage.ReadRecord(ownerCtx, &proto.ReadRecordRequest{Id: id})
+		assert.NoError(t, err)
+		assert.Equal(t, "shared", rec.Name)
+		assert.Equal(t, "text", rec.Type)
+		assert.Equal(t, []byte("changed"), rec.Data)
+	})
+
+	t.Run("Revoked share must not be readable", func(t *testing.T) {
+		out, err := client.storage.RevokeShare(ownerCtx, &proto.RevokeShareRequest{Id: id, Login: "grantee"})
+		assert.NoError(t, err)
+		assert.Empty(t, out.Error)
+
+		rec, err := client.storage.ReadRecord(granteeCtx, &proto.ReadRecordRequest{Id: id})
+		assert.NoError(t, err)
+		assert.Equal(t, "record not found", rec.Error)
+	})
+}
+
+func TestVaultRoles(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := testServer(ctx)
+	defer closer()
+
+	// Owner
+	tkn, err := getJWT(testJWTkey, testUserID, testUser)
+	assert.NoError(t, err)
+	ownerCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("authorization", fmt.Sprintf("bearer %s", *tkn)))
+
+	// Viewer
+	reg, err := client.user.Register(ctx, &proto.RegiserRequest{Login: "viewer", Password: "test"})
+	assert.NoError(t, err)
+	assert.Empty(t, reg.Error)
+
+	org, err := client.vault.CreateOrganization(ownerCtx, &proto.CreateOrganizationRequest{Name: "team"})
+	assert.NoError(t, err)
+	assert.Empty(t, org.Error)
+
+	vault, err := client.vault.CreateVault(ownerCtx, &proto.CreateVaultRequest{OrgId: org.Id, Name: "prod"})
+	assert.NoError(t, err)
+	assert.Empty(t, vault.Error)
+
+	member, err := client.vault.AddMember(ownerCtx, &proto.AddMemberRequest{
+		OrgId: org.Id, Login: "viewer", Role: "viewer",
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, member.Error)
+
+	vaultID := fmt.Sprint(vault.Id)
+	ownerVaultCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("authorization", fmt.Sprintf("bearer %s", *tkn), "x-vault-id", vaultID))
+	viewerVaultCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("authorization", fmt.Sprintf("bearer %s", reg.Jwt), "x-vault-id", vaultID))
+
+	t.Run("Owner must write to vault", func(t *testing.T) {
+		createRecord(ownerVaultCtx, t, client.storage, &proto.WriteRecordRequest{Name: "db", Type: "text",
+			Data: []byte("test")})
+	})
+
+	t.Run("Viewer must read vault records", func(t *testing.T) {
+		out, err := client.storage.ReadAllRecord(viewerVaultCtx, &proto.ReadAllRecordRequest{})
+		assert.NoError(t, err)
+		assert.Empty(t, out.Error)
+		assert.Len(t, out.Units, 1)
+	})
+
+	t.Run("Viewer must not write to vault", func(t *testing.T) {
+		stream, err := client.storage.WriteRecord(viewerVaultCtx)
+		assert.NoError(t, err)
+		err = stream.Send(&proto.WriteRecordRequest{Name: "db2", Type: "text", Data: []byte("test")})
+		assert.NoError(t, err)
+
+		_, err = stream.CloseAndRecv()
+		assert.Error(t, err)
+	})
+
+	t.Run("Viewer must not invite members", func(t *testing.T) {
+		_, err := client.vault.AddMember(viewerVaultCtx, &proto.AddMemberRequest{
+			OrgId: org.Id, Login: testUser, Role: "admin",
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("Read access token of the owner must not manage the organization", func(t *testing.T) {
+		created, err := client.user.CreateAccessToken(ownerCtx, &proto.CreateAccessTokenRequest{
+			Name:   "ci-vault",
+			Scopes: []string{"read"},
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, created.Error)
+
+		patCtx := metadata.NewOutgoingContext(context.Background(),
+			metadata.Pairs("authorization", fmt.Sprintf("bearer %s", created.Token)))
+
+		_, err = client.vault.AddMember(patCtx, &proto.AddMemberRequest{
+			OrgId: org.Id, Login: "viewer", Role: "owner",
+		})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		_, err = client.vault.CreateVault(patCtx, &proto.CreateVaultRequest{OrgId: org.Id, Name: "ci"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		_, err = client.vault.RemoveMember(patCtx, &proto.RemoveMemberRequest{OrgId: org.Id, Login: "viewer"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("Last owner must not be removed or demoted", func(t *testing.T) {
+		out, err := client.vault.AddMember(ownerCtx, &proto.AddMemberRequest{
+			OrgId: org.Id, Login: testUser, Role: "admin",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "organization must keep at least one owner", out.Error)
+
+		rm, err := client.vault.RemoveMember(ownerCtx, &proto.RemoveMemberRequest{OrgId: org.Id, Login: testUser})
+		assert.NoError(t, err)
+		assert.Equal(t, "organization must keep at least one owner", rm.Error)
+	})
+
+	t.Run("Owner must be demoted when another owner exists", func(t *testing.T) {
+		out, err := client.vault.AddMember(ownerCtx, &proto.AddMemberRequest{
+			OrgId: org.Id, Login: "viewer", Role: "owner",
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, out.Error)
+
+		out, err = client.vault.AddMember(ownerCtx, &proto.AddMemberRequest{
+			OrgId: org.Id, Login: testUser, Role: "admin",
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, out.Error)
+	})
+}
+
+func TestEmergencyAccess(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := testServer(ctx)
+	defer closer()
+
+	// Grantor
+	tkn, err := getJWT(testJWTkey, testUserID, testUser)
+	assert.NoError(t, err)
+	grantorCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("authorization", fmt.Sprintf("bearer %s", *tkn)))
+
+	// Emergency contact
+	reg, err := client.user.Register(ctx, &proto.RegiserRequest{Login: "contact", Password: "test"})
+	assert.NoError(t, err)
+	assert.Empty(t, reg.Error)
+	contactCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("authorization", fmt.Sprintf("bearer %s", reg.Jwt)))
+
+	out, err := client.user.AddEmergencyContact(grantorCtx, &proto.AddEmergencyContactRequest{
+		Login: "contact", WaitHours: 0,
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, out.Error)
+
+	t.Run("Contact must not read records before request", func(t *testing.T) {
+		out, err := client.storage.ReadAllRecord(contactCtx, &proto.ReadAllRecordRequest{Grantor: testUser})
+		assert.NoError(t, err)
+		assert.Equal(t, "access denied", out.Error)
+	})
+
+	t.Run("Rejected request must not grant access", func(t *testing.T) {
+		req, err := client.user.RequestEmergencyAccess(contactCtx, &proto.RequestEmergencyAccessRequest{Login: testUser})
+		assert.NoError(t, err)
+		assert.Empty(t, req.Error)
+
+		rej, err := client.user.RejectEmergencyAccess(grantorCtx, &proto.RejectEmergencyAccessRequest{Login: "contact"})
+		assert.NoError(t, err)
+		assert.Empty(t, rej.Error)
+
+		out, err := client.storage.ReadAllRecord(contactCtx, &proto.ReadAllRecordRequest{Grantor: testUser})
+		assert.NoError(t, err)
+		assert.Equal(t, "access denied", out.Error)
+	})
+
+	t.Run("Contact must read records after waiting period", func(t *testing.T) {
+		req, err := client.user.RequestEmergencyAccess(contactCtx, &proto.RequestEmergencyAccessRequest{Login: testUser})
+		assert.NoError(t, err)
+		assert.Empty(t, req.Error)
+
+		out, err := client.storage.ReadAllRecord(contactCtx, &proto.ReadAllRecordRequest{Grantor: testUser})
+		assert.NoError(t, err)
+		assert.Empty(t, out.Error)
+
+		list, err := client.user.ListEmergencyContacts(grantorCtx, &proto.ListEmergencyContactsRequest{})
+		assert.NoError(t, err)
+		assert.Len(t, list.Contacts, 1)
+		assert.Equal(t, "granted", list.Contacts[0].Status)
+	})
+}
+
+func TestSyncStorage(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := testServer(ctx)
+	defer closer()
+
+	tkn, err := getJWT(testJWTkey, testUserID, testUser)
+	assert.NoError(t, err)
+	jwtCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("authorization", fmt.Sprintf("bearer %s", *tkn)))
+
+	first, err := client.storage.Sync(jwtCtx, &proto.SyncRequest{})
+	assert.NoError(t, err)
+	assert.Empty(t, first.Error)
+
+	createRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{Name: "sync", Type: "text", Data: []byte("test")})
+
+	var id int32
+	t.Run("Sync must return only new records", func(t *testing.T) {
+		out, err := client.storage.Sync(jwtCtx, &proto.SyncRequest{Cursor: first.Cursor})
+		assert.NoError(t, err)
+		assert.Empty(t, out.Error)
+		assert.Len(t, out.Units, 1)
+		assert.Equal(t, "sync", out.Units[0].Name)
+		assert.Greater(t, out.Cursor, first.Cursor)
+
+		id = out.Units[0].Id
+	})
+
+	t.Run("Sync must return deleted records", func(t *testing.T) {
+		before, err := client.storage.Sync(jwtCtx, &proto.SyncRequest{})
+		assert.NoError(t, err)
+
+		del, err := client.storage.DeleteRecord(jwtCtx, &proto.DeleteRecordRequest{Id: id})
+		assert.NoError(t, err)
+		assert.Empty(t, del.Error)
+
+		out, err := client.storage.Sync(jwtCtx, &proto.SyncRequest{Cursor: before.Cursor})
+		assert.NoError(t, err)
+		assert.Empty(t, out.Units)
+		assert.Equal(t, []int32{id}, out.Deleted)
+	})
+
+	t.Run("Sync must not skip records committed after the call", func(t *testing.T) {
+		db, err := sql.Open("postgres", databaseURL)
+		assert.NoError(t, err)
+		defer db.Close()
+
+		// The revision is taken now, the record is committed after the sync
+		tx, err := db.BeginTx(ctx, nil)
+		assert.NoError(t, err)
+		_, err = tx.ExecContext(ctx, `INSERT INTO storages (name, type, value, key, owner) VALUES ($1, $2, $3, $4, $5)`,
+			"sync-slow", "text", "", "", testUserID)
+		assert.NoError(t, err)
+
+		createRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{Name: "sync-fast", Type: "text",
+			Data: []byte("test")})
+
+		before, err := client.storage.Sync(jwtCtx, &proto.SyncRequest{})
+		assert.NoError(t, err)
+
+		err = tx.Commit()
+		assert.NoError(t, err)
+
+		out, err := client.storage.Sync(jwtCtx, &proto.SyncRequest{Cursor: before.Cursor})
+		assert.NoError(t, err)
+
+		names := []string{}
+		for _, v := range out.Units {
+			names = append(names, v.Name)
+		}
+		assert.Contains(t, names, "sync-slow")
+	})
+}
+
+func TestConflictStorage(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := testServer(ctx)
+	defer closer()
+
+	tkn, err := getJWT(testJWTkey, testUserID, testUser)
+	assert.NoError(t, err)
+	jwtCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("authorization", fmt.Sprintf("bearer %s", *tkn)))
+
+	before, err := client.storage.Sync(jwtCtx, &proto.SyncRequest{})
+	assert.NoError(t, err)
+
+	createRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{Name: "conflict", Type: "text",
+		Data: []byte("base")})
+
+	out, err := client.storage.Sync(jwtCtx, &proto.SyncRequest{Cursor: before.Cursor})
+	assert.NoError(t, err)
+	assert.Len(t, out.Units, 1)
+	id := out.Units[0].Id
+	base := out.Units[0].Versions
+
+	t.Run("Write of a seen version must update record", func(t *testing.T) {
+		resp := writeRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{
+			Id: id, Type: "text", Data: []byte("first"), Device: "first", Versions: base})
+		assert.Empty(t, resp.Error)
+		assert.False(t, resp.Conflict)
+		assert.Equal(t, int64(1), resp.Versions["first"])
+	})
+
+	t.Run("Write of a stale version must create conflict", func(t *testing.T) {
+		resp := writeRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{
+			Id: id, Type: "text", Data: []byte("second"), Device: "second", Versions: base})
+		assert.True(t, resp.Conflict)
+
+		rec, err := client.storage.ReadRecord(jwtCtx, &proto.ReadRecordRequest{Id: id})
+		assert.NoError(t, err)
+		assert.Equal(t, "first", string(rec.Data))
+		assert.True(t, rec.Conflict)
+	})
+
+	t.Run("Resolve must keep picked version", func(t *testing.T) {
+		list, err := client.storage.ListConflicts(jwtCtx, &proto.ListConflictsRequest{Id: id})
+		assert.NoError(t, err)
+		assert.Empty(t, list.Error)
+		assert.Equal(t, "first", string(list.Current.Data))
+		assert.Len(t, list.Conflicts, 1)
+		assert.Equal(t, "second", list.Conflicts[0].Device)
+
+		resolved, err := client.storage.ResolveConflict(jwtCtx, &proto.ResolveConflictRequest{
+			Id: id, Keep: list.Conflicts[0].Id, Device: "first"})
+		assert.NoError(t, err)
+		assert.Empty(t, resolved.Error)
+
+		rec, err := client.storage.ReadRecord(jwtCtx, &proto.ReadRecordRequest{Id: id})
+		assert.NoError(t, err)
+		assert.Equal(t, "second", string(rec.Data))
+		assert.False(t, rec.Conflict)
+		assert.Equal(t, int64(1), rec.Versions["second"])
+		assert.Equal(t, int64(2), rec.Versions["first"])
+	})
+
+	t.Run("Concurrent writes must not be lost", func(t *testing.T) {
+		const writers = 8
+
+		var wg sync.WaitGroup
+		responses := make([]*proto.WriteRecordResponse, writers)
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				responses[i] = writeRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{
+					Id: id, Type: "text", Data: []byte("concurrent"), Device: fmt.Sprintf("writer-%d", i)})
+			}(i)
+		}
+		wg.Wait()
+
+		rec, err := client.storage.ReadRecord(jwtCtx, &proto.ReadRecordRequest{Id: id})
+		assert.NoError(t, err)
+
+		// Every accepted write is counted, the others are kept as conflicts
+		for i, v := range responses {
+			assert.Empty(t, v.Error)
+			if !v.Conflict {
+				assert.Equal(t, int64(1), rec.Versions[fmt.Sprintf("writer-%d", i)])
+			}
+		}
+	})
+}
+
+func TestWatchStorage(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := testServer(ctx)
+	defer closer()
+
+	tkn, err := getJWT(testJWTkey, testUserID, testUser)
+	assert.NoError(t, err)
+	jwtCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("authorization", fmt.Sprintf("bearer %s", *tkn)))
+
+	reg, err := client.user.Register(ctx, &proto.RegiserRequest{Login: "watch-other", Password: "test"})
+	assert.NoError(t, err)
+	assert.Empty(t, reg.Error)
+	otherCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("authorization", fmt.Sprintf("bearer %s", reg.Jwt)))
+
+	watchCtx, cancel := context.WithTimeout(jwtCtx, 10*time.Second)
+	defer cancel()
+
+	watch, err := client.storage.Watch(watchCtx, &proto.WatchRequest{})
+	assert.NoError(t, err)
+
+	// Headers are sent once the watcher is subscribed
+	_, err = watch.Header()
+	assert.NoError(t, err)
+
+	var id int32
+	t.Run("Watch must skip records of other users", func(t *testing.T) {
+		writeRecord(otherCtx, t, client.storage, &proto.WriteRecordRequest{Name: "watch-foreign", Type: "text",
+			Data: []byte("test")})
+		writeRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{Name: "watch", Type: "text", Data: []byte("test")})
+
+		event, err := watch.Recv()
+		assert.NoError(t, err)
+		assert.Empty(t, event.Error)
+		assert.Equal(t, "create", event.Kind)
+		assert.Equal(t, "watch", event.Name)
+
+		id = event.Id
+	})
+
+	t.Run("Watch must receive deleted records", func(t *testing.T) {
+		del, err := client.storage.DeleteRecord(jwtCtx, &proto.DeleteRecordRequest{Id: id})
+		assert.NoError(t, err)
+		assert.Empty(t, del.Error)
+
+		event, err := watch.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, "delete", event.Kind)
+		assert.Equal(t, id, event.Id)
+	})
+
+	t.Run("Watch must receive shares and revoked shares", func(t *testing.T) {
+		otherWatch, err := client.storage.Watch(metadata.NewOutgoingContext(watchCtx,
+			metadata.Pairs("authorization", fmt.Sprintf("bearer %s", reg.Jwt))), &proto.WatchRequest{})
+		assert.NoError(t, err)
+		_, err = otherWatch.Header()
+		assert.NoError(t, err)
+
+		shared := createRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{Name: "watch-shared", Type: "text",
+			Data: []byte("test")})
+
+		event, err := watch.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, "create", event.Kind)
+
+		out, err := client.storage.ShareRecord(jwtCtx, &proto.ShareRecordRequest{
+			Id: shared, Login: "watch-other", Permission: "read",
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, out.Error)
+
+		event, err = otherWatch.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, "create", event.Kind)
+		assert.Equal(t, shared, event.Id)
+		assert.Equal(t, "watch-shared", event.Name)
+
+		revoked, err := client.storage.RevokeShare(jwtCtx, &proto.RevokeShareRequest{Id: shared, Login: "watch-other"})
+		assert.NoError(t, err)
+		assert.Empty(t, revoked.Error)
+
+		event, err = otherWatch.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, "delete", event.Kind)
+		assert.Equal(t, shared, event.Id)
+	})
+}
+
+func TestFindRecordByName(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := testServer(ctx)
+	defer closer()
+
+	tkn, err := getJWT(testJWTkey, testUserID, testUser)
+	assert.NoError(t, err)
+	jwtCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("authorization", fmt.Sprintf("bearer %s", *tkn)))
+
+	for _, name := range []string{"work/aws/prod", "work/aws/dev", "workshop", " work//gcp/ "} {
+		resp := writeRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{Name: name, Type: "text", Data: []byte("test")})
+		assert.Empty(t, resp.Error)
+	}
+
+	t.Run("Exact name must find one record", func(t *testing.T) {
+		r, err := client.storage.FindRecordByName(jwtCtx, &proto.FindRecordByNameRequest{Name: "work/aws/prod"})
+		assert.NoError(t, err)
+		assert.Empty(t, r.Error)
+		assert.Len(t, r.Units, 1)
+		assert.Equal(t, "work/aws/prod", r.Units[0].Name)
+	})
+
+	t.Run("Prefix must find records of folder", func(t *testing.T) {
+		r, err := client.storage.FindRecordByName(jwtCtx, &proto.FindRecordByNameRequest{Name: "work/", Prefix: true})
+		assert.NoError(t, err)
+		assert.Empty(t, r.Error)
+
+		names := []string{}
+		for _, v := range r.Units {
+			names = append(names, v.Name)
+		}
+		assert.Equal(t, []string{"work/aws/dev", "work/aws/prod", "work/gcp"}, names)
+	})
+
+	t.Run("Duplicate name must be rejected", func(t *testing.T) {
+		resp := writeRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{Name: "work/aws/prod", Type: "text",
+			Data: []byte("test")})
+		assert.Equal(t, `record "work/aws/prod" already exists`, resp.Error)
+	})
+
+	t.Run("Relative segments must be rejected", func(t *testing.T) {
+		resp := writeRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{Name: "work/../prod", Type: "text",
+			Data: []byte("test")})
+		assert.Contains(t, resp.Error, "invalid record")
+	})
+}
+
+func TestSearchRecords(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := testServer(ctx)
+	defer closer()
+
+	tkn, err := getJWT(testJWTkey, testUserID, testUser)
+	assert.NoError(t, err)
+	jwtCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("authorization", fmt.Sprintf("bearer %s", *tkn)))
+
+	alpha := createRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{Name: "search/alpha", Type: "text",
+		Data: []byte("test"), Metadata: map[string]string{"env": "prod", "team": "core"}})
+	beta := createRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{Name: "search/beta", Type: "login",
+		Data: []byte(`{"password":"test"}`), Metadata: map[string]string{"env": "dev"}})
+	createRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{Name: "search/gamma_1", Type: "text",
+		Data: []byte("test")})
+
+	names := func(units []*proto.StorageUnit) []string {
+		out := []string{}
+		for _, v := range units {
+			out = append(out, v.Name)
+		}
+		return out
+	}
+
+	t.Run("Name substring and type must filter records", func(t *testing.T) {
+		r, err := client.storage.SearchRecords(jwtCtx, &proto.SearchRecordsRequest{Name: "SEARCH/", Type: "text"})
+		assert.NoError(t, err)
+		assert.Empty(t, r.Error)
+		assert.Equal(t, []string{"search/alpha", "search/gamma_1"}, names(r.Units))
+	})
+
+	t.Run("Wildcards must be matched literally", func(t *testing.T) {
+		r, err := client.storage.SearchRecords(jwtCtx, &proto.SearchRecordsRequest{Name: "a_1"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"search/gamma_1"}, names(r.Units))
+	})
+
+	t.Run("Metadata must filter records", func(t *testing.T) {
+		r, err := client.storage.SearchRecords(jwtCtx, &proto.SearchRecordsRequest{
+			Metadata: map[string]string{"env": "prod"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"search/alpha"}, names(r.Units))
+
+		r, err = client.storage.SearchRecords(jwtCtx, &proto.SearchRecordsRequest{
+			Name: "search/", Metadata: map[string]string{"env": ""}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"search/alpha", "search/beta"}, names(r.Units))
+	})
+
+	t.Run("Tags must filter records", func(t *testing.T) {
+		for id, tags := range map[int32][]string{alpha: {"prod", "db"}, beta: {"prod"}} {
+			r, err := client.storage.TagRecord(jwtCtx, &proto.TagRecordRequest{Id: id, Tags: tags})
+			assert.NoError(t, err)
+			assert.Empty(t, r.Error)
+		}
+
+		r, err := client.storage.SearchRecords(jwtCtx, &proto.SearchRecordsRequest{Name: "search/", Tags: []string{"prod"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"search/alpha", "search/beta"}, names(r.Units))
+
+		r, err = client.storage.SearchRecords(jwtCtx, &proto.SearchRecordsRequest{Tags: []string{"prod", "db"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"search/alpha"}, names(r.Units))
+	})
+
+	t.Run("Time range must filter records", func(t *testing.T) {
+		r, err := client.storage.SearchRecords(jwtCtx, &proto.SearchRecordsRequest{
+			Name: "search/", UpdatedAfter: time.Now().Add(time.Hour).Unix()})
+		assert.NoError(t, err)
+		assert.Empty(t, r.Units)
+	})
+
+	t.Run("Pages must follow each other", func(t *testing.T) {
+		var all []string
+		in := &proto.SearchRecordsRequest{Name: "search/", PageSize: 2}
+		for {
+			r, err := client.storage.SearchRecords(jwtCtx, in)
+			assert.NoError(t, err)
+			assert.Empty(t, r.Error)
+			assert.LessOrEqual(t, len(r.Units), 2)
+
+			all = append(all, names(r.Units)...)
+			if r.NextPageToken == "" {
+				break
+			}
+			in.PageToken = r.NextPageToken
+		}
+		assert.Equal(t, []string{"search/alpha", "search/beta", "search/gamma_1"}, all)
+	})
+
+	t.Run("Invalid page token must be rejected", func(t *testing.T) {
+		r, err := client.storage.SearchRecords(jwtCtx, &proto.SearchRecordsRequest{PageToken: "???"})
+		assert.NoError(t, err)
+		assert.Contains(t, r.Error, "invalid page token")
+	})
+}
+
+func TestReadAllRecordPages(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := testServer(ctx)
+	defer closer()
+
+	tkn, err := getJWT(testJWTkey, testUserID, testUser)
+	assert.NoError(t, err)
+	jwtCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("authorization", fmt.Sprintf("bearer %s", *tkn)))
+
+	// More records than fit into the page of the default size
+	for i := 0; i < defaultPageSize; i++ {
+		createRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{Name: fmt.Sprintf("page/bulk/%03d", i),
+			Type: "text", Data: []byte("test")})
+	}
+
+	for _, v := range []string{"page/c", "page/a", "page/b"} {
+		createRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{Name: v, Type: "text", Data: []byte("test")})
+	}
+
+	all, err := client.storage.ReadAllRecord(jwtCtx, &proto.ReadAllRecordRequest{PageSize: maxPageSize})
+	assert.NoError(t, err)
+	assert.Empty(t, all.NextPageToken)
+
+	t.Run("Request without page size must get a bounded page", func(t *testing.T) {
+		r, err := client.storage.ReadAllRecord(jwtCtx, &proto.ReadAllRecordRequest{})
+		assert.NoError(t, err)
+		assert.Empty(t, r.Error)
+		assert.Len(t, r.Units, defaultPageSize)
+		assert.NotEmpty(t, r.NextPageToken)
+
+		found, err := client.storage.FindRecordByName(jwtCtx, &proto.FindRecordByNameRequest{Name: "page/", Prefix: true})
+		assert.NoError(t, err)
+		assert.Empty(t, found.Error)
+		assert.Len(t, found.Units, defaultPageSize)
+		assert.NotEmpty(t, found.NextPageToken)
+
+		found, err = client.storage.FindRecordByName(jwtCtx, &proto.FindRecordByNameRequest{Name: "page/", Prefix: true,
+			PageToken: found.NextPageToken})
+		assert.NoError(t, err)
+		assert.Empty(t, found.Error)
+		assert.Len(t, found.Units, 3)
+		assert.Empty(t, found.NextPageToken)
+	})
+
+	t.Run("Page size must be limited by the server", func(t *testing.T) {
+		r, err := client.storage.ReadAllRecord(jwtCtx, &proto.ReadAllRecordRequest{PageSize: maxPageSize + 1})
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, len(r.Units), maxPageSize)
+	})
+
+	pages := func(in *proto.ReadAllRecordRequest) []string {
+		var out []string
+		for {
+			r, err := client.storage.ReadAllRecord(jwtCtx, in)
+			assert.NoError(t, err)
+			assert.Empty(t, r.Error)
+			assert.LessOrEqual(t, len(r.Units), int(in.PageSize))
+
+			for _, v := range r.Units {
+				out = append(out, v.Name)
+			}
+			if r.NextPageToken == "" {
+				return out
+			}
+			in.PageToken = r.NextPageToken
+		}
+	}
+
+	t.Run("Pages must cover all records", func(t *testing.T) {
+		names := pages(&proto.ReadAllRecordRequest{PageSize: 2})
+		assert.Len(t, names, len(all.Units))
+	})
+
+	t.Run("Pages must follow the sort order", func(t *testing.T) {
+		names := pages(&proto.ReadAllRecordRequest{PageSize: 2, Sort: "-name"})
+		assert.Len(t, names, len(all.Units))
+		assert.IsNonIncreasing(t, names)
+
+		names = pages(&proto.ReadAllRecordRequest{PageSize: 1, Sort: "updated"})
+		assert.Len(t, names, len(all.Units))
+		assert.Equal(t, []string{"page/c", "page/a", "page/b"}, names[len(names)-3:])
+	})
+
+	t.Run("Page token of another sort order must be rejected", func(t *testing.T) {
+		r, err := client.storage.ReadAllRecord(jwtCtx, &proto.ReadAllRecordRequest{PageSize: 1, Sort: "name"})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, r.NextPageToken)
+
+		r, err = client.storage.ReadAllRecord(jwtCtx, &proto.ReadAllRecordRequest{PageToken: r.NextPageToken})
+		assert.NoError(t, err)
+		assert.Contains(t, r.Error, "invalid page token")
+	})
+
+	t.Run("Unknown sort order must be rejected", func(t *testing.T) {
+		r, err := client.storage.ReadAllRecord(jwtCtx, &proto.ReadAllRecordRequest{Sort: "size"})
+		assert.NoError(t, err)
+		assert.Contains(t, r.Error, "invalid sort order")
+	})
+}
+
 /* UTILS. */
+// writeRecord sends the record in one chunk and returns the response.
+func writeRecord(ctx context.Context, t *testing.T, storage proto.StorageClient,
+	in *proto.WriteRecordRequest) *proto.WriteRecordResponse {
+	t.Helper()
+
+	stream, err := storage.WriteRecord(ctx)
+	assert.NoError(t, err)
+	err = stream.Send(in)
+	assert.NoError(t, err)
+	resp, err := stream.CloseAndRecv()
+	assert.NoError(t, err)
+
+	return resp
+}
+
+// createRecord writes a new record, checks that it is accepted and returns
+// its ID.
+func createRecord(ctx context.Context, t *testing.T, storage proto.StorageClient, in *proto.WriteRecordRequest) int32 {
+	t.Helper()
+
+	resp := writeRecord(ctx, t, storage, in)
+	assert.Empty(t, resp.Error)
+
+	found, err := storage.FindRecordByName(ctx, &proto.FindRecordByNameRequest{Name: in.Name})
+	assert.NoError(t, err)
+	assert.Len(t, found.Units, 1)
+
+	return found.Units[0].Id
+}
+
 func getJWT(jwtKey string, id int, login string) (*string, error) {
 	var DefaultSession = 30
 	var DefaultExpTime = time.Now().Add(time.Duration(DefaultSession) * time.Minute)
@@ -682,3 +1635,396 @@ func getJWT(jwtKey string, id int, login string) (*string, error) {
 
 	return &tokenString, nil
 }
+
+func TestFoldersAndTags(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := testServer(ctx)
+	defer closer()
+
+	tkn, err := getJWT(testJWTkey, testUserID, testUser)
+	assert.NoError(t, err)
+	jwtCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("authorization", fmt.Sprintf("bearer %s", *tkn)))
+
+	id := createRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{Name: "folder-record", Type: "text",
+		Data: []byte("test")})
+
+	work, err := client.storage.CreateFolder(jwtCtx, &proto.CreateFolderRequest{Name: "work"})
+	assert.NoError(t, err)
+	assert.Empty(t, work.Error)
+
+	db, err := client.storage.CreateFolder(jwtCtx, &proto.CreateFolderRequest{Parent: work.Folder.Id, Name: "db"})
+	assert.NoError(t, err)
+	assert.Empty(t, db.Error)
+
+	search := func(in *proto.SearchRecordsRequest) []string {
+		r, err := client.storage.SearchRecords(jwtCtx, in)
+		assert.NoError(t, err)
+		assert.Empty(t, r.Error)
+
+		names := []string{}
+		for _, v := range r.Units {
+			names = append(names, v.Name)
+		}
+		return names
+	}
+
+	t.Run("Folder names must be unique in parent", func(t *testing.T) {
+		r, err := client.storage.CreateFolder(jwtCtx, &proto.CreateFolderRequest{Name: "work"})
+		assert.NoError(t, err)
+		assert.Contains(t, r.Error, "already exists")
+
+		r, err = client.storage.CreateFolder(jwtCtx, &proto.CreateFolderRequest{Name: "a/b"})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, r.Error)
+	})
+
+	t.Run("Concurrent writes of the same name must report existing folder", func(t *testing.T) {
+		const writers = 5
+
+		var wg sync.WaitGroup
+		errs := make([]string, writers)
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				r, err := client.storage.CreateFolder(jwtCtx, &proto.CreateFolderRequest{Parent: work.Folder.Id, Name: "race"})
+				assert.NoError(t, err)
+				errs[i] = r.Error
+			}(i)
+		}
+		wg.Wait()
+
+		created := 0
+		for _, v := range errs {
+			if v == "" {
+				created++
+				continue
+			}
+			assert.Equal(t, `folder "race" already exists`, v)
+		}
+		assert.Equal(t, 1, created)
+
+		r, err := client.storage.RenameFolder(jwtCtx, &proto.RenameFolderRequest{Id: db.Folder.Id, Name: "race"})
+		assert.NoError(t, err)
+		assert.Equal(t, `folder "race" already exists`, r.Error)
+	})
+
+	t.Run("Moved record must be listed in folder", func(t *testing.T) {
+		r, err := client.storage.MoveRecord(jwtCtx, &proto.MoveRecordRequest{Id: id, Folder: db.Folder.Id})
+		assert.NoError(t, err)
+		assert.Empty(t, r.Error)
+
+		assert.Equal(t, []string{"folder-record"}, search(&proto.SearchRecordsRequest{Folder: "work/db"}))
+		assert.NotContains(t, search(&proto.SearchRecordsRequest{Folder: "/"}), "folder-record")
+	})
+
+	t.Run("Tags must filter records", func(t *testing.T) {
+		r, err := client.storage.TagRecord(jwtCtx, &proto.TagRecordRequest{Id: id, Tags: []string{"prod", "db"}})
+		assert.NoError(t, err)
+		assert.Empty(t, r.Error)
+
+		assert.Equal(t, []string{"folder-record"}, search(&proto.SearchRecordsRequest{Tags: []string{"prod", "db"}}))
+
+		u, err := client.storage.UntagRecord(jwtCtx, &proto.UntagRecordRequest{Id: id, Tags: []string{"db"}})
+		assert.NoError(t, err)
+		assert.Empty(t, u.Error)
+
+		assert.Empty(t, search(&proto.SearchRecordsRequest{Tags: []string{"prod", "db"}}))
+
+		all, err := client.storage.ReadAllRecord(jwtCtx, &proto.ReadAllRecordRequest{PageSize: maxPageSize})
+		assert.NoError(t, err)
+		for _, v := range all.Units {
+			if v.Id == id {
+				assert.Equal(t, []string{"prod"}, v.Tags)
+				assert.Equal(t, db.Folder.Id, v.Folder)
+			}
+		}
+	})
+
+	t.Run("Deleted folder must move records to parent", func(t *testing.T) {
+		r, err := client.storage.DeleteFolder(jwtCtx, &proto.DeleteFolderRequest{Id: work.Folder.Id})
+		assert.NoError(t, err)
+		assert.Empty(t, r.Error)
+
+		folders, err := client.storage.ListFolders(jwtCtx, &proto.ListFoldersRequest{})
+		assert.NoError(t, err)
+		assert.Empty(t, folders.Folders)
+
+		assert.Contains(t, search(&proto.SearchRecordsRequest{Folder: "/"}), "folder-record")
+	})
+
+	t.Run("Deleted folder must not be found", func(t *testing.T) {
+		c, err := client.storage.CreateFolder(jwtCtx, &proto.CreateFolderRequest{Parent: db.Folder.Id, Name: "orphan"})
+		assert.NoError(t, err)
+		assert.Equal(t, "folder not found", c.Error)
+
+		m, err := client.storage.MoveRecord(jwtCtx, &proto.MoveRecordRequest{Id: id, Folder: db.Folder.Id})
+		assert.NoError(t, err)
+		assert.Equal(t, "folder not found", m.Error)
+
+		r, err := client.storage.RenameFolder(jwtCtx, &proto.RenameFolderRequest{Id: work.Folder.Id, Name: "home"})
+		assert.NoError(t, err)
+		assert.Equal(t, "folder not found", r.Error)
+
+		d, err := client.storage.DeleteFolder(jwtCtx, &proto.DeleteFolderRequest{Id: work.Folder.Id})
+		assert.NoError(t, err)
+		assert.Equal(t, "folder not found", d.Error)
+	})
+}
+
+func TestTrash(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := testServer(ctx)
+	defer closer()
+
+	tkn, err := getJWT(testJWTkey, testUserID, testUser)
+	assert.NoError(t, err)
+	jwtCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("authorization", fmt.Sprintf("bearer %s", *tkn)))
+
+	listed := func() []int32 {
+		all, err := client.storage.ReadAllRecord(jwtCtx, &proto.ReadAllRecordRequest{PageSize: maxPageSize})
+		assert.NoError(t, err)
+		assert.Empty(t, all.Error)
+
+		ids := []int32{}
+		for _, v := range all.Units {
+			ids = append(ids, v.Id)
+		}
+		return ids
+	}
+
+	id := createRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{Name: "trash-record", Type: "text",
+		Data: []byte("test")})
+
+	t.Run("Deleted record must be moved to trash", func(t *testing.T) {
+		r, err := client.storage.DeleteRecord(jwtCtx, &proto.DeleteRecordRequest{Id: id})
+		assert.NoError(t, err)
+		assert.Empty(t, r.Error)
+
+		assert.NotContains(t, listed(), id)
+
+		trash, err := client.storage.ListTrash(jwtCtx, &proto.ListTrashRequest{})
+		assert.NoError(t, err)
+		assert.Empty(t, trash.Error)
+
+		found := false
+		for _, v := range trash.Units {
+			if v.Id == id {
+				found = true
+				assert.NotZero(t, v.DeletedAt)
+			}
+		}
+		assert.True(t, found)
+	})
+
+	t.Run("Restore must fail if name is taken", func(t *testing.T) {
+		other := createRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{Name: "trash-record", Type: "text",
+			Data: []byte("test")})
+
+		r, err := client.storage.RestoreRecord(jwtCtx, &proto.RestoreRecordRequest{Id: id})
+		assert.NoError(t, err)
+		assert.Contains(t, r.Error, "already exists")
+
+		d, err := client.storage.DeleteRecord(jwtCtx, &proto.DeleteRecordRequest{Id: other})
+		assert.NoError(t, err)
+		assert.Empty(t, d.Error)
+	})
+
+	t.Run("Restored record must be listed", func(t *testing.T) {
+		r, err := client.storage.RestoreRecord(jwtCtx, &proto.RestoreRecordRequest{Id: id})
+		assert.NoError(t, err)
+		assert.Empty(t, r.Error)
+
+		assert.Contains(t, listed(), id)
+
+		r, err = client.storage.RestoreRecord(jwtCtx, &proto.RestoreRecordRequest{Id: id})
+		assert.NoError(t, err)
+		assert.Equal(t, "record not found", r.Error)
+	})
+
+	t.Run("Empty trash must remove deleted records", func(t *testing.T) {
+		r, err := client.storage.EmptyTrash(jwtCtx, &proto.EmptyTrashRequest{})
+		assert.NoError(t, err)
+		assert.Empty(t, r.Error)
+		assert.Positive(t, r.Count)
+
+		trash, err := client.storage.ListTrash(jwtCtx, &proto.ListTrashRequest{})
+		assert.NoError(t, err)
+		assert.Empty(t, trash.Units)
+
+		assert.Contains(t, listed(), id)
+	})
+
+	t.Run("Sync from cursor before purge must remove purged record", func(t *testing.T) {
+		purged := createRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{Name: "purged-record", Type: "text",
+			Data: []byte("test")})
+
+		before, err := client.storage.Sync(jwtCtx, &proto.SyncRequest{})
+		assert.NoError(t, err)
+		assert.Empty(t, before.Error)
+
+		d, err := client.storage.DeleteRecord(jwtCtx, &proto.DeleteRecordRequest{Id: purged})
+		assert.NoError(t, err)
+		assert.Empty(t, d.Error)
+
+		r, err := client.storage.EmptyTrash(jwtCtx, &proto.EmptyTrashRequest{})
+		assert.NoError(t, err)
+		assert.Empty(t, r.Error)
+		assert.Equal(t, int64(1), r.Count)
+
+		out, err := client.storage.Sync(jwtCtx, &proto.SyncRequest{Cursor: before.Cursor})
+		assert.NoError(t, err)
+		assert.Empty(t, out.Error)
+		assert.Contains(t, out.Deleted, purged)
+
+		read, err := client.storage.ReadRecord(jwtCtx, &proto.ReadRecordRequest{Id: purged})
+		assert.NoError(t, err)
+		assert.Equal(t, "record not found", read.Error)
+
+		r, err = client.storage.EmptyTrash(jwtCtx, &proto.EmptyTrashRequest{})
+		assert.NoError(t, err)
+		assert.Zero(t, r.Count)
+	})
+}
+
+func TestRecordExpiry(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := testServer(ctx)
+	defer closer()
+
+	tkn, err := getJWT(testJWTkey, testUserID, testUser)
+	assert.NoError(t, err)
+	jwtCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("authorization", fmt.Sprintf("bearer %s", *tkn)))
+
+	id := createRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{Name: "expiry-record", Type: "text",
+		Data: []byte("test")})
+
+	status := func(in *proto.ListExpiringRecordsRequest) string {
+		r, err := client.storage.ListExpiringRecords(jwtCtx, in)
+		assert.NoError(t, err)
+		assert.Empty(t, r.Error)
+
+		for _, v := range r.Units {
+			if v.Id == id {
+				return v.ExpiryStatus
+			}
+		}
+		return ""
+	}
+
+	set := func(in *proto.SetRecordExpiryRequest) {
+		in.Id = id
+		r, err := client.storage.SetRecordExpiry(jwtCtx, in)
+		assert.NoError(t, err)
+		assert.Empty(t, r.Error)
+	}
+
+	t.Run("Record without expiry must not be listed", func(t *testing.T) {
+		assert.Empty(t, status(&proto.ListExpiringRecordsRequest{}))
+	})
+
+	t.Run("Record expiring soon must be listed", func(t *testing.T) {
+		set(&proto.SetRecordExpiryRequest{ExpiresAt: time.Now().AddDate(0, 0, 3).Unix()})
+
+		assert.Equal(t, "expiring", status(&proto.ListExpiringRecordsRequest{}))
+		assert.Empty(t, status(&proto.ListExpiringRecordsRequest{WithinDays: 1}))
+	})
+
+	t.Run("Rotation interval must be counted from last write", func(t *testing.T) {
+		set(&proto.SetRecordExpiryRequest{RotateDays: 90})
+		assert.Empty(t, status(&proto.ListExpiringRecordsRequest{}))
+		assert.Equal(t, "expiring", status(&proto.ListExpiringRecordsRequest{WithinDays: 91}))
+	})
+
+	t.Run("Expired record must be listed", func(t *testing.T) {
+		set(&proto.SetRecordExpiryRequest{ExpiresAt: time.Now().AddDate(0, 0, -1).Unix(), RotateDays: 90})
+		assert.Equal(t, "expired", status(&proto.ListExpiringRecordsRequest{}))
+
+		all, err := client.storage.ReadAllRecord(jwtCtx, &proto.ReadAllRecordRequest{PageSize: maxPageSize})
+		assert.NoError(t, err)
+		for _, v := range all.Units {
+			if v.Id == id {
+				assert.Equal(t, "expired", v.ExpiryStatus)
+				assert.Equal(t, int32(90), v.RotateDays)
+			}
+		}
+	})
+
+	t.Run("Invalid rotation interval must be rejected", func(t *testing.T) {
+		r, err := client.storage.SetRecordExpiry(jwtCtx, &proto.SetRecordExpiryRequest{Id: id, RotateDays: -1})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, r.Error)
+	})
+
+	t.Run("Changes without a new value must not restart rotation", func(t *testing.T) {
+		set(&proto.SetRecordExpiryRequest{RotateDays: 30})
+
+		db, err := sql.Open("postgres", databaseURL)
+		assert.NoError(t, err)
+		defer db.Close()
+
+		_, err = db.ExecContext(ctx, "UPDATE storages SET value_updated_at = now() - interval '40 days' WHERE id = $1", id)
+		assert.NoError(t, err)
+		assert.Equal(t, "expired", status(&proto.ListExpiringRecordsRequest{}))
+
+		m, err := client.storage.MoveRecord(jwtCtx, &proto.MoveRecordRequest{Id: id})
+		assert.NoError(t, err)
+		assert.Empty(t, m.Error)
+
+		tag, err := client.storage.TagRecord(jwtCtx, &proto.TagRecordRequest{Id: id, Tags: []string{"rotate"}})
+		assert.NoError(t, err)
+		assert.Empty(t, tag.Error)
+
+		set(&proto.SetRecordExpiryRequest{RotateDays: 30})
+		assert.Equal(t, "expired", status(&proto.ListExpiringRecordsRequest{}))
+
+		resp := writeRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{Id: id, Name: "expiry-record", Type: "text",
+			Data: []byte("rotated")})
+		assert.Empty(t, resp.Error)
+		assert.Empty(t, status(&proto.ListExpiringRecordsRequest{}))
+	})
+
+	t.Run("Cleared expiry must not be listed", func(t *testing.T) {
+		set(&proto.SetRecordExpiryRequest{})
+		assert.Empty(t, status(&proto.ListExpiringRecordsRequest{}))
+	})
+}
+
+func TestNextOTPCounter(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := testServer(ctx)
+	defer closer()
+
+	tkn, err := getJWT(testJWTkey, testUserID, testUser)
+	assert.NoError(t, err)
+	jwtCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("authorization", fmt.Sprintf("bearer %s", *tkn)))
+
+	otp := createRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{Name: "hotp-record", Type: "otp",
+		Data: []byte(`{"uri":"otpauth://hotp/test?secret=JBSWY3DPEHPK3PXP&counter=5"}`)})
+	text := createRecord(jwtCtx, t, client.storage, &proto.WriteRecordRequest{Name: "hotp-text", Type: "text",
+		Data: []byte("test")})
+
+	t.Run("Counter must be taken once", func(t *testing.T) {
+		for _, want := range []int64{0, 1, 2} {
+			r, err := client.storage.NextOTPCounter(jwtCtx, &proto.NextOTPCounterRequest{Id: otp})
+			assert.NoError(t, err)
+			assert.Empty(t, r.Error)
+			assert.Equal(t, want, r.Counter)
+		}
+	})
+
+	t.Run("Counter of other types must be rejected", func(t *testing.T) {
+		r, err := client.storage.NextOTPCounter(jwtCtx, &proto.NextOTPCounterRequest{Id: text})
+		assert.NoError(t, err)
+		assert.Equal(t, "record is not an otp", r.Error)
+	})
+}
@@ -0,0 +1,195 @@
+// Package archive implements the password-protected export of the records
+// of a user. The archive is a JSON file with the parameters of the key
+// derivation and the encrypted manifest. The key is derived from the password
+// with Argon2id, the manifest is encrypted with AES-GCM.
+package archive
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Format and Version identify the archive files.
+const (
+	Format  = "gophkeeper-export"
+	Version = 1
+)
+
+// Parameters of the Argon2id key derivation of new archives and the limits
+// of the parameters of the read ones.
+const (
+	kdfName     = "argon2id"
+	saltSize    = 16
+	keySize     = 32
+	argonTime   = 3
+	argonMemory = 64 * 1024
+	argonLanes  = 4
+
+	maxArgonTime   = 16
+	maxArgonMemory = 1024 * 1024
+)
+
+// ErrWrongPassword is returned when the archive cannot be decrypted.
+var ErrWrongPassword = errors.New("wrong password or corrupted archive")
+
+// errInvalidKDF is returned for parameters of the key derivation out of the limits.
+var errInvalidKDF = errors.New("invalid key derivation parameters")
+
+// Record is an exported record. Files, the attachments of the other
+// records, are records of the file type. `Folder` is the path of the folder,
+// `ExpiresAt` is the expiry date in Unix time.
+type Record struct {
+	Name       string            `json:"name"`
+	Type       string            `json:"type"`
+	Data       []byte            `json:"data"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	Folder     string            `json:"folder,omitempty"`
+	Tags       []string          `json:"tags,omitempty"`
+	ExpiresAt  int64             `json:"expires_at,omitempty"`
+	RotateDays int32             `json:"rotate_days,omitempty"`
+}
+
+// Manifest is the content of the archive.
+type Manifest struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Records   []Record  `json:"records"`
+}
+
+// kdf is the key derivation of the archive.
+type kdf struct {
+	Name   string `json:"name"`
+	Salt   []byte `json:"salt"`
+	Time   uint32 `json:"time"`
+	Memory uint32 `json:"memory"`
+	Lanes  uint8  `json:"lanes"`
+}
+
+// file is the archive file.
+type file struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+	KDF     kdf    `json:"kdf"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// Write encrypts the manifest with the password and writes the archive.
+func Write(w io.Writer, password string, m *Manifest) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed encode manifest: %w", err)
+	}
+
+	f := file{
+		Format:  Format,
+		Version: Version,
+		KDF: kdf{
+			Name:   kdfName,
+			Salt:   make([]byte, saltSize),
+			Time:   argonTime,
+			Memory: argonMemory,
+			Lanes:  argonLanes,
+		},
+	}
+
+	if _, err := rand.Read(f.KDF.Salt); err != nil {
+		return fmt.Errorf("failed generate salt: %w", err)
+	}
+
+	aead, err := newAEAD(password, f.KDF)
+	if err != nil {
+		return err
+	}
+
+	f.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(f.Nonce); err != nil {
+		return fmt.Errorf("failed generate nonce: %w", err)
+	}
+
+	f.Data = aead.Seal(nil, f.Nonce, data, additionalData(f))
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	err = enc.Encode(f)
+	if err != nil {
+		return fmt.Errorf("failed write archive: %w", err)
+	}
+
+	return nil
+}
+
+// Read decrypts the archive with the password and returns the manifest.
+func Read(r io.Reader, password string) (*Manifest, error) {
+	var f file
+	err := json.NewDecoder(r).Decode(&f)
+	if err != nil {
+		return nil, fmt.Errorf("failed decode archive: %w", err)
+	}
+
+	if f.Format != Format {
+		return nil, fmt.Errorf("unknown archive format %q", f.Format)
+	}
+	if f.Version > Version {
+		return nil, fmt.Errorf("archive version %v is newer than supported %v", f.Version, Version)
+	}
+	if f.KDF.Name != kdfName {
+		return nil, fmt.Errorf("unknown key derivation %q", f.KDF.Name)
+	}
+	// Archives may come from anywhere, so the cost of the derivation is limited
+	if f.KDF.Time == 0 || f.KDF.Time > maxArgonTime || f.KDF.Memory > maxArgonMemory || f.KDF.Lanes == 0 {
+		return nil, errInvalidKDF
+	}
+
+	aead, err := newAEAD(password, f.KDF)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(f.Nonce) != aead.NonceSize() {
+		return nil, ErrWrongPassword
+	}
+
+	data, err := aead.Open(nil, f.Nonce, f.Data, additionalData(f))
+	if err != nil {
+		return nil, ErrWrongPassword
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed decode manifest: %w", err)
+	}
+
+	return &m, nil
+}
+
+// additionalData binds the header of the archive to the encrypted manifest.
+func additionalData(f file) []byte {
+	return []byte(fmt.Sprintf("%s/%v/%s/%v/%v/%v", f.Format, f.Version, f.KDF.Name, f.KDF.Time, f.KDF.Memory, f.KDF.Lanes))
+}
+
+// newAEAD derives the key from the password and creates the cipher.
+func newAEAD(password string, params kdf) (cipher.AEAD, error) {
+	key := argon2.IDKey([]byte(password), params.Salt, params.Time, params.Memory, params.Lanes, keySize)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AES cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return aead, nil
+}
@@ -0,0 +1,125 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testManifest returns a manifest with records of every stored field.
+func testManifest() *Manifest {
+	return &Manifest{
+		Version:   Version,
+		CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Records: []Record{
+			{
+				Name:       "mail",
+				Type:       "login",
+				Data:       []byte(`{"password":"secret"}`),
+				Metadata:   map[string]string{"site": "example.com"},
+				Folder:     "Work/Mail",
+				Tags:       []string{"prod"},
+				ExpiresAt:  1893456000,
+				RotateDays: 90,
+			},
+			{Name: "photo", Type: "file", Data: []byte{0, 1, 2, 0xff}},
+		},
+	}
+}
+
+// rewrite decodes the archive, changes its file and encodes it back.
+func rewrite(t *testing.T, data []byte, change func(f *file)) []byte {
+	t.Helper()
+
+	var f file
+	assert.NoError(t, json.Unmarshal(data, &f))
+
+	change(&f)
+
+	out, err := json.Marshal(f)
+	assert.NoError(t, err)
+
+	return out
+}
+
+func TestRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, " pass phrase ", testManifest()))
+
+	t.Run("Archive must be read with the password", func(t *testing.T) {
+		m, err := Read(bytes.NewReader(buf.Bytes()), " pass phrase ")
+		assert.NoError(t, err)
+		assert.Equal(t, testManifest(), m)
+	})
+
+	t.Run("Manifest must not be stored in plain text", func(t *testing.T) {
+		assert.NotContains(t, buf.String(), "secret")
+		assert.NotContains(t, buf.String(), "Work/Mail")
+	})
+
+	t.Run("Password must not be trimmed", func(t *testing.T) {
+		_, err := Read(bytes.NewReader(buf.Bytes()), "pass phrase")
+		assert.ErrorIs(t, err, ErrWrongPassword)
+	})
+}
+
+func TestTampered(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, "secret", testManifest()))
+
+	tests := []struct {
+		name   string
+		change func(f *file)
+	}{
+		{name: "Changed time of the key derivation", change: func(f *file) { f.KDF.Time++ }},
+		{name: "Changed memory of the key derivation", change: func(f *file) { f.KDF.Memory /= 2 }},
+		{name: "Changed lanes of the key derivation", change: func(f *file) { f.KDF.Lanes++ }},
+		{name: "Changed salt", change: func(f *file) { f.KDF.Salt[0] ^= 1 }},
+		{name: "Changed version", change: func(f *file) { f.Version = 0 }},
+		{name: "Changed nonce", change: func(f *file) { f.Nonce[0] ^= 1 }},
+		{name: "Short nonce", change: func(f *file) { f.Nonce = f.Nonce[1:] }},
+		{name: "Changed ciphertext", change: func(f *file) { f.Data[0] ^= 1 }},
+		{name: "Changed tag", change: func(f *file) { f.Data[len(f.Data)-1] ^= 1 }},
+		{name: "Truncated ciphertext", change: func(f *file) { f.Data = f.Data[:len(f.Data)/2] }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" must be rejected", func(t *testing.T) {
+			_, err := Read(bytes.NewReader(rewrite(t, buf.Bytes(), tt.change)), "secret")
+			assert.ErrorIs(t, err, ErrWrongPassword)
+		})
+	}
+}
+
+func TestInvalidHeader(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, "secret", testManifest()))
+
+	tests := []struct {
+		name   string
+		change func(f *file)
+		err    error
+	}{
+		{name: "Zero time", change: func(f *file) { f.KDF.Time = 0 }, err: errInvalidKDF},
+		{name: "Time over the limit", change: func(f *file) { f.KDF.Time = maxArgonTime + 1 }, err: errInvalidKDF},
+		{name: "Memory over the limit", change: func(f *file) { f.KDF.Memory = maxArgonMemory + 1 }, err: errInvalidKDF},
+		{name: "Zero lanes", change: func(f *file) { f.KDF.Lanes = 0 }, err: errInvalidKDF},
+		{name: "Unknown key derivation", change: func(f *file) { f.KDF.Name = "scrypt" }},
+		{name: "Unknown format", change: func(f *file) { f.Format = "other" }},
+		{name: "Newer version", change: func(f *file) { f.Version = Version + 1 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" must be rejected", func(t *testing.T) {
+			_, err := Read(bytes.NewReader(rewrite(t, buf.Bytes(), tt.change)), "secret")
+			assert.Error(t, err)
+			assert.NotErrorIs(t, err, ErrWrongPassword)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
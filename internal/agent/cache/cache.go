@@ -0,0 +1,541 @@
+// Package cache implements the encrypted local cache of the agent. The cache
+// keeps the records read from the server and the changes made while the
+// server is unavailable in a single file. The file is encrypted with
+// AES-GCM, the key is derived from the user's password with Argon2id.
+package cache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+)
+
+var defaultPermition fs.FileMode = 0600
+
+// Parameters of the Argon2id key derivation.
+const (
+	saltSize    = 16
+	deviceSize  = 8
+	keySize     = 32
+	argonTime   = 1
+	argonMemory = 64 * 1024
+	argonLanes  = 4
+)
+
+// ErrWrongPassword is returned when the cache file cannot be decrypted.
+var ErrWrongPassword = errors.New("wrong password or corrupted cache")
+
+// Kinds of queued operations.
+const (
+	OpWrite  = "write"
+	OpDelete = "delete"
+)
+
+// Unit is the description of a record without its value. `Versions` is
+// the revision vector of the value known to the agent.
+type Unit struct {
+	ID         int32             `json:"id"`
+	Name       string            `json:"name"`
+	Type       string            `json:"type"`
+	Owner      int32             `json:"owner"`
+	OwnerLogin string            `json:"owner_login"`
+	Shared     bool              `json:"shared"`
+	Permission string            `json:"permission"`
+	Versions   map[string]int64  `json:"versions"`
+	Metadata   map[string]string `json:"metadata"`
+	Conflict   bool              `json:"conflict"`
+}
+
+// Record is a record with its decrypted value.
+type Record struct {
+	Unit
+	Data []byte `json:"data"`
+}
+
+// Op is a change made while the server was unavailable. A write with
+// a zero `ID` creates a new record. A nil `Metadata` keeps the metadata of
+// the record. `Versions` is the revision vector of the value the change was
+// made to.
+type Op struct {
+	Kind     string            `json:"kind"`
+	Vault    int32             `json:"vault"`
+	ID       int32             `json:"id"`
+	Name     string            `json:"name"`
+	Type     string            `json:"type"`
+	Data     []byte            `json:"data"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Versions map[string]int64  `json:"versions"`
+}
+
+// Rejected is a queued change the server rejected on replay, with the
+// reason. Rejected changes are kept until the user retries or clears them.
+type Rejected struct {
+	Op
+	Error string `json:"error"`
+}
+
+// scope is the cached state of the records of one scope: the personal
+// records, a team vault or the records of an emergency access grantor.
+// `Cursor` is the revision the scope was synchronized to.
+type scope struct {
+	Units   []Unit           `json:"units"`
+	Records map[int32]Record `json:"records"`
+	Cursor  int64            `json:"cursor"`
+}
+
+// state is the content of the cache file. `Device` identifies the agent
+// in the revision vectors of records.
+type state struct {
+	Device   string            `json:"device"`
+	Scopes   map[string]*scope `json:"scopes"`
+	Queue    []Op              `json:"queue"`
+	Rejected []Rejected        `json:"rejected"`
+}
+
+// file is the encrypted cache file.
+type file struct {
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+// Cache is the encrypted local cache. Every change is written to the file
+// immediately, so the cache survives a crash of the agent.
+type Cache struct {
+	mu      sync.Mutex
+	path    string
+	salt    []byte
+	aead    cipher.AEAD
+	state   state
+	offline bool
+}
+
+// Open reads and decrypts the cache file. If the file does not exist,
+// an empty cache is created with a new salt and a new device ID.
+func Open(path string, password string) (*Cache, error) {
+	c := &Cache{
+		path: path,
+		state: state{
+			Scopes: map[string]*scope{},
+		},
+	}
+
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		c.salt = make([]byte, saltSize)
+		if _, err := rand.Read(c.salt); err != nil {
+			return nil, fmt.Errorf("failed generate salt: %w", err)
+		}
+
+		c.aead, err = newAEAD(password, c.salt)
+		if err != nil {
+			return nil, err
+		}
+
+		c.state.Device, err = newDevice()
+		if err != nil {
+			return nil, err
+		}
+
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed read cache: %w", err)
+	}
+
+	var f file
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("failed decode cache: %w", err)
+	}
+
+	c.salt = f.Salt
+	c.aead, err = newAEAD(password, c.salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(f.Nonce) != c.aead.NonceSize() {
+		return nil, ErrWrongPassword
+	}
+
+	data, err := c.aead.Open(nil, f.Nonce, f.Data, nil)
+	if err != nil {
+		return nil, ErrWrongPassword
+	}
+
+	if err := json.Unmarshal(data, &c.state); err != nil {
+		return nil, fmt.Errorf("failed decode cache state: %w", err)
+	}
+
+	if c.state.Scopes == nil {
+		c.state.Scopes = map[string]*scope{}
+	}
+
+	// Caches created before revision vectors have no device
+	if c.state.Device == "" {
+		c.state.Device, err = newDevice()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// Device returns the ID of the agent in the revision vectors of records.
+func (c *Cache) Device() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.state.Device
+}
+
+// SetVersions replaces the revision vector of the listed record after
+// the agent changed its value.
+func (c *Cache) SetVersions(name string, id int32, versions map[string]int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := c.scope(name)
+	for i, v := range s.Units {
+		if v.ID == id {
+			s.Units[i].Versions = versions
+		}
+	}
+
+	if rec, ok := s.Records[id]; ok {
+		rec.Versions = versions
+		s.Records[id] = rec
+	}
+
+	return c.save()
+}
+
+// SetOffline marks that the server was unavailable during the session.
+func (c *Cache) SetOffline() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.offline = true
+}
+
+// Offline reports whether the server was unavailable during the session.
+func (c *Cache) Offline() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.offline
+}
+
+// Units returns the cached list of records of the scope.
+func (c *Cache) Units(name string) ([]Unit, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.state.Scopes[name]
+	if !ok {
+		return nil, false
+	}
+
+	return s.Units, true
+}
+
+// SetUnits replaces the cached list of records of the scope. Cached values
+// of the records which are no longer listed are removed.
+func (c *Cache) SetUnits(name string, units []Unit) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := c.scope(name)
+	s.Units = units
+
+	listed := make(map[int32]bool, len(units))
+	for _, v := range units {
+		listed[v.ID] = true
+	}
+
+	for id := range s.Records {
+		if !listed[id] {
+			delete(s.Records, id)
+		}
+	}
+
+	return c.save()
+}
+
+// Record returns the cached record of the scope.
+func (c *Cache) Record(name string, id int32) (Record, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.state.Scopes[name]
+	if !ok {
+		return Record{}, false
+	}
+
+	rec, ok := s.Records[id]
+	return rec, ok
+}
+
+// SetRecord stores the record of the scope and adds it to the list
+// of records if it is not listed yet.
+func (c *Cache) SetRecord(name string, rec Record) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := c.scope(name)
+	s.Records[rec.ID] = rec
+	s.upsertUnit(rec.Unit, false)
+
+	return c.save()
+}
+
+// DeleteRecord removes the record from the scope.
+func (c *Cache) DeleteRecord(name string, id int32) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.scope(name).deleteUnit(id)
+
+	return c.save()
+}
+
+// Cursor returns the revision the scope was synchronized to.
+func (c *Cache) Cursor(name string) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.state.Scopes[name]
+	if !ok {
+		return 0
+	}
+
+	return s.Cursor
+}
+
+// ApplySync merges the changes received from the server into the scope:
+// changed records replace the listed ones, deleted records are removed and
+// the cursor is moved to the new revision.
+func (c *Cache) ApplySync(name string, changed []Unit, deleted []int32, cursor int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := c.scope(name)
+	for _, v := range changed {
+		s.upsertUnit(v, true)
+	}
+
+	for _, id := range deleted {
+		s.deleteUnit(id)
+	}
+
+	s.Cursor = cursor
+
+	return c.save()
+}
+
+// Enqueue adds a change to the queue of changes to replay.
+func (c *Cache) Enqueue(op Op) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.state.Queue = append(c.state.Queue, op)
+
+	return c.save()
+}
+
+// Queue returns the queued changes in the order they were made.
+func (c *Cache) Queue() []Op {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]Op(nil), c.state.Queue...)
+}
+
+// Dequeue removes the oldest queued change after it was replayed.
+func (c *Cache) Dequeue() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.state.Queue) == 0 {
+		return nil
+	}
+
+	c.state.Queue = c.state.Queue[1:]
+
+	return c.save()
+}
+
+// Reject moves the oldest queued change to the rejected changes with
+// the reason the server gave.
+func (c *Cache) Reject(reason string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.state.Queue) == 0 {
+		return nil
+	}
+
+	c.state.Rejected = append(c.state.Rejected, Rejected{Op: c.state.Queue[0], Error: reason})
+	c.state.Queue = c.state.Queue[1:]
+
+	return c.save()
+}
+
+// Rejected returns the changes the server rejected in the order they were made.
+func (c *Cache) Rejected() []Rejected {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]Rejected(nil), c.state.Rejected...)
+}
+
+// ClearRejected removes the rejected changes. If `retry` is set, they are
+// queued again to be replayed on the next connection.
+func (c *Cache) ClearRejected(retry bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if retry {
+		for _, v := range c.state.Rejected {
+			c.state.Queue = append(c.state.Queue, v.Op)
+		}
+	}
+
+	c.state.Rejected = nil
+
+	return c.save()
+}
+
+/* UTILS. */
+
+// scope returns the state of the scope, creating it if needed.
+func (c *Cache) scope(name string) *scope {
+	s, ok := c.state.Scopes[name]
+	if !ok {
+		s = &scope{}
+		c.state.Scopes[name] = s
+	}
+
+	if s.Records == nil {
+		s.Records = map[int32]Record{}
+	}
+
+	return s
+}
+
+// upsertUnit adds the record to the list. A listed record is replaced
+// only if `replace` is set.
+func (s *scope) upsertUnit(unit Unit, replace bool) {
+	for i, v := range s.Units {
+		if v.ID == unit.ID {
+			if replace {
+				s.Units[i] = unit
+			}
+			return
+		}
+	}
+
+	s.Units = append(s.Units, unit)
+}
+
+// deleteUnit removes the record and its value from the scope.
+func (s *scope) deleteUnit(id int32) {
+	delete(s.Records, id)
+
+	units := make([]Unit, 0, len(s.Units))
+	for _, v := range s.Units {
+		if v.ID != id {
+			units = append(units, v)
+		}
+	}
+	s.Units = units
+}
+
+// newDevice generates a random ID of the agent.
+func newDevice() (string, error) {
+	device := make([]byte, deviceSize)
+	if _, err := rand.Read(device); err != nil {
+		return "", fmt.Errorf("failed generate device: %w", err)
+	}
+
+	return hex.EncodeToString(device), nil
+}
+
+// save encrypts the state with a new nonce and replaces the cache file.
+func (c *Cache) save() error {
+	data, err := json.Marshal(c.state)
+	if err != nil {
+		return fmt.Errorf("failed encode cache state: %w", err)
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed generate nonce: %w", err)
+	}
+
+	raw, err := json.Marshal(file{
+		Salt:  c.salt,
+		Nonce: nonce,
+		Data:  c.aead.Seal(nil, nonce, data, nil),
+	})
+	if err != nil {
+		return fmt.Errorf("failed encode cache: %w", err)
+	}
+
+	// Write a temporary file first, so a crash never leaves a broken cache
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed create cache: %w", err)
+	}
+
+	if _, err := tmp.Write(raw); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed write cache: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed close cache: %w", err)
+	}
+
+	if err := os.Chmod(tmp.Name(), defaultPermition); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed chmod cache: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed replace cache: %w", err)
+	}
+
+	return nil
+}
+
+// newAEAD derives the key from the password and creates the cipher.
+func newAEAD(password string, salt []byte) (cipher.AEAD, error) {
+	key := argon2.IDKey([]byte(password), salt, argonTime, argonMemory, argonLanes, keySize)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AES cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create chiper: %w", err)
+	}
+
+	return aead, nil
+}
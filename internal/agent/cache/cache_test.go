@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testCache creates a cache file with a record and a queued change.
+func testCache(t *testing.T, password string) (string, *Cache) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "cache")
+
+	c, err := Open(path, password)
+	assert.NoError(t, err)
+
+	assert.NoError(t, c.SetRecord("personal", Record{
+		Unit: Unit{ID: 1, Name: "mail", Type: "login", Versions: map[string]int64{c.Device(): 1}},
+		Data: []byte(`{"password":"secret"}`),
+	}))
+	assert.NoError(t, c.Enqueue(Op{Kind: OpWrite, Name: "note", Type: "text", Data: []byte("offline")}))
+
+	return path, c
+}
+
+// rewrite decodes the cache file, changes it and writes it back.
+func rewrite(t *testing.T, path string, change func(f *file)) {
+	t.Helper()
+
+	raw, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	var f file
+	assert.NoError(t, json.Unmarshal(raw, &f))
+
+	change(&f)
+
+	raw, err = json.Marshal(f)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(path, raw, defaultPermition))
+}
+
+func TestRoundTrip(t *testing.T) {
+	path, c := testCache(t, " pass phrase ")
+
+	t.Run("Cache must be read with the password", func(t *testing.T) {
+		opened, err := Open(path, " pass phrase ")
+		assert.NoError(t, err)
+		assert.Equal(t, c.Device(), opened.Device())
+		assert.Equal(t, c.Queue(), opened.Queue())
+
+		rec, ok := opened.Record("personal", 1)
+		assert.True(t, ok)
+		assert.Equal(t, []byte(`{"password":"secret"}`), rec.Data)
+
+		units, ok := opened.Units("personal")
+		assert.True(t, ok)
+		assert.Len(t, units, 1)
+	})
+
+	t.Run("Cache file must be private and encrypted", func(t *testing.T) {
+		info, err := os.Stat(path)
+		assert.NoError(t, err)
+		assert.Equal(t, defaultPermition, info.Mode().Perm())
+
+		raw, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(raw), "secret")
+		assert.NotContains(t, string(raw), "offline")
+	})
+
+	t.Run("Wrong password must be rejected", func(t *testing.T) {
+		_, err := Open(path, "pass phrase")
+		assert.ErrorIs(t, err, ErrWrongPassword)
+	})
+}
+
+func TestTampered(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(f *file)
+	}{
+		{name: "Changed salt", change: func(f *file) { f.Salt[0] ^= 1 }},
+		{name: "Changed nonce", change: func(f *file) { f.Nonce[0] ^= 1 }},
+		{name: "Short nonce", change: func(f *file) { f.Nonce = f.Nonce[1:] }},
+		{name: "Missing nonce", change: func(f *file) { f.Nonce = nil }},
+		{name: "Changed ciphertext", change: func(f *file) { f.Data[0] ^= 1 }},
+		{name: "Changed tag", change: func(f *file) { f.Data[len(f.Data)-1] ^= 1 }},
+		{name: "Truncated ciphertext", change: func(f *file) { f.Data = f.Data[:len(f.Data)/2] }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" must be rejected", func(t *testing.T) {
+			path, _ := testCache(t, "secret")
+			rewrite(t, path, tt.change)
+
+			assert.NotPanics(t, func() {
+				_, err := Open(path, "secret")
+				assert.ErrorIs(t, err, ErrWrongPassword)
+			})
+		})
+	}
+
+	t.Run("Broken file must be rejected", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "cache")
+		assert.NoError(t, os.WriteFile(path, []byte(`{"salt":`), defaultPermition))
+
+		_, err := Open(path, "secret")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,411 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"strconv"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/cache"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errorUpdateCache = "failed update cache: %w"
+
+// Replay sends the changes queued while the server was unavailable in the
+// order they were made. Replay stops if the server is still unavailable,
+// changes rejected by the server are kept in the rejected changes of the
+// cache and their errors are returned. It returns the number of changes
+// accepted by the server.
+func (c Client) Replay() (int, error) {
+	if c.Cache == nil {
+		return 0, nil
+	}
+
+	// Replayed changes must not be queued again
+	cl := Client{Conn: c.Conn, Token: c.Token}
+
+	var sent int
+	var errs []error
+	for _, op := range c.Cache.Queue() {
+		cl.Vault = op.Vault
+
+		// Writes never queue, they keep the cache for the revision vectors
+		wr := c
+		wr.Vault = op.Vault
+
+		var err error
+		switch op.Kind {
+		case cache.OpWrite:
+			_, err = wr.writeData(op.ID, op.Type, op.Name, op.Data, op.Metadata, op.Versions)
+		case cache.OpDelete:
+			_, err = cl.DeleteFile(op.ID)
+		default:
+			err = fmt.Errorf("unknown queued change: %s", op.Kind)
+		}
+
+		if isOffline(err) {
+			c.Cache.SetOffline()
+			break
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %q: %w", op.Kind, op.Name, err))
+
+			if err := c.Cache.Reject(err.Error()); err != nil {
+				return sent, fmt.Errorf(errorUpdateCache, err)
+			}
+			continue
+		}
+
+		sent++
+		if err := c.Cache.Dequeue(); err != nil {
+			return sent, fmt.Errorf(errorUpdateCache, err)
+		}
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+// WriteData writes the value read into memory, a zero `id` creates a new
+// record. Values of files are written in chunks like `WriteFile` does.
+func (c Client) WriteData(id int32, typ string, name string, data []byte) (*proto.WriteRecordResponse, error) {
+	return c.WriteDataWithMetadata(id, typ, name, data, nil)
+}
+
+// WriteDataWithMetadata writes the value read into memory with the metadata
+// of the record, a nil `metadata` keeps the metadata of an existing record.
+func (c Client) WriteDataWithMetadata(id int32, typ string, name string, data []byte,
+	metadata map[string]string) (*proto.WriteRecordResponse, error) {
+	resp, err := c.writeData(id, typ, name, data, metadata, c.baseVersions(id))
+	if err != nil {
+		// Server is unavailable, send the record on the next connection
+		if c.Cache != nil && c.Grantor == "" && isOffline(err) {
+			_, errQueue := c.queueValue(id, typ, name, data, metadata)
+			return &proto.WriteRecordResponse{}, errQueue
+		}
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// writeData writes the record value from memory. `versions` is the revision
+// vector of the value the change was made to.
+func (c Client) writeData(id int32, typ string, name string, data []byte,
+	metadata map[string]string, versions map[string]int64) (*proto.WriteRecordResponse, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewStorageClient(c.Conn)
+	stream, err := client.WriteRecord(ctx)
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+
+	// Send the data in chunks
+	chunkSize := 4096
+	for i := 0; i == 0 || i < len(data); i += chunkSize {
+		end := min(i+chunkSize, len(data))
+
+		err = stream.Send(&proto.WriteRecordRequest{Id: id, Name: name, Data: data[i:end], Type: typ,
+			Device: c.device(), Versions: versions, Metadata: metadata})
+		if err != nil {
+			return nil, fmt.Errorf("failed send stream: %w", err)
+		}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, fmt.Errorf("closed stream has error: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	err = c.cacheVersions(id, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+/* UTILS. */
+
+// isOffline reports whether the call failed because the server is unavailable.
+func isOffline(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+// cacheScope returns the name of the cached scope the client works with.
+func (c Client) cacheScope() string {
+	if c.Grantor != "" {
+		return "grantor:" + c.Grantor
+	}
+
+	if c.Vault != 0 {
+		return "vault:" + strconv.Itoa(int(c.Vault))
+	}
+
+	return "personal"
+}
+
+// cacheUnits stores the list of records read from the server.
+func (c Client) cacheUnits(units []*proto.StorageUnit) error {
+	if c.Cache == nil {
+		return nil
+	}
+
+	cached := make([]cache.Unit, 0, len(units))
+	for _, v := range units {
+		cached = append(cached, cache.Unit{
+			ID:         v.Id,
+			Name:       v.Name,
+			Type:       v.Type,
+			Owner:      v.Owner,
+			OwnerLogin: v.OwnerLogin,
+			Shared:     v.Shared,
+			Permission: v.Permission,
+			Versions:   v.Versions,
+			Metadata:   v.Metadata,
+			Conflict:   v.Conflict,
+		})
+	}
+
+	if err := c.Cache.SetUnits(c.cacheScope(), cached); err != nil {
+		return fmt.Errorf(errorUpdateCache, err)
+	}
+
+	return nil
+}
+
+// cachedUnits returns the cached list of records if the server is unavailable.
+func (c Client) cachedUnits(err error) (*proto.ReadAllRecordResponse, bool) {
+	if c.Cache == nil || !isOffline(err) {
+		return nil, false
+	}
+
+	c.Cache.SetOffline()
+
+	units, ok := c.Cache.Units(c.cacheScope())
+	if !ok {
+		return nil, false
+	}
+
+	resp := &proto.ReadAllRecordResponse{
+		Units: make([]*proto.StorageUnit, 0, len(units)),
+	}
+	for _, v := range units {
+		resp.Units = append(resp.Units, &proto.StorageUnit{
+			Id:         v.ID,
+			Name:       v.Name,
+			Type:       v.Type,
+			Owner:      v.Owner,
+			OwnerLogin: v.OwnerLogin,
+			Shared:     v.Shared,
+			Permission: v.Permission,
+			Versions:   v.Versions,
+			Metadata:   v.Metadata,
+			Conflict:   v.Conflict,
+		})
+	}
+
+	return resp, true
+}
+
+// cacheRecord stores the record read from the server.
+func (c Client) cacheRecord(id int32, rec *proto.ReadRecordResponse) error {
+	if c.Cache == nil {
+		return nil
+	}
+
+	err := c.Cache.SetRecord(c.cacheScope(), cache.Record{
+		Unit: cache.Unit{
+			ID:         id,
+			Name:       rec.Name,
+			Type:       rec.Type,
+			Owner:      rec.Owner,
+			OwnerLogin: rec.OwnerLogin,
+			Versions:   rec.Versions,
+			Metadata:   rec.Metadata,
+			Conflict:   rec.Conflict,
+		},
+		Data: rec.Data,
+	})
+	if err != nil {
+		return fmt.Errorf(errorUpdateCache, err)
+	}
+
+	return nil
+}
+
+// cachedRecord returns the cached record if the server is unavailable.
+func (c Client) cachedRecord(id int32, err error) (*proto.ReadRecordResponse, bool) {
+	if c.Cache == nil || !isOffline(err) {
+		return nil, false
+	}
+
+	c.Cache.SetOffline()
+
+	rec, ok := c.Cache.Record(c.cacheScope(), id)
+	if !ok {
+		return nil, false
+	}
+
+	return &proto.ReadRecordResponse{
+		Name:       rec.Name,
+		Type:       rec.Type,
+		Data:       rec.Data,
+		Owner:      rec.Owner,
+		OwnerLogin: rec.OwnerLogin,
+		Versions:   rec.Versions,
+		Metadata:   rec.Metadata,
+		Conflict:   rec.Conflict,
+	}, true
+}
+
+// uncacheRecord removes the deleted record from the cache.
+func (c Client) uncacheRecord(id int32) error {
+	if c.Cache == nil {
+		return nil
+	}
+
+	if err := c.Cache.DeleteRecord(c.cacheScope(), id); err != nil {
+		return fmt.Errorf(errorUpdateCache, err)
+	}
+
+	return nil
+}
+
+// queueWrite queues the write if the server is unavailable. The value of
+// a file is read now, so later changes of the file are not sent.
+func (c Client) queueWrite(err error, id int32, typ string, name string, data string) (bool, error) {
+	if c.Cache == nil || c.Grantor != "" || !isOffline(err) {
+		return false, nil
+	}
+
+	value := []byte(data)
+	if typ == "file" {
+		value, err = os.ReadFile(data)
+		if err != nil {
+			return false, fmt.Errorf("failed read file: %w", err)
+		}
+	}
+
+	return c.queueValue(id, typ, name, value, nil)
+}
+
+// queueValue queues the write of the value read into memory.
+func (c Client) queueValue(id int32, typ string, name string, value []byte, metadata map[string]string) (bool, error) {
+	c.Cache.SetOffline()
+
+	versions := c.baseVersions(id)
+	err := c.Cache.Enqueue(cache.Op{
+		Kind:     cache.OpWrite,
+		Vault:    c.Vault,
+		ID:       id,
+		Name:     name,
+		Type:     typ,
+		Data:     value,
+		Metadata: metadata,
+		Versions: versions,
+	})
+	if err != nil {
+		return false, fmt.Errorf(errorUpdateCache, err)
+	}
+
+	if id == 0 {
+		return true, nil
+	}
+
+	// Show the new value of a cached record
+	if rec, ok := c.Cache.Record(c.cacheScope(), id); ok {
+		rec.Data = value
+		if err := c.Cache.SetRecord(c.cacheScope(), rec); err != nil {
+			return false, fmt.Errorf(errorUpdateCache, err)
+		}
+	}
+
+	// Count the queued write, so the next queued write of the record
+	// descends from it
+	next := maps.Clone(versions)
+	if next == nil {
+		next = map[string]int64{}
+	}
+	next[c.device()]++
+
+	if err := c.Cache.SetVersions(c.cacheScope(), id, next); err != nil {
+		return false, fmt.Errorf(errorUpdateCache, err)
+	}
+
+	return true, nil
+}
+
+// queueDelete queues the deletion if the server is unavailable.
+func (c Client) queueDelete(err error, id int32) (bool, error) {
+	if c.Cache == nil || c.Grantor != "" || !isOffline(err) {
+		return false, nil
+	}
+
+	c.Cache.SetOffline()
+
+	err = c.Cache.Enqueue(cache.Op{
+		Kind:  cache.OpDelete,
+		Vault: c.Vault,
+		ID:    id,
+	})
+	if err != nil {
+		return false, fmt.Errorf(errorUpdateCache, err)
+	}
+
+	return true, c.uncacheRecord(id)
+}
+
+// device returns the ID of the agent in the revision vectors of records.
+// Without the cache the agent has no ID and its writes replace records.
+func (c Client) device() string {
+	if c.Cache == nil {
+		return ""
+	}
+
+	return c.Cache.Device()
+}
+
+// baseVersions returns the revision vector of the cached value of the record.
+func (c Client) baseVersions(id int32) map[string]int64 {
+	if c.Cache == nil || id == 0 {
+		return nil
+	}
+
+	if rec, ok := c.Cache.Record(c.cacheScope(), id); ok && rec.Versions != nil {
+		return rec.Versions
+	}
+
+	units, _ := c.Cache.Units(c.cacheScope())
+	for _, v := range units {
+		if v.ID == id {
+			return v.Versions
+		}
+	}
+
+	return nil
+}
+
+// cacheVersions stores the revision vector of the written value. A value
+// kept as a conflicting version does not change the cached record.
+func (c Client) cacheVersions(id int32, resp *proto.WriteRecordResponse) error {
+	if c.Cache == nil || id == 0 || resp == nil || resp.Conflict {
+		return nil
+	}
+
+	if err := c.Cache.SetVersions(c.cacheScope(), id, resp.Versions); err != nil {
+		return fmt.Errorf(errorUpdateCache, err)
+	}
+
+	return nil
+}
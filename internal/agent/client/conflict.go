@@ -0,0 +1,61 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+)
+
+// ListConflicts returns the current version of the record and the versions
+// written concurrently with it.
+func (c Client) ListConflicts(id int32) (*proto.ListConflictsResponse, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewStorageClient(c.Conn)
+	resp, err := client.ListConflicts(ctx, &proto.ListConflictsRequest{
+		Id: id,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	return resp, nil
+}
+
+// ResolveConflict keeps the picked version of the record, `keep` is the ID
+// of a conflicting version or zero for the current one. The cached value of
+// the record is read again.
+func (c Client) ResolveConflict(id int32, keep int32, mergeMetadata bool) (*proto.ResolveConflictResponse, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewStorageClient(c.Conn)
+	resp, err := client.ResolveConflict(ctx, &proto.ResolveConflictRequest{
+		Id:            id,
+		Keep:          keep,
+		MergeMetadata: mergeMetadata,
+		Device:        c.device(),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	if c.Cache != nil {
+		if _, err := c.ReadFile(id); err != nil {
+			return nil, fmt.Errorf("failed read resolved file: %w", err)
+		}
+	}
+
+	return resp, nil
+}
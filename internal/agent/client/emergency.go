@@ -0,0 +1,106 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+)
+
+func (c Client) AddEmergencyContact(login string, waitHours int32) (*proto.AddEmergencyContactResponse, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewUserClient(c.Conn)
+	resp, err := client.AddEmergencyContact(ctx, &proto.AddEmergencyContactRequest{
+		Login:     login,
+		WaitHours: waitHours,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	return resp, nil
+}
+
+func (c Client) RemoveEmergencyContact(login string) (*proto.RemoveEmergencyContactResponse, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewUserClient(c.Conn)
+	resp, err := client.RemoveEmergencyContact(ctx, &proto.RemoveEmergencyContactRequest{
+		Login: login,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	return resp, nil
+}
+
+func (c Client) ListEmergencyContacts() (*proto.ListEmergencyContactsResponse, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewUserClient(c.Conn)
+	resp, err := client.ListEmergencyContacts(ctx, &proto.ListEmergencyContactsRequest{})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	return resp, nil
+}
+
+func (c Client) RequestEmergencyAccess(login string) (*proto.RequestEmergencyAccessResponse, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewUserClient(c.Conn)
+	resp, err := client.RequestEmergencyAccess(ctx, &proto.RequestEmergencyAccessRequest{
+		Login: login,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	return resp, nil
+}
+
+func (c Client) RejectEmergencyAccess(login string) (*proto.RejectEmergencyAccessResponse, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewUserClient(c.Conn)
+	resp, err := client.RejectEmergencyAccess(ctx, &proto.RejectEmergencyAccessRequest{
+		Login: login,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+)
+
+// SetRecordExpiry replaces the expiry date in Unix time and the rotation
+// interval in days of the record, zero values remove them.
+func (c Client) SetRecordExpiry(id int32, expiresAt int64, rotateDays int32) error {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewStorageClient(c.Conn)
+	resp, err := client.SetRecordExpiry(ctx, &proto.SetRecordExpiryRequest{
+		Id:         id,
+		ExpiresAt:  expiresAt,
+		RotateDays: rotateDays,
+	})
+
+	if err != nil {
+		return fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return responseError(resp.Error)
+	}
+
+	return nil
+}
+
+// ListExpiringRecords returns the records which expire or are due for
+// rotation within the number of days, zero is the default of the server.
+func (c Client) ListExpiringRecords(withinDays int32) ([]*proto.StorageUnit, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewStorageClient(c.Conn)
+	resp, err := client.ListExpiringRecords(ctx, &proto.ListExpiringRecordsRequest{
+		WithinDays: withinDays,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	return resp.Units, nil
+}
@@ -0,0 +1,157 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+)
+
+// CreateFolder creates a folder of the own records in the parent folder,
+// zero parent is the root.
+func (c Client) CreateFolder(parent int32, name string) (*proto.Folder, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewStorageClient(c.Conn)
+	resp, err := client.CreateFolder(ctx, &proto.CreateFolderRequest{
+		Parent: parent,
+		Name:   name,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	return resp.Folder, nil
+}
+
+// RenameFolder renames the folder.
+func (c Client) RenameFolder(id int32, name string) error {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewStorageClient(c.Conn)
+	resp, err := client.RenameFolder(ctx, &proto.RenameFolderRequest{
+		Id:   id,
+		Name: name,
+	})
+
+	if err != nil {
+		return fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return responseError(resp.Error)
+	}
+
+	return nil
+}
+
+// DeleteFolder deletes the folder with its subfolders, the records of the
+// folders are moved to the parent of the folder.
+func (c Client) DeleteFolder(id int32) error {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewStorageClient(c.Conn)
+	resp, err := client.DeleteFolder(ctx, &proto.DeleteFolderRequest{Id: id})
+
+	if err != nil {
+		return fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return responseError(resp.Error)
+	}
+
+	return nil
+}
+
+// ListFolders returns all folders of the own records.
+func (c Client) ListFolders() ([]*proto.Folder, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewStorageClient(c.Conn)
+	resp, err := client.ListFolders(ctx, &proto.ListFoldersRequest{})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	return resp.Folders, nil
+}
+
+// MoveRecord moves the own record to the folder, zero folder is the root.
+func (c Client) MoveRecord(id int32, folder int32) error {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewStorageClient(c.Conn)
+	resp, err := client.MoveRecord(ctx, &proto.MoveRecordRequest{
+		Id:     id,
+		Folder: folder,
+	})
+
+	if err != nil {
+		return fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return responseError(resp.Error)
+	}
+
+	return nil
+}
+
+// TagRecord labels the own record with the tags.
+func (c Client) TagRecord(id int32, tags []string) error {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewStorageClient(c.Conn)
+	resp, err := client.TagRecord(ctx, &proto.TagRecordRequest{
+		Id:   id,
+		Tags: tags,
+	})
+
+	if err != nil {
+		return fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return responseError(resp.Error)
+	}
+
+	return nil
+}
+
+// UntagRecord removes the tags from the own record.
+func (c Client) UntagRecord(id int32, tags []string) error {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewStorageClient(c.Conn)
+	resp, err := client.UntagRecord(ctx, &proto.UntagRecordRequest{
+		Id:   id,
+		Tags: tags,
+	})
+
+	if err != nil {
+		return fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return responseError(resp.Error)
+	}
+
+	return nil
+}
@@ -8,8 +8,12 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
+	"strings"
 
+	"github.com/dedpnd/GophKeeper/internal/agent/cache"
 	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+	"github.com/dedpnd/GophKeeper/internal/srp"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
@@ -18,10 +22,45 @@ import (
 var maxMsgSize = 100000648
 var errorResponseFinished = "response finished error: %w"
 var errorEesponseReturn = "response return error: %w"
+var vaultHeader = "x-vault-id"
 
+// ErrNotFound is returned when the server reports that the requested record,
+// folder, user or other object does not exist.
+var ErrNotFound = errors.New("not found")
+
+// responseError converts the error message of a server response. Responses
+// carry errors as text, messages about missing objects match `ErrNotFound`.
+func responseError(msg string) error {
+	if strings.HasSuffix(msg, " not found") {
+		return fmt.Errorf(errorEesponseReturn, notFoundError(msg))
+	}
+
+	return fmt.Errorf(errorEesponseReturn, errors.New(msg))
+}
+
+// notFoundError is the error message of a missing object.
+type notFoundError string
+
+func (e notFoundError) Error() string {
+	return string(e)
+}
+
+// Is makes the error match `ErrNotFound`.
+func (e notFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// Client is a gRPC client of the GophKeeper server. A non-zero `Vault`
+// targets the storage calls at a team vault instead of personal records.
+// A non-empty `Grantor` reads the records of the user who granted emergency
+// access to the client. With a `Cache` the records are available while the
+// server is unavailable and the changes made meanwhile are queued.
 type Client struct {
-	Conn  *grpc.ClientConn
-	Token string
+	Conn    *grpc.ClientConn
+	Token   string
+	Vault   int32
+	Grantor string
+	Cache   *cache.Cache
 }
 
 func NewClient(addr string, certPath string, token string) (*Client, error) {
@@ -47,6 +86,17 @@ func NewClient(addr string, certPath string, token string) (*Client, error) {
 	}, nil
 }
 
+// authContext returns a context with the authorization token and, if set,
+// the team vault in the gRPC metadata.
+func (c Client) authContext() context.Context {
+	md := metadata.Pairs("authorization", fmt.Sprintf("bearer %s", c.Token))
+	if c.Vault != 0 {
+		md.Set(vaultHeader, strconv.Itoa(int(c.Vault)))
+	}
+
+	return metadata.NewOutgoingContext(context.Background(), md)
+}
+
 func (c Client) Close() error {
 	err := c.Conn.Close()
 	if err != nil {
@@ -69,7 +119,7 @@ func (c Client) Register(login string, password string) (*proto.RegisterResponse
 	}
 
 	if resp.Error != "" {
-		return nil, fmt.Errorf(errorEesponseReturn, resp.Error)
+		return nil, responseError(resp.Error)
 	}
 
 	return resp, nil
@@ -88,26 +138,130 @@ func (c Client) Login(login string, password string) (*proto.LoginResponse, erro
 	}
 
 	if resp.Error != "" {
-		return nil, fmt.Errorf(errorEesponseReturn, resp.Error)
+		return nil, responseError(resp.Error)
 	}
 
 	return resp, nil
 }
 
-func (c Client) ReadAllFile() (*proto.ReadAllRecordResponse, error) {
+func (c Client) RegisterSRP(login string, password string) (*proto.SRPRegisterResponse, error) {
+	// Only the salt and the verifier are sent to the server
+	salt, verifier, err := srp.NewVerifier(login, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed create verifier: %w", err)
+	}
+
+	// Create client
+	client := proto.NewUserClient(c.Conn)
+	resp, err := client.SRPRegister(context.Background(), &proto.SRPRegisterRequest{
+		Login:    login,
+		Salt:     salt,
+		Verifier: verifier,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	return resp, nil
+}
+
+func (c Client) LoginSRP(login string, password string) (*proto.SRPLoginFinishResponse, error) {
+	session, err := srp.NewClient(login, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed start srp session: %w", err)
+	}
+
+	// Create client
+	client := proto.NewUserClient(c.Conn)
+	start, err := client.SRPLoginStart(context.Background(), &proto.SRPLoginStartRequest{
+		Login: login,
+		A:     session.Public(),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+
+	if start.Error != "" {
+		return nil, responseError(start.Error)
+	}
+
+	m1, err := session.Proof(start.Salt, start.B)
+	if err != nil {
+		return nil, fmt.Errorf("failed compute srp proof: %w", err)
+	}
+
+	resp, err := client.SRPLoginFinish(context.Background(), &proto.SRPLoginFinishRequest{
+		Session: start.Session,
+		M1:      m1,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	// Make sure the server knows the verifier
+	err = session.Verify(resp.M2)
+	if err != nil {
+		return nil, fmt.Errorf("failed verify server: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c Client) SetVerifier(login string, password string) (*proto.SetVerifierResponse, error) {
+	salt, verifier, err := srp.NewVerifier(login, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed create verifier: %w", err)
+	}
+
 	// Set authorization in gRPC metadata
-	md := metadata.Pairs("authorization", fmt.Sprintf("bearer %s", c.Token))
-	ctx := metadata.NewOutgoingContext(context.Background(), md)
+	ctx := c.authContext()
 
 	// Create client
-	client := proto.NewStorageClient(c.Conn)
-	resp, err := client.ReadAllRecord(ctx, &proto.ReadAllRecordRequest{})
+	client := proto.NewUserClient(c.Conn)
+	resp, err := client.SetVerifier(ctx, &proto.SetVerifierRequest{
+		Salt:     salt,
+		Verifier: verifier,
+	})
 
 	if err != nil {
 		return nil, fmt.Errorf(errorResponseFinished, err)
 	}
 	if resp.Error != "" {
-		return nil, fmt.Errorf(errorEesponseReturn, resp.Error)
+		return nil, responseError(resp.Error)
+	}
+
+	return resp, nil
+}
+
+func (c Client) ReadAllFile() (*proto.ReadAllRecordResponse, error) {
+	// Read all pages, the cache keeps the full list of records
+	resp := &proto.ReadAllRecordResponse{}
+	it := c.IterateFiles("", 0)
+	for it.Next() {
+		resp.Units = append(resp.Units, it.Unit())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	if it.Cached() {
+		return resp, nil
+	}
+
+	err := c.cacheUnits(resp.Units)
+	if err != nil {
+		return nil, err
 	}
 
 	return resp, nil
@@ -116,42 +270,68 @@ func (c Client) ReadAllFile() (*proto.ReadAllRecordResponse, error) {
 //nolint:dupl // This legal duplicate
 func (c Client) ReadFile(id int32) (*proto.ReadRecordResponse, error) {
 	// Set authorization in gRPC metadata
-	md := metadata.Pairs("authorization", fmt.Sprintf("bearer %s", c.Token))
-	ctx := metadata.NewOutgoingContext(context.Background(), md)
+	ctx := c.authContext()
 
 	// Create client
 	client := proto.NewStorageClient(c.Conn)
 	resp, err := client.ReadRecord(ctx, &proto.ReadRecordRequest{
-		Id: id,
+		Id:      id,
+		Grantor: c.Grantor,
 	})
 
 	if err != nil {
+		// Server is unavailable, use the cached record
+		if cached, ok := c.cachedRecord(id, err); ok {
+			return cached, nil
+		}
 		return nil, fmt.Errorf(errorResponseFinished, err)
 	}
 	if resp.Error != "" {
-		return nil, fmt.Errorf(errorEesponseReturn, resp.Error)
+		return nil, responseError(resp.Error)
+	}
+
+	err = c.cacheRecord(id, resp)
+	if err != nil {
+		return nil, err
 	}
 
 	return resp, nil
 }
 
 func (c Client) WriteFile(typ string, name string, data string) (*proto.WriteRecordResponse, error) {
+	return c.writeRecord(0, typ, name, data)
+}
+
+// UpdateFile replaces the value of an existing record, the record may be
+// owned by the user or shared with the user with the write permission.
+func (c Client) UpdateFile(id int32, typ string, name string, data string) (*proto.WriteRecordResponse, error) {
+	return c.writeRecord(id, typ, name, data)
+}
+
+func (c Client) writeRecord(id int32, typ string, name string, data string) (*proto.WriteRecordResponse, error) {
 	// Set authorization in gRPC metadata
-	md := metadata.Pairs("authorization", fmt.Sprintf("bearer %s", c.Token))
-	ctx := metadata.NewOutgoingContext(context.Background(), md)
+	ctx := c.authContext()
 
 	// Create client
 	client := proto.NewStorageClient(c.Conn)
 	stream, err := client.WriteRecord(ctx)
 	if err != nil {
+		// Server is unavailable, send the record on the next connection
+		if queued, errQueue := c.queueWrite(err, id, typ, name, data); queued || errQueue != nil {
+			return &proto.WriteRecordResponse{}, errQueue
+		}
 		return nil, fmt.Errorf(errorResponseFinished, err)
 	}
 
+	// The revision vector of the cached value detects concurrent writes
+	device, versions := c.device(), c.baseVersions(id)
+
 	var resp *proto.WriteRecordResponse
 	switch typ {
-	case "text":
-		// Send the gRPC data
-		err = stream.Send(&proto.WriteRecordRequest{Name: name, Data: []byte(data), Type: "text"})
+	default:
+		// Send the gRPC data, values of text and structured records are small
+		err = stream.Send(&proto.WriteRecordRequest{Id: id, Name: name, Data: []byte(data), Type: typ,
+			Device: device, Versions: versions})
 		if err != nil {
 			return nil, fmt.Errorf("stream send has error: %w", err)
 		}
@@ -162,7 +342,7 @@ func (c Client) WriteFile(typ string, name string, data string) (*proto.WriteRec
 			return nil, fmt.Errorf("closed stream has error: %w", err)
 		}
 		if resp.Error != "" {
-			return nil, fmt.Errorf(errorEesponseReturn, resp.Error)
+			return nil, responseError(resp.Error)
 		}
 	case "file":
 		file, err := os.Open(data)
@@ -191,7 +371,7 @@ func (c Client) WriteFile(typ string, name string, data string) (*proto.WriteRec
 					return nil, fmt.Errorf("failed CloseAndRecv: %w", err)
 				}
 				if resp.Error != "" {
-					return nil, fmt.Errorf(errorEesponseReturn, resp.Error)
+					return nil, responseError(resp.Error)
 				}
 				break
 			}
@@ -200,7 +380,8 @@ func (c Client) WriteFile(typ string, name string, data string) (*proto.WriteRec
 			}
 
 			// Send a piece of data
-			err = stream.Send(&proto.WriteRecordRequest{Name: name, Data: buf[:n], Type: "file"})
+			err = stream.Send(&proto.WriteRecordRequest{Id: id, Name: name, Data: buf[:n], Type: "file",
+				Device: device, Versions: versions})
 			if err != nil {
 				return nil, fmt.Errorf("failed send stream: %w", err)
 			}
@@ -212,14 +393,18 @@ func (c Client) WriteFile(typ string, name string, data string) (*proto.WriteRec
 		}
 	}
 
+	err = c.cacheVersions(id, resp)
+	if err != nil {
+		return nil, err
+	}
+
 	return resp, nil
 }
 
 //nolint:dupl // This legal duplicate
 func (c Client) DeleteFile(id int32) (*proto.DeleteRecordResponse, error) {
 	// Set authorization in gRPC metadata
-	md := metadata.Pairs("authorization", fmt.Sprintf("bearer %s", c.Token))
-	ctx := metadata.NewOutgoingContext(context.Background(), md)
+	ctx := c.authContext()
 
 	// Create client
 	client := proto.NewStorageClient(c.Conn)
@@ -227,11 +412,145 @@ func (c Client) DeleteFile(id int32) (*proto.DeleteRecordResponse, error) {
 		Id: id,
 	})
 
+	if err != nil {
+		// Server is unavailable, delete the record on the next connection
+		if queued, errQueue := c.queueDelete(err, id); queued || errQueue != nil {
+			return &proto.DeleteRecordResponse{}, errQueue
+		}
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	err = c.uncacheRecord(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (c Client) ShareFile(id int32, login string, permission string) (*proto.ShareRecordResponse, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewStorageClient(c.Conn)
+	resp, err := client.ShareRecord(ctx, &proto.ShareRecordRequest{
+		Id:         id,
+		Login:      login,
+		Permission: permission,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	return resp, nil
+}
+
+func (c Client) UnshareFile(id int32, login string) (*proto.RevokeShareResponse, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewStorageClient(c.Conn)
+	resp, err := client.RevokeShare(ctx, &proto.RevokeShareRequest{
+		Id:    id,
+		Login: login,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	return resp, nil
+}
+
+func (c Client) ListSharedWithMe() (*proto.ListSharedWithMeResponse, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewStorageClient(c.Conn)
+	resp, err := client.ListSharedWithMe(ctx, &proto.ListSharedWithMeRequest{})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	return resp, nil
+}
+
+func (c Client) CreateAccessToken(name string, scopes []string, recordIDs []int32,
+	namePrefix string, ttlDays int32) (*proto.CreateAccessTokenResponse, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewUserClient(c.Conn)
+	resp, err := client.CreateAccessToken(ctx, &proto.CreateAccessTokenRequest{
+		Name:       name,
+		Scopes:     scopes,
+		RecordIds:  recordIDs,
+		NamePrefix: namePrefix,
+		TtlDays:    ttlDays,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	return resp, nil
+}
+
+func (c Client) ListAccessTokens() (*proto.ListAccessTokensResponse, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewUserClient(c.Conn)
+	resp, err := client.ListAccessTokens(ctx, &proto.ListAccessTokensRequest{})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	return resp, nil
+}
+
+//nolint:dupl // This legal duplicate
+func (c Client) RevokeAccessToken(id int32) (*proto.RevokeAccessTokenResponse, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewUserClient(c.Conn)
+	resp, err := client.RevokeAccessToken(ctx, &proto.RevokeAccessTokenRequest{
+		Id: id,
+	})
+
 	if err != nil {
 		return nil, fmt.Errorf(errorResponseFinished, err)
 	}
 	if resp.Error != "" {
-		return nil, fmt.Errorf(errorEesponseReturn, resp.Error)
+		return nil, responseError(resp.Error)
 	}
 
 	return resp, nil
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dedpnd/GophKeeper/internal/record"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+)
+
+// FindRecordByName finds the own records, or the records of the team vault,
+// by the hierarchical name. With `prefix` the records whose names start with
+// the name are returned, the pages of the server are read one by one. If the
+// server is unavailable, the cached records are searched.
+func (c Client) FindRecordByName(name string, prefix bool) ([]*proto.StorageUnit, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewStorageClient(c.Conn)
+	in := &proto.FindRecordByNameRequest{
+		Name:     name,
+		Prefix:   prefix,
+		Grantor:  c.Grantor,
+		PageSize: listPageSize,
+	}
+
+	units := []*proto.StorageUnit{}
+	for {
+		resp, err := client.FindRecordByName(ctx, in)
+		if err != nil {
+			// Server is unavailable, search the cached records
+			if cached, ok := c.cachedUnits(err); ok {
+				return findCachedUnits(cached.Units, name, prefix), nil
+			}
+			return nil, fmt.Errorf(errorResponseFinished, err)
+		}
+		if resp.Error != "" {
+			return nil, responseError(resp.Error)
+		}
+
+		units = append(units, resp.Units...)
+		if resp.NextPageToken == "" {
+			return units, nil
+		}
+		in.PageToken = resp.NextPageToken
+	}
+}
+
+// findCachedUnits filters the cached records the same way the server does.
+// Records shared by other users are not searched.
+func findCachedUnits(units []*proto.StorageUnit, name string, prefix bool) []*proto.StorageUnit {
+	name = strings.TrimSpace(name)
+
+	clean, err := record.CleanName(name)
+	switch {
+	case err != nil && !(prefix && name == ""):
+		return nil
+	case prefix && strings.HasSuffix(name, record.Separator):
+		clean += record.Separator
+	}
+
+	found := []*proto.StorageUnit{}
+	for _, v := range units {
+		if v.Shared || v.Id <= 0 {
+			continue
+		}
+
+		if v.Name == clean || (prefix && strings.HasPrefix(v.Name, clean)) {
+			found = append(found, v)
+		}
+	}
+
+	return found
+}
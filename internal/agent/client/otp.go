@@ -0,0 +1,29 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+)
+
+// NextOTPCounter takes the next HOTP counter of the OTP record and returns
+// the number of codes issued before.
+func (c Client) NextOTPCounter(id int32) (int64, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewStorageClient(c.Conn)
+	resp, err := client.NextOTPCounter(ctx, &proto.NextOTPCounterRequest{
+		Id: id,
+	})
+
+	if err != nil {
+		return 0, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return 0, responseError(resp.Error)
+	}
+
+	return resp.Counter, nil
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+)
+
+// listPageSize is the size of the pages of records the agent reads.
+const listPageSize = 500
+
+// RecordIterator iterates over the records page by page, the next page is
+// read from the server when the records of the current page are over.
+// When the server is unavailable the cached records are iterated instead.
+type RecordIterator struct {
+	c      Client
+	in     *proto.ReadAllRecordRequest
+	units  []*proto.StorageUnit
+	unit   *proto.StorageUnit
+	read   bool
+	done   bool
+	cached bool
+	err    error
+}
+
+// IterateFiles returns the iterator over the records in the sort order: "id",
+// "name", "created" or "updated", prefixed with "-" for the descending order.
+// A zero page size reads pages of the default size.
+func (c Client) IterateFiles(sort string, pageSize int32) *RecordIterator {
+	if pageSize <= 0 {
+		pageSize = listPageSize
+	}
+
+	return &RecordIterator{
+		c: c,
+		in: &proto.ReadAllRecordRequest{
+			Grantor:  c.Grantor,
+			PageSize: pageSize,
+			Sort:     sort,
+		},
+	}
+}
+
+// Next moves to the next record, it returns false when the records are over
+// or the page is not read.
+func (it *RecordIterator) Next() bool {
+	// Pages may be empty if their records are hidden from the access token
+	for len(it.units) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+
+		it.err = it.readPage()
+	}
+
+	it.unit, it.units = it.units[0], it.units[1:]
+	return true
+}
+
+// Unit returns the current record.
+func (it *RecordIterator) Unit() *proto.StorageUnit {
+	return it.unit
+}
+
+// Err returns the error of reading a page.
+func (it *RecordIterator) Err() error {
+	return it.err
+}
+
+// Cached tells whether the cached records are iterated.
+func (it *RecordIterator) Cached() bool {
+	return it.cached
+}
+
+// readPage reads the next page of records.
+func (it *RecordIterator) readPage() error {
+	// Set authorization in gRPC metadata
+	ctx := it.c.authContext()
+
+	// Create client
+	client := proto.NewStorageClient(it.c.Conn)
+	resp, err := client.ReadAllRecord(ctx, it.in)
+
+	if err != nil {
+		// Server is unavailable before the first page, use the cached records
+		if cached, ok := it.c.cachedUnits(err); ok && !it.read {
+			it.units, it.done, it.cached = cached.Units, true, true
+			return nil
+		}
+		return fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return responseError(resp.Error)
+	}
+
+	it.read = true
+	it.units = resp.Units
+	it.in.PageToken = resp.NextPageToken
+	it.done = resp.NextPageToken == ""
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+)
+
+// SearchRecords returns a page of the records which match the filters of
+// the request, the next page is asked for with the token of the response.
+func (c Client) SearchRecords(in *proto.SearchRecordsRequest) (*proto.SearchRecordsResponse, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	in.Grantor = c.Grantor
+
+	// Create client
+	client := proto.NewStorageClient(c.Conn)
+	resp, err := client.SearchRecords(ctx, in)
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/cache"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+)
+
+// Sync receives the records changed since the last synchronization and
+// merges them into the local cache. Values of the changed records are read
+// from the server, the cursor is saved in the cache with the records.
+func (c Client) Sync() (*proto.SyncResponse, error) {
+	if c.Cache == nil {
+		return nil, errors.New("synchronization requires the local cache")
+	}
+
+	if c.Grantor != "" {
+		return nil, errors.New("records of emergency access cannot be synchronized")
+	}
+
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+	scope := c.cacheScope()
+
+	// Create client
+	client := proto.NewStorageClient(c.Conn)
+	resp, err := client.Sync(ctx, &proto.SyncRequest{
+		Cursor: c.Cache.Cursor(scope),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	changed := make([]cache.Unit, 0, len(resp.Units))
+	for _, v := range resp.Units {
+		// Read the value of the changed record into the cache
+		_, err := c.ReadFile(v.Id)
+		if err != nil {
+			return nil, fmt.Errorf("failed read changed file: %w", err)
+		}
+
+		changed = append(changed, cache.Unit{
+			ID:         v.Id,
+			Name:       v.Name,
+			Type:       v.Type,
+			Owner:      v.Owner,
+			OwnerLogin: v.OwnerLogin,
+			Versions:   v.Versions,
+			Metadata:   v.Metadata,
+			Conflict:   v.Conflict,
+		})
+	}
+
+	err = c.Cache.ApplySync(scope, changed, resp.Deleted, resp.Cursor)
+	if err != nil {
+		return nil, fmt.Errorf(errorUpdateCache, err)
+	}
+
+	return resp, nil
+}
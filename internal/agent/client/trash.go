@@ -0,0 +1,66 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+)
+
+// ListTrash returns the deleted records, the latest deleted first.
+func (c Client) ListTrash() ([]*proto.StorageUnit, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewStorageClient(c.Conn)
+	resp, err := client.ListTrash(ctx, &proto.ListTrashRequest{})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	return resp.Units, nil
+}
+
+// RestoreRecord moves the deleted record back from the trash.
+func (c Client) RestoreRecord(id int32) error {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewStorageClient(c.Conn)
+	resp, err := client.RestoreRecord(ctx, &proto.RestoreRecordRequest{
+		Id: id,
+	})
+
+	if err != nil {
+		return fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return responseError(resp.Error)
+	}
+
+	return nil
+}
+
+// EmptyTrash permanently removes the deleted records and returns their number.
+func (c Client) EmptyTrash() (int64, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewStorageClient(c.Conn)
+	resp, err := client.EmptyTrash(ctx, &proto.EmptyTrashRequest{})
+
+	if err != nil {
+		return 0, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return 0, responseError(resp.Error)
+	}
+
+	return resp.Count, nil
+}
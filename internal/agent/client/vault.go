@@ -0,0 +1,109 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+)
+
+func (c Client) CreateOrganization(name string) (*proto.CreateOrganizationResponse, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewVaultClient(c.Conn)
+	resp, err := client.CreateOrganization(ctx, &proto.CreateOrganizationRequest{
+		Name: name,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	return resp, nil
+}
+
+func (c Client) CreateVault(orgID int32, name string) (*proto.CreateVaultResponse, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewVaultClient(c.Conn)
+	resp, err := client.CreateVault(ctx, &proto.CreateVaultRequest{
+		OrgId: orgID,
+		Name:  name,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	return resp, nil
+}
+
+func (c Client) AddMember(orgID int32, login string, role string) (*proto.AddMemberResponse, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewVaultClient(c.Conn)
+	resp, err := client.AddMember(ctx, &proto.AddMemberRequest{
+		OrgId: orgID,
+		Login: login,
+		Role:  role,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	return resp, nil
+}
+
+func (c Client) RemoveMember(orgID int32, login string) (*proto.RemoveMemberResponse, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewVaultClient(c.Conn)
+	resp, err := client.RemoveMember(ctx, &proto.RemoveMemberRequest{
+		OrgId: orgID,
+		Login: login,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	return resp, nil
+}
+
+func (c Client) ListVaults() (*proto.ListVaultsResponse, error) {
+	// Set authorization in gRPC metadata
+	ctx := c.authContext()
+
+	// Create client
+	client := proto.NewVaultClient(c.Conn)
+	resp, err := client.ListVaults(ctx, &proto.ListVaultsRequest{})
+
+	if err != nil {
+		return nil, fmt.Errorf(errorResponseFinished, err)
+	}
+	if resp.Error != "" {
+		return nil, responseError(resp.Error)
+	}
+
+	return resp, nil
+}
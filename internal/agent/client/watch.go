@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+	"google.golang.org/grpc/metadata"
+)
+
+// Watch receives the events about changes of the records and passes them to
+// `handle` until the context is done or the server ends the stream.
+func (c Client) Watch(ctx context.Context, handle func(*proto.WatchEvent)) error {
+	if c.Grantor != "" {
+		return errors.New("records of emergency access cannot be watched")
+	}
+
+	// Set authorization in gRPC metadata
+	md, _ := metadata.FromOutgoingContext(c.authContext())
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// Create client
+	client := proto.NewStorageClient(c.Conn)
+	stream, err := client.Watch(ctx, &proto.WatchRequest{})
+	if err != nil {
+		return fmt.Errorf(errorResponseFinished, err)
+	}
+
+	for {
+		event, err := stream.Recv()
+		if errors.Is(err, io.EOF) || ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed receive event: %w", err)
+		}
+		if event.Error != "" {
+			return responseError(event.Error)
+		}
+
+		handle(event)
+	}
+}
@@ -14,12 +14,21 @@ import (
 
 var defaultPermition fs.FileMode = 0600
 
-// ConfigENV contains app settings.
+// ConfigENV contains app settings. `Args` are the subcommand and its
+// arguments given after the flags.
 type ConfigENV struct {
-	Command     string
-	JWT         string `env:"JWT"`
-	ServerAddr  string `json:"server_addr" env:"SERVER_ADDR"`
-	Certificate string `json:"certificate"`
+	Command        string
+	Args           []string
+	Vault          int
+	Grantor        string
+	Output         string
+	JWT            string `env:"JWT"`
+	ServerAddr     string `json:"server_addr" env:"SERVER_ADDR"`
+	Certificate    string `json:"certificate"`
+	LegacyAuth     bool   `json:"legacy_auth" env:"LEGACY_AUTH"`
+	CachePath      string `json:"cache_path" env:"CACHE_PATH"`
+	CachePassword  string `env:"CACHE_PASSWORD"`
+	ExportPassword string `env:"EXPORT_PASSWORD"`
 }
 
 // GetConfig get app settings.
@@ -28,7 +37,11 @@ func GetConfig() (*ConfigENV, error) {
 	configPath := "config/agent.json"
 
 	flag.StringVar(&eCfg.Command, "c", "", "command for GophKeeper storage")
+	flag.IntVar(&eCfg.Vault, "vault", 0, "team vault for file commands")
+	flag.StringVar(&eCfg.Grantor, "grantor", "", "read files of the user who granted emergency access")
+	flag.StringVar(&eCfg.Output, "output", "", "output format of subcommands: json, yaml or table")
 	flag.Parse()
+	eCfg.Args = flag.Args()
 
 	file, err := os.Open(configPath)
 	if err != nil {
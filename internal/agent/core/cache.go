@@ -0,0 +1,87 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/cache"
+	"github.com/dedpnd/GophKeeper/internal/agent/client"
+	"github.com/dedpnd/GophKeeper/internal/agent/config"
+)
+
+// UTILS FOR LOCAL CACHE.
+
+// cacheCommands lists the commands which work with the local cache.
+var cacheCommands = map[string]bool{
+	"read-file":   true,
+	"write-file":  true,
+	"update-file": true,
+	"delete-file": true,
+	"sync":        true,
+	"tui":         true,
+}
+
+// openCache opens the local cache if it is configured and replays the
+// changes made while the server was unavailable. The password is taken
+// from the config or asked for with `ask`, messages are written to `w`.
+func openCache(client *client.Client, cfg *config.ConfigENV, ask func(string) (string, error), w io.Writer) error {
+	if cfg.CachePath == "" {
+		return nil
+	}
+
+	password := cfg.CachePassword
+	if password == "" {
+		r, err := ask("Enter your password to unlock the cache: ")
+		if err != nil {
+			return err
+		}
+
+		password = r
+	}
+
+	c, err := cache.Open(cfg.CachePath, password)
+	if err != nil {
+		return fmt.Errorf("failed open cache: %w", err)
+	}
+
+	client.Cache = c
+
+	queued := len(c.Queue())
+	if queued == 0 {
+		return nil
+	}
+
+	sent, err := client.Replay()
+	if sent != 0 {
+		fmt.Fprintf(w, "Sent %v of %v changes made offline \n", sent, queued)
+	}
+	if err != nil {
+		fmt.Fprintf(w, "Changes rejected by the server: %s \n", err.Error())
+		fmt.Fprintln(w, "Run \"rejected\" to review them, \"rejected -retry\" to send them again.")
+	}
+
+	return nil
+}
+
+// printOffline warns that the server was unavailable and the cache was used.
+func printOffline(w io.Writer, client *client.Client) {
+	if client.Cache == nil || !client.Cache.Offline() {
+		return
+	}
+
+	fmt.Fprintln(w, "Server is unavailable: files are shown from the local cache, "+
+		"changes will be sent on the next connection.")
+}
+
+// askCachePassword asks for the password of the cache in the interactive
+// mode, without echo on a terminal. Piped input is read as one line.
+func askCachePassword(prompt string) (string, error) {
+	s := StdStreams()
+	if !s.TTY {
+		return readLine(bufio.NewReader(os.Stdin), prompt)
+	}
+
+	return s.askPassword(prompt)
+}
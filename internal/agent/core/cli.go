@@ -0,0 +1,281 @@
+package core
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/client"
+	"github.com/dedpnd/GophKeeper/internal/agent/config"
+	"github.com/dedpnd/GophKeeper/internal/record"
+	"golang.org/x/term"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Exit codes of the subcommands, scripts tell the classes of errors apart by them.
+const (
+	ExitOK          = 0
+	ExitError       = 1
+	ExitUsage       = 2
+	ExitAuth        = 3
+	ExitNotFound    = 4
+	ExitUnavailable = 5
+)
+
+// Classes of errors of the subcommands.
+var (
+	ErrUsage    = errors.New("usage error")
+	ErrAuth     = errors.New("authentication failed")
+	ErrNotFound = errors.New("not found")
+)
+
+// Streams are the standard streams of a subcommand. Results are written to
+// `Out`, prompts and messages to `Err`. Values missing from the arguments are
+// asked for only if `TTY` is set, otherwise they are usage errors. `Output`
+// is the format of the results.
+type Streams struct {
+	In     io.Reader
+	Out    io.Writer
+	Err    io.Writer
+	Output string
+	TTY    bool
+	reader *bufio.Reader
+}
+
+// StdStreams returns the standard streams of the process.
+func StdStreams() *Streams {
+	return &Streams{
+		In:  os.Stdin,
+		Out: os.Stdout,
+		Err: os.Stderr,
+		TTY: isTerminal(os.Stdin),
+	}
+}
+
+// subcommand runs a subcommand with its arguments.
+type subcommand func(client *client.Client, cfg *config.ConfigENV, args []string, s *Streams) error
+
+// subcommands maps the names of the non-interactive subcommands to their
+// implementations.
+var subcommands = map[string]subcommand{
+	"login":          runLogin,
+	"ls":             runList,
+	"get":            runGet,
+	"put":            runPut,
+	"rm":             runRemove,
+	"search":         runSearch,
+	"folders":        runFolders,
+	"mkdir":          runMkdir,
+	"rmdir":          runRmdir,
+	"mvdir":          runRenameFolder,
+	"mv":             runMove,
+	"tag":            runTag,
+	"untag":          runUntag,
+	"trash":          runTrash,
+	"restore":        runRestore,
+	"empty-trash":    runEmptyTrash,
+	"expire":         runExpire,
+	"expiring":       runExpiring,
+	"otp":            runOTP,
+	"ssh-agent":      runSSHAgent,
+	"git-credential": runGitCredential,
+	"run":            runProcess,
+	"inject":         runInject,
+	"import":         runImport,
+	"export":         runExport,
+	"rejected":       runRejected,
+}
+
+// IsSubcommand reports whether the name is a non-interactive subcommand.
+func IsSubcommand(name string) bool {
+	_, ok := subcommands[name]
+	return ok
+}
+
+// Exec runs the subcommand `args[0]` with the rest of the arguments. The local
+// cache is opened if its password is configured or can be asked for.
+func Exec(client *client.Client, cfg *config.ConfigENV, args []string, s *Streams) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: subcommand is required", ErrUsage)
+	}
+
+	run, ok := subcommands[args[0]]
+	if !ok {
+		return fmt.Errorf("%w: unknown subcommand %q", ErrUsage, args[0])
+	}
+
+	if s.Output == "" {
+		s.Output = cfg.Output
+	}
+
+	err := checkOutput(s.Output)
+	if err != nil {
+		return err
+	}
+
+	if args[0] != "login" && (cfg.CachePassword != "" || s.TTY) {
+		err = openCache(client, cfg, s.askPassword, s.Err)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = run(client, cfg, args[1:], s)
+	printOffline(s.Err, client)
+
+	return err
+}
+
+// ExitCode returns the exit code of the process for the error of a subcommand.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	// The agent exits with the status of the child process
+	var exitStatus *exitStatusError
+	if errors.As(err, &exitStatus) {
+		return exitStatus.Code
+	}
+
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return ExitUnavailable
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return ExitAuth
+	case codes.NotFound:
+		return ExitNotFound
+	}
+
+	switch {
+	case errors.Is(err, ErrUsage), errors.Is(err, record.ErrInvalid):
+		return ExitUsage
+	case errors.Is(err, ErrAuth):
+		return ExitAuth
+	case errors.Is(err, ErrNotFound), errors.Is(err, client.ErrNotFound):
+		return ExitNotFound
+	}
+
+	// The server returns errors of the calls in responses as text
+	msg := err.Error()
+	if strings.Contains(msg, errorInvalidToken) || strings.Contains(msg, errorAccessDenied) {
+		return ExitAuth
+	}
+
+	return ExitError
+}
+
+var (
+	errorInvalidToken = "invalid token"
+	errorAccessDenied = "access denied"
+)
+
+// UTILS FOR SUBCOMMANDS.
+
+// isUnavailable reports whether the call failed because the server is unavailable.
+func isUnavailable(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+// ask prints the prompt and reads a line if a terminal is attached.
+func (s *Streams) ask(prompt string) (string, error) {
+	if !s.TTY {
+		return "", fmt.Errorf("%w: missing value for %q and no terminal to ask",
+			ErrUsage, strings.TrimSuffix(strings.TrimSpace(prompt), ":"))
+	}
+
+	fmt.Fprint(s.Err, prompt)
+
+	return s.readLine()
+}
+
+// askPassword asks for a password on the terminal without echo. The password
+// is kept as typed, spaces are part of it.
+func (s *Streams) askPassword(prompt string) (string, error) {
+	f, ok := s.In.(*os.File)
+	if !s.TTY || !ok {
+		return "", fmt.Errorf("%w: missing value for %q and no terminal to ask",
+			ErrUsage, strings.TrimSuffix(strings.TrimSpace(prompt), ":"))
+	}
+
+	fmt.Fprint(s.Err, prompt)
+	defer fmt.Fprintln(s.Err)
+
+	r, err := term.ReadPassword(int(f.Fd()))
+	if err != nil {
+		return "", fmt.Errorf(errorFailedReadSTDIN, err)
+	}
+
+	return string(r), nil
+}
+
+// readLine reads one trimmed line from the input.
+func (s *Streams) readLine() (string, error) {
+	if s.reader == nil {
+		s.reader = bufio.NewReader(s.In)
+	}
+
+	r, err := s.reader.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && r != "") {
+		return "", fmt.Errorf(errorFailedReadSTDIN, err)
+	}
+
+	return strings.TrimSpace(r), nil
+}
+
+// readAll reads the rest of the input.
+func (s *Streams) readAll() ([]byte, error) {
+	if s.reader == nil {
+		s.reader = bufio.NewReader(s.In)
+	}
+
+	data, err := io.ReadAll(s.reader)
+	if err != nil {
+		return nil, fmt.Errorf(errorFailedReadSTDIN, err)
+	}
+
+	return data, nil
+}
+
+// newFlagSet creates the flag set of the subcommand, its errors are written
+// to the error stream.
+func newFlagSet(name string, s *Streams) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(s.Err)
+
+	return fs
+}
+
+// parseArgs parses the flags of the subcommand and returns the positional
+// arguments. Flags may follow the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		err := fs.Parse(args)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrUsage, err)
+		}
+
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// isTerminal reports whether the file is a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return fi.Mode()&os.ModeCharDevice != 0
+}
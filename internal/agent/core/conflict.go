@@ -0,0 +1,139 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/client"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+)
+
+// columnWidth is the width of a version column in the side by side view.
+var columnWidth = 40
+
+// UTILS FOR CONFLICTS.
+
+// resolveConflict shows the versions of a record with conflicts side by side
+// and keeps the version picked by the user.
+func resolveConflict(client *client.Client) error {
+	rAllFile, err := client.ReadAllFile()
+	if err != nil {
+		return fmt.Errorf("failed get all file: %w", err)
+	}
+
+	units := make([]*proto.StorageUnit, 0, len(rAllFile.Units))
+	for _, v := range rAllFile.Units {
+		if v.Conflict {
+			units = append(units, v)
+		}
+	}
+
+	if len(units) == 0 {
+		fmt.Println("Not found files with conflicts.")
+		return nil
+	}
+
+	printUnits(units)
+
+	i, err := selectReadFile(client)
+	if err != nil {
+		return fmt.Errorf("wrong id file: %w", err)
+	}
+
+	r, err := client.ListConflicts(int32(i))
+	if err != nil {
+		return fmt.Errorf("failed get conflicts: %w", err)
+	}
+
+	for _, v := range r.Conflicts {
+		printVersions(r.Current, v)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+
+	keep, err := readLine(reader, "Enter ID of the version to keep [0 - current]: ")
+	if err != nil {
+		return err
+	}
+
+	k, err := strconv.Atoi(keep)
+	if err != nil {
+		return fmt.Errorf("failed parse int: %w", err)
+	}
+
+	merge, err := readLine(reader, "Merge metadata of all versions? [y/n]: ")
+	if err != nil {
+		return err
+	}
+
+	_, err = client.ResolveConflict(int32(i), int32(k), strings.EqualFold(merge, "y"))
+	if err != nil {
+		return fmt.Errorf("failed resolve conflict: %w", err)
+	}
+
+	fmt.Println("Conflict resolved!")
+
+	return nil
+}
+
+// printVersions shows the current version of the record and a conflicting
+// one side by side.
+func printVersions(current *proto.RecordVersion, conflict *proto.RecordVersion) {
+	left, right := versionLines(current), versionLines(conflict)
+	left[0] = "[0] current"
+	right[0] = fmt.Sprintf("[%v] conflict", conflict.Id)
+
+	fmt.Println(strings.Repeat("-", columnWidth*2+3))
+	for i := 0; i < max(len(left), len(right)); i++ {
+		var l, r string
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+
+		fmt.Printf("%-*s | %-*s\n", columnWidth, truncate(l), columnWidth, truncate(r))
+	}
+}
+
+// versionLines returns the lines describing the version, the first line
+// is left for the title.
+func versionLines(v *proto.RecordVersion) []string {
+	lines := []string{
+		"",
+		"device: " + v.Device,
+		"name: " + v.Name,
+		"changed: " + time.Unix(v.CreatedAt, 0).Format(time.DateTime),
+	}
+
+	keys := make([]string, 0, len(v.Metadata))
+	for k := range v.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		lines = append(lines, fmt.Sprintf("%s: %s", k, v.Metadata[k]))
+	}
+
+	if v.Type == "file" {
+		return append(lines, fmt.Sprintf("<file, %v bytes>", len(v.Data)))
+	}
+
+	return append(lines, strings.Split(string(v.Data), "\n")...)
+}
+
+// truncate cuts the line to the width of a column.
+func truncate(s string) string {
+	if len([]rune(s)) <= columnWidth {
+		return s
+	}
+
+	return string([]rune(s)[:columnWidth-1]) + "~"
+}
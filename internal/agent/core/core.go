@@ -10,12 +10,25 @@ import (
 	"strings"
 
 	"github.com/dedpnd/GophKeeper/internal/agent/client"
+	"github.com/dedpnd/GophKeeper/internal/agent/config"
+	"github.com/dedpnd/GophKeeper/internal/agent/tui"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
 )
 
 var defaultPermition fs.FileMode = 0600
 var errorFailedReadSTDIN = "failed read stdin: %w"
 
-func Run(client *client.Client, command string) error {
+func Run(client *client.Client, cfg *config.ConfigENV) error {
+	command := cfg.Command
+
+	// Commands working with files use the local cache
+	if cacheCommands[command] {
+		err := openCache(client, cfg, askCachePassword, os.Stdout)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Depending on the command, we choose the logic of behavior
 	switch command {
 	case "sign-up":
@@ -27,15 +40,15 @@ func Run(client *client.Client, command string) error {
 			return fmt.Errorf("failed get user credentials: %w", err)
 		}
 
-		r, err := client.Register(ss.login, ss.password)
+		jwt, err := register(client, cfg.LegacyAuth, ss)
 		if err != nil {
 			return fmt.Errorf("failed register user: %w", err)
 		}
 
-		fmt.Printf("Token: %s \n", r.Jwt)
+		fmt.Printf("Token: %s \n", jwt)
 
 		// Do you want to save the token?
-		err = saveAuthToken(r.Jwt)
+		err = saveAuthToken(jwt)
 		if err != nil {
 			return fmt.Errorf("client failed save token: %w", err)
 		}
@@ -47,13 +60,18 @@ func Run(client *client.Client, command string) error {
 			return fmt.Errorf("failed get user credentials: %w", err)
 		}
 
-		r, err := client.Login(ss.login, ss.password)
+		jwt, err := login(client, cfg.LegacyAuth, ss)
 		if err != nil {
 			return fmt.Errorf("failed login user: %w", err)
 		}
 
-		fmt.Printf("Token: %s \n", r.Jwt)
-		err = saveAuthToken(r.Jwt)
+		fmt.Printf("Token: %s \n", jwt)
+
+		// Warn about the files to rotate
+		client.Token = jwt
+		warnExpiring(client, os.Stdout)
+
+		err = saveAuthToken(jwt)
 		if err != nil {
 			return fmt.Errorf("client failed save token: %w", err)
 		}
@@ -73,16 +91,10 @@ func Run(client *client.Client, command string) error {
 		}
 
 		// Showing the available files
-		fmt.Println("Available files:")
-		for _, v := range rAllFile.Units {
-			// TODO: Откуда 0 ? Size slice ?
-			if v.Id > 0 {
-				fmt.Printf("[%v] - %s \n", v.Id, v.Name)
-			}
-		}
+		printUnits(rAllFile.Units)
 
 		// Selecting a file to download
-		i, err := selectReadFile()
+		i, err := selectReadFile(client)
 		if err != nil {
 			return fmt.Errorf("wrong id file: %w", err)
 		}
@@ -107,10 +119,17 @@ func Run(client *client.Client, command string) error {
 		fmt.Println("-> Write file")
 
 		// Selecting the file type and the file we want to save
-		err := selectWriteData(client)
+		err := selectWriteData(client, 0)
 		if err != nil {
 			return fmt.Errorf("select write data has error: %w", err)
 		}
+	case "update-file":
+		fmt.Println("-> Update file")
+
+		err := updateFile(client)
+		if err != nil {
+			return fmt.Errorf("update file has error: %w", err)
+		}
 	case "delete-file":
 		fmt.Println("-> Delete file")
 
@@ -127,16 +146,10 @@ func Run(client *client.Client, command string) error {
 		}
 
 		// Showing the available files
-		fmt.Println("Available files:")
-		for _, v := range rAllFile.Units {
-			// TODO: Откуда 0 ? Size slice ?
-			if v.Id > 0 {
-				fmt.Printf("[%v] - %s \n", v.Id, v.Name)
-			}
-		}
+		printUnits(rAllFile.Units)
 
 		// Select a file to delete
-		i, err := selectReadFile()
+		i, err := selectReadFile(client)
 		if err != nil {
 			return fmt.Errorf("wrong id file: %w", err)
 		}
@@ -148,10 +161,154 @@ func Run(client *client.Client, command string) error {
 		}
 
 		fmt.Println("File delete!")
+	case "sync":
+		fmt.Println("-> Sync files")
+
+		r, err := client.Sync()
+		if err != nil {
+			return fmt.Errorf("failed sync files: %w", err)
+		}
+
+		fmt.Printf("Changed files: %v, deleted files: %v \n", len(r.Units), len(r.Deleted))
+	case "watch":
+		fmt.Println("-> Watch files, press Ctrl+C to stop")
+
+		err := watchFiles(client)
+		if err != nil {
+			return fmt.Errorf("watch files has error: %w", err)
+		}
+	case "tui":
+		fmt.Println("-> Open terminal interface")
+
+		err := tui.Run(client)
+		if err != nil {
+			return fmt.Errorf("terminal interface has error: %w", err)
+		}
+	case "resolve":
+		fmt.Println("-> Resolve conflict")
+
+		err := resolveConflict(client)
+		if err != nil {
+			return fmt.Errorf("resolve conflict has error: %w", err)
+		}
+	case "share-file":
+		fmt.Println("-> Share file")
+
+		err := shareFile(client)
+		if err != nil {
+			return fmt.Errorf("share file has error: %w", err)
+		}
+	case "unshare-file":
+		fmt.Println("-> Unshare file")
+
+		err := unshareFile(client)
+		if err != nil {
+			return fmt.Errorf("unshare file has error: %w", err)
+		}
+	case "shared-files":
+		fmt.Println("-> Files shared with you")
+
+		err := listSharedFiles(client)
+		if err != nil {
+			return fmt.Errorf("list shared files has error: %w", err)
+		}
+	case "create-org":
+		fmt.Println("-> Create organization")
+
+		err := createOrganization(client)
+		if err != nil {
+			return fmt.Errorf("create organization has error: %w", err)
+		}
+	case "create-vault":
+		fmt.Println("-> Create vault")
+
+		err := createVault(client)
+		if err != nil {
+			return fmt.Errorf("create vault has error: %w", err)
+		}
+	case "invite-member":
+		fmt.Println("-> Invite member")
+
+		err := inviteMember(client)
+		if err != nil {
+			return fmt.Errorf("invite member has error: %w", err)
+		}
+	case "remove-member":
+		fmt.Println("-> Remove member")
+
+		err := removeMember(client)
+		if err != nil {
+			return fmt.Errorf("remove member has error: %w", err)
+		}
+	case "list-vaults":
+		fmt.Println("-> List vaults")
+
+		err := listVaults(client)
+		if err != nil {
+			return fmt.Errorf("list vaults has error: %w", err)
+		}
+	case "add-emergency":
+		fmt.Println("-> Add emergency contact")
+
+		err := addEmergencyContact(client)
+		if err != nil {
+			return fmt.Errorf("add emergency contact has error: %w", err)
+		}
+	case "remove-emergency":
+		fmt.Println("-> Remove emergency contact")
+
+		err := removeEmergencyContact(client)
+		if err != nil {
+			return fmt.Errorf("remove emergency contact has error: %w", err)
+		}
+	case "list-emergency":
+		fmt.Println("-> List emergency contacts")
+
+		err := listEmergencyContacts(client)
+		if err != nil {
+			return fmt.Errorf("list emergency contacts has error: %w", err)
+		}
+	case "request-emergency":
+		fmt.Println("-> Request emergency access")
+
+		err := requestEmergencyAccess(client)
+		if err != nil {
+			return fmt.Errorf("request emergency access has error: %w", err)
+		}
+	case "reject-emergency":
+		fmt.Println("-> Reject emergency access")
+
+		err := rejectEmergencyAccess(client)
+		if err != nil {
+			return fmt.Errorf("reject emergency access has error: %w", err)
+		}
+	case "create-token":
+		fmt.Println("-> Create access token")
+
+		err := createAccessToken(client)
+		if err != nil {
+			return fmt.Errorf("create access token has error: %w", err)
+		}
+	case "list-tokens":
+		fmt.Println("-> List access tokens")
+
+		err := listAccessTokens(client)
+		if err != nil {
+			return fmt.Errorf("list access tokens has error: %w", err)
+		}
+	case "revoke-token":
+		fmt.Println("-> Revoke access token")
+
+		err := revokeAccessToken(client)
+		if err != nil {
+			return fmt.Errorf("revoke access token has error: %w", err)
+		}
 	default:
 		fmt.Printf("Command:%s not found! \n", command)
 	}
 
+	printOffline(os.Stdout, client)
+
 	fmt.Println("Bye!")
 	return nil
 }
@@ -186,8 +343,9 @@ func saveFileInDisk(fileName string, data []byte) error {
 	return nil
 }
 
-// selectWriteData selecting a file to download.
-func selectWriteData(client *client.Client) error {
+// selectWriteData selecting a file to download. A non-zero id replaces
+// the value of the existing record.
+func selectWriteData(client *client.Client, id int32) error {
 	fmt.Println("What you want send on server?")
 	fmt.Println("[1] - Text")
 	fmt.Println("[2] - File")
@@ -258,7 +416,7 @@ func selectWriteData(client *client.Client) error {
 		data = strings.TrimSpace(data)
 
 		// Send the gRPC data
-		_, err = client.WriteFile("text", fileName, data)
+		_, err = writeOrUpdate(client, id, "text", fileName, data)
 		if err != nil {
 			return fmt.Errorf("write file has error: %w", err)
 		}
@@ -279,7 +437,7 @@ func selectWriteData(client *client.Client) error {
 		baseName := filepath.Base(filePath)
 
 		// Send the gRPC data
-		_, err = client.WriteFile("file", baseName, filePath)
+		_, err = writeOrUpdate(client, id, "file", baseName, filePath)
 		if err != nil {
 			return fmt.Errorf("write file has error: %w", err)
 		}
@@ -290,11 +448,58 @@ func selectWriteData(client *client.Client) error {
 	return nil
 }
 
+// writeOrUpdate writes a new record or replaces the existing one. The user
+// is warned if the record was changed concurrently and the value is kept
+// as a conflicting version.
+func writeOrUpdate(client *client.Client, id int32, typ string, name string, data string) (*proto.WriteRecordResponse, error) {
+	if id != 0 {
+		resp, err := client.UpdateFile(id, typ, name, data)
+		if err != nil {
+			//nolint:wrapcheck // This legal return
+			return nil, err
+		}
+
+		if resp.Conflict {
+			fmt.Println("The file was changed on another device, your version is kept as a conflict.")
+			fmt.Println("Use the resolve command to pick the version to keep.")
+		}
+
+		return resp, nil
+	}
+
+	//nolint:wrapcheck // This legal return
+	return client.WriteFile(typ, name, data)
+}
+
 // UTILS FOR READ FILE.
 
-// selectReadFile select a file to read.
-func selectReadFile() (int, error) {
-	fmt.Print("Select ID file: ")
+// printUnits shows the list of records, shared records are marked with their
+// owner and records with conflicting versions are marked too.
+func printUnits(units []*proto.StorageUnit) {
+	fmt.Println("Available files:")
+	for _, v := range units {
+		// TODO: Откуда 0 ? Size slice ?
+		if v.Id <= 0 {
+			continue
+		}
+
+		conflict := ""
+		if v.Conflict {
+			conflict = " (conflict)"
+		}
+
+		if v.Shared {
+			fmt.Printf("[%v] - %s (shared by %s, %s)%s \n", v.Id, v.Name, v.OwnerLogin, v.Permission, conflict)
+			continue
+		}
+
+		fmt.Printf("[%v] - %s%s \n", v.Id, v.Name, conflict)
+	}
+}
+
+// selectReadFile select a file to read by ID or by name.
+func selectReadFile(client *client.Client) (int, error) {
+	fmt.Print("Select ID or name of file: ")
 
 	// Create a reader for input from standard input (console)
 	reader := bufio.NewReader(os.Stdin)
@@ -308,10 +513,15 @@ func selectReadFile() (int, error) {
 	// Trim the spaces and newline characters from the response
 	response = strings.TrimSpace(response)
 
-	// Converting the answer to a digit
+	// Converting the answer to a digit, otherwise it is a name
 	i, err := strconv.Atoi(response)
 	if err != nil {
-		return 0, fmt.Errorf("failed parse int: %w", err)
+		unit, err := findUnitByName(client, response)
+		if err != nil {
+			return 0, err
+		}
+
+		return int(unit.Id), nil
 	}
 
 	return i, nil
@@ -319,6 +529,55 @@ func selectReadFile() (int, error) {
 
 // UTILS FOR REGISTER AND LOGIN.
 
+// register creates a new account with SRP, or with the password flow if
+// the legacy mode is enabled, and returns the JWT token.
+func register(client *client.Client, legacy bool, ss userCredentials) (string, error) {
+	if legacy {
+		r, err := client.Register(ss.login, ss.password)
+		if err != nil {
+			return "", fmt.Errorf("legacy register has error: %w", err)
+		}
+
+		return r.Jwt, nil
+	}
+
+	r, err := client.RegisterSRP(ss.login, ss.password)
+	if err != nil {
+		return "", fmt.Errorf("srp register has error: %w", err)
+	}
+
+	return r.Jwt, nil
+}
+
+// login signs in with SRP and returns the JWT token. In the legacy mode the
+// password flow is used and the account is migrated to SRP afterwards, so
+// the next sign in works without the legacy mode.
+func login(client *client.Client, legacy bool, ss userCredentials) (string, error) {
+	if !legacy {
+		r, err := client.LoginSRP(ss.login, ss.password)
+		if err != nil {
+			return "", fmt.Errorf("srp login has error: %w", err)
+		}
+
+		return r.Jwt, nil
+	}
+
+	r, err := client.Login(ss.login, ss.password)
+	if err != nil {
+		return "", fmt.Errorf("legacy login has error: %w", err)
+	}
+
+	client.Token = r.Jwt
+	_, err = client.SetVerifier(ss.login, ss.password)
+	if err != nil {
+		fmt.Printf("Failed migrate account to SRP: %s \n", err.Error())
+	} else {
+		fmt.Println("Account migrated to SRP, disable legacy_auth in the agent config.")
+	}
+
+	return r.Jwt, nil
+}
+
 // saveAuthToken saving the token to the .env file.
 func saveAuthToken(token string) error {
 	fmt.Print("Do you want save token in .env? [y/N]: ")
@@ -337,29 +596,51 @@ func saveAuthToken(token string) error {
 
 	// Check the user's response
 	if strings.ToLower(response) == "y" {
-		// Open the file .env in append or create mode, if it doesn't exist yet
-		file, err := os.OpenFile(".env", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, defaultPermition)
+		err = writeAuthToken(token)
 		if err != nil {
-			return fmt.Errorf("failed to open .env file: %w", err)
+			return err
 		}
 
-		// Write the string with the token in the format "JWT=your_token" to the file
-		_, err = file.WriteString(fmt.Sprintf("JWT=%s\n", token))
-		if err != nil {
-			return fmt.Errorf("failed to write token to .env file: %w", err)
-		}
+		fmt.Println("Token saved in .env file.")
+	}
 
-		err = file.Close()
-		if err != nil {
-			return fmt.Errorf("failed close file: %w", err)
-		}
+	return nil
+}
 
-		fmt.Println("Token saved in .env file.")
+// writeAuthToken replaces the .env file with the token.
+func writeAuthToken(token string) error {
+	// Open the file .env in append or create mode, if it doesn't exist yet
+	file, err := os.OpenFile(".env", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, defaultPermition)
+	if err != nil {
+		return fmt.Errorf("failed to open .env file: %w", err)
+	}
+
+	// Write the string with the token in the format "JWT=your_token" to the file
+	_, err = file.WriteString(fmt.Sprintf("JWT=%s\n", token))
+	if err != nil {
+		return fmt.Errorf("failed to write token to .env file: %w", err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("failed close file: %w", err)
 	}
 
 	return nil
 }
 
+// readLine prints the prompt and reads one trimmed line from stdin.
+func readLine(reader *bufio.Reader, prompt string) (string, error) {
+	fmt.Print(prompt)
+
+	r, err := reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf(errorFailedReadSTDIN, err)
+	}
+
+	return strings.TrimSpace(r), nil
+}
+
 type userCredentials struct {
 	login    string
 	password string
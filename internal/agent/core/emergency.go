@@ -0,0 +1,136 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/client"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+)
+
+// UTILS FOR EMERGENCY ACCESS.
+
+// addEmergencyContact nominates another user as an emergency contact.
+func addEmergencyContact(client *client.Client) error {
+	reader := bufio.NewReader(os.Stdin)
+
+	login, err := readLine(reader, "Enter login of the user: ")
+	if err != nil {
+		return err
+	}
+
+	r, err := readLine(reader, "Enter waiting period in hours: ")
+	if err != nil {
+		return err
+	}
+
+	hours, err := strconv.Atoi(r)
+	if err != nil {
+		return fmt.Errorf("failed parse int: %w", err)
+	}
+
+	_, err = client.AddEmergencyContact(login, int32(hours))
+	if err != nil {
+		return fmt.Errorf("failed add emergency contact: %w", err)
+	}
+
+	fmt.Printf("%s is your emergency contact now! \n", login)
+
+	return nil
+}
+
+// removeEmergencyContact removes an emergency contact of the user.
+func removeEmergencyContact(client *client.Client) error {
+	reader := bufio.NewReader(os.Stdin)
+
+	login, err := readLine(reader, "Enter login of the user: ")
+	if err != nil {
+		return err
+	}
+
+	_, err = client.RemoveEmergencyContact(login)
+	if err != nil {
+		return fmt.Errorf("failed remove emergency contact: %w", err)
+	}
+
+	fmt.Printf("%s removed! \n", login)
+
+	return nil
+}
+
+// listEmergencyContacts shows the emergency contacts of the user and the
+// users who nominated the user.
+func listEmergencyContacts(client *client.Client) error {
+	r, err := client.ListEmergencyContacts()
+	if err != nil {
+		return fmt.Errorf("failed get emergency contacts: %w", err)
+	}
+
+	fmt.Println("Your emergency contacts:")
+	printEmergencyContacts(r.Contacts, func(v *proto.EmergencyContactUnit) string { return v.GranteeLogin })
+
+	fmt.Println("You are emergency contact of:")
+	printEmergencyContacts(r.Grantors, func(v *proto.EmergencyContactUnit) string { return v.GrantorLogin })
+
+	return nil
+}
+
+// printEmergencyContacts shows the emergency contacts with the login
+// of the other user.
+func printEmergencyContacts(contacts []*proto.EmergencyContactUnit, login func(*proto.EmergencyContactUnit) string) {
+	if len(contacts) == 0 {
+		fmt.Println("  -")
+		return
+	}
+
+	for _, v := range contacts {
+		fmt.Printf("  %s - %s, wait %vh", login(v), v.Status, v.WaitHours)
+		if v.GrantedAt != 0 {
+			fmt.Printf(", access from %s", time.Unix(v.GrantedAt, 0).Format(time.DateTime))
+		}
+		fmt.Println()
+	}
+}
+
+// requestEmergencyAccess requests access to the records of the user who
+// nominated the user as an emergency contact.
+func requestEmergencyAccess(client *client.Client) error {
+	reader := bufio.NewReader(os.Stdin)
+
+	login, err := readLine(reader, "Enter login of the user: ")
+	if err != nil {
+		return err
+	}
+
+	r, err := client.RequestEmergencyAccess(login)
+	if err != nil {
+		return fmt.Errorf("failed request emergency access: %w", err)
+	}
+
+	fmt.Printf("Access will be granted at %s, then use -grantor %s \n",
+		time.Unix(r.GrantedAt, 0).Format(time.DateTime), login)
+
+	return nil
+}
+
+// rejectEmergencyAccess rejects the access request of an emergency contact.
+func rejectEmergencyAccess(client *client.Client) error {
+	reader := bufio.NewReader(os.Stdin)
+
+	login, err := readLine(reader, "Enter login of the user: ")
+	if err != nil {
+		return err
+	}
+
+	_, err = client.RejectEmergencyAccess(login)
+	if err != nil {
+		return fmt.Errorf("failed reject emergency access: %w", err)
+	}
+
+	fmt.Printf("Emergency access of %s rejected! \n", login)
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/client"
+	"github.com/dedpnd/GophKeeper/internal/agent/config"
+)
+
+// expiryOK is the expiry status of records which are not due soon.
+const expiryOK = "ok"
+
+// UTILS FOR EXPIRY.
+
+// runExpire sets the expiry date and the rotation interval of the record
+// found by ID or name. `-clear` removes both.
+func runExpire(client *client.Client, _ *config.ConfigENV, args []string, s *Streams) error {
+	var expiresAt int64
+
+	fs := newFlagSet("expire", s)
+	fs.Func("at", "expiry date, RFC 3339 or YYYY-MM-DD", unixFlag(&expiresAt))
+	rotate := fs.Int("rotate", 0, "rotation interval in days")
+	clear := fs.Bool("clear", false, "remove the expiry date and the rotation interval")
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return fmt.Errorf("%w: usage: expire <id|name> [-at date] [-rotate days] [-clear]", ErrUsage)
+	}
+
+	if *rotate < 0 {
+		return fmt.Errorf("%w: rotation interval must be positive", ErrUsage)
+	}
+
+	if *clear == (expiresAt != 0 || *rotate != 0) {
+		return fmt.Errorf("%w: use -at and -rotate, or -clear", ErrUsage)
+	}
+
+	unit, err := findUnit(client, pos[0])
+	if err != nil {
+		return err
+	}
+
+	err = client.SetRecordExpiry(unit.Id, expiresAt, int32(*rotate))
+	if err != nil {
+		return fmt.Errorf("failed set file expiry: %w", err)
+	}
+
+	return s.render(map[string]any{
+		"id": unit.Id, "name": unit.Name, "expires_at": formatTime(expiresAt), "rotate_days": *rotate,
+	}, discard)
+}
+
+// runExpiring prints the records which expire or are due for rotation
+// within the number of days, expired records included.
+func runExpiring(client *client.Client, _ *config.ConfigENV, args []string, s *Streams) error {
+	fs := newFlagSet("expiring", s)
+	days := fs.Int("days", 0, "warning period in days, zero is the default of the server")
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 {
+		return fmt.Errorf("%w: usage: expiring [-days n]", ErrUsage)
+	}
+
+	if *days < 0 {
+		return fmt.Errorf("%w: number of days must be positive", ErrUsage)
+	}
+
+	found, err := client.ListExpiringRecords(int32(*days))
+	if err != nil {
+		return fmt.Errorf("failed get expiring files: %w", err)
+	}
+
+	units := make([]unitView, 0, len(found))
+	for _, v := range found {
+		units = append(units, newUnitView(v))
+	}
+
+	return s.render(units, func(w io.Writer) error {
+		rows := make([][]string, 0, len(units))
+		for _, v := range units {
+			rows = append(rows, []string{fmt.Sprint(v.ID), v.Name, v.Status, v.DueAt})
+		}
+
+		if s.Output == OutputTable {
+			return writeTable(w, []string{"ID", "NAME", "STATUS", "DUE"}, rows)
+		}
+
+		for _, v := range rows {
+			fmt.Fprintln(w, strings.Join(v, "\t"))
+		}
+		return nil
+	})
+}
+
+// warnExpiring writes a warning about the records which are expired or
+// expire soon. The warning is a hint, failures are not reported.
+func warnExpiring(client *client.Client, w io.Writer) {
+	found, err := client.ListExpiringRecords(0)
+	if err != nil || len(found) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "Warning: %v files must be rotated:\n", len(found))
+	for _, v := range found {
+		fmt.Fprintf(w, "  %s - %s %s\n", v.Name, v.ExpiryStatus, formatTime(v.DueAt))
+	}
+}
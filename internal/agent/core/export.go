@@ -0,0 +1,184 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/archive"
+	"github.com/dedpnd/GophKeeper/internal/agent/client"
+	"github.com/dedpnd/GophKeeper/internal/agent/config"
+	"github.com/dedpnd/GophKeeper/internal/agent/importer"
+	"github.com/dedpnd/GophKeeper/internal/record"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+)
+
+// UTILS FOR EXPORT.
+
+// runExport writes all own records with their folders, tags, metadata and
+// expiry into an archive protected with the password, HOTP records keep the
+// counter of the issued codes in their URI. The password is taken from the
+// environment or asked twice. The archive is restored by `import -format
+// gophkeeper`.
+func runExport(client *client.Client, cfg *config.ConfigENV, args []string, s *Streams) error {
+	fs := newFlagSet("export", s)
+	out := fs.String("out", "", "path of the archive")
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 || *out == "" {
+		return fmt.Errorf("%w: usage: export -out <path>", ErrUsage)
+	}
+
+	password := cfg.ExportPassword
+	if password == "" {
+		password, err = s.askPassword("Enter archive password: ")
+		if err != nil {
+			return err
+		}
+
+		repeat, err := s.askPassword("Repeat archive password: ")
+		if err != nil {
+			return err
+		}
+
+		if password != repeat {
+			return fmt.Errorf("%w: passwords do not match", ErrUsage)
+		}
+	}
+	if password == "" {
+		return fmt.Errorf("%w: archive password is required", ErrUsage)
+	}
+
+	rAllFile, err := client.ReadAllFile()
+	if err != nil {
+		return fmt.Errorf("failed get all file: %w", err)
+	}
+
+	folders, err := client.ListFolders()
+	if err != nil {
+		return fmt.Errorf("failed get folders: %w", err)
+	}
+	paths := folderPaths(folders)
+
+	units := make([]*proto.StorageUnit, 0, len(rAllFile.Units))
+	for _, v := range rAllFile.Units {
+		if !v.Shared && v.Id > 0 {
+			units = append(units, v)
+		}
+	}
+
+	m := &archive.Manifest{Version: archive.Version, CreatedAt: time.Now().UTC()}
+	for i, v := range units {
+		fmt.Fprintf(s.Err, "Export %v/%v: %s \n", i+1, len(units), v.Name)
+
+		rFile, err := client.ReadFile(v.Id)
+		if err != nil {
+			return fmt.Errorf("failed get file %s: %w", v.Name, err)
+		}
+
+		data := rFile.Data
+		if rFile.Type == record.OTP && v.OtpCounter > 0 {
+			data, err = advanceHOTP(data, v.OtpCounter)
+			if err != nil {
+				return fmt.Errorf("failed export otp counter of %s: %w", v.Name, err)
+			}
+		}
+
+		m.Records = append(m.Records, archive.Record{
+			Name:       v.Name,
+			Type:       rFile.Type,
+			Data:       data,
+			Metadata:   v.Metadata,
+			Folder:     paths[v.Folder],
+			Tags:       v.Tags,
+			ExpiresAt:  v.ExpiresAt,
+			RotateDays: v.RotateDays,
+		})
+	}
+
+	var buf bytes.Buffer
+	err = archive.Write(&buf, password, m)
+	if err != nil {
+		//nolint:wrapcheck // This legal return
+		return err
+	}
+
+	err = writeSecretFile(*out, buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(s.Err, "Exported %v records to %s \n", len(m.Records), *out)
+
+	return s.render(map[string]any{"file": *out, "records": len(m.Records)}, discard)
+}
+
+// advanceHOTP moves the counter of the URI of an HOTP record past the codes
+// issued by the server, the imported record starts with no codes issued.
+// Other OTP records are returned as is.
+func advanceHOTP(data []byte, issued int64) ([]byte, error) {
+	values, err := record.Values(record.OTP, data)
+	if err != nil {
+		//nolint:wrapcheck // This legal return
+		return nil, err
+	}
+
+	key, err := record.ParseOTP(values["uri"])
+	if err != nil {
+		//nolint:wrapcheck // This legal return
+		return nil, err
+	}
+	if key.Kind != record.HOTP {
+		return data, nil
+	}
+
+	u, err := url.Parse(strings.TrimSpace(values["uri"]))
+	if err != nil {
+		return nil, fmt.Errorf("failed parse otp uri: %w", err)
+	}
+
+	q := u.Query()
+	q.Set("counter", strconv.FormatUint(key.Counter+uint64(issued), 10))
+	u.RawQuery = q.Encode()
+	values["uri"] = u.String()
+
+	//nolint:wrapcheck // This legal return
+	return record.Encode(record.OTP, values)
+}
+
+// readArchive reads the records of an archive of `export`.
+func readArchive(r io.Reader, opt importOptions) (*importer.Result, error) {
+	password, err := opt.Password()
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := archive.Read(r, password)
+	if err != nil {
+		//nolint:wrapcheck // This legal return
+		return nil, err
+	}
+
+	res := &importer.Result{}
+	for _, v := range m.Records {
+		res.Items = append(res.Items, importer.Item{
+			Type:       v.Type,
+			Name:       v.Name,
+			Data:       v.Data,
+			Metadata:   v.Metadata,
+			Folder:     v.Folder,
+			Tags:       v.Tags,
+			ExpiresAt:  v.ExpiresAt,
+			RotateDays: v.RotateDays,
+		})
+	}
+
+	return res, nil
+}
@@ -0,0 +1,45 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dedpnd/GophKeeper/internal/record"
+)
+
+func TestAdvanceHOTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		uri    string
+		issued int64
+		want   string
+	}{
+		{
+			name: "Counter of HOTP must be advanced", uri: "otpauth://hotp/test?secret=JBSWY3DPEHPK3PXP&counter=5", issued: 3,
+			want: "otpauth://hotp/test?counter=8&secret=JBSWY3DPEHPK3PXP",
+		},
+		{
+			name: "Missing counter of HOTP must be added", uri: "otpauth://hotp/test?secret=JBSWY3DPEHPK3PXP", issued: 2,
+			want: "otpauth://hotp/test?counter=2&secret=JBSWY3DPEHPK3PXP",
+		},
+		{
+			name: "TOTP must be kept", uri: "otpauth://totp/test?secret=JBSWY3DPEHPK3PXP", issued: 2,
+			want: "otpauth://totp/test?secret=JBSWY3DPEHPK3PXP",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := record.Encode(record.OTP, map[string]string{"uri": tt.uri, "notes": "kept"})
+			assert.NoError(t, err)
+
+			data, err = advanceHOTP(data, tt.issued)
+			assert.NoError(t, err)
+
+			values, err := record.Values(record.OTP, data)
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]string{"uri": tt.want, "notes": "kept"}, values)
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/client"
+	"github.com/dedpnd/GophKeeper/internal/agent/config"
+	"github.com/dedpnd/GophKeeper/internal/record"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+)
+
+// folderView is a folder in the list of folders.
+type folderView struct {
+	ID     int32  `json:"id"     yaml:"id"`
+	Parent int32  `json:"parent" yaml:"parent"`
+	Path   string `json:"path"   yaml:"path"`
+}
+
+// UTILS FOR FOLDERS.
+
+// runFolders prints the paths of all folders.
+func runFolders(client *client.Client, _ *config.ConfigENV, args []string, s *Streams) error {
+	pos, err := parseArgs(newFlagSet("folders", s), args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 {
+		return fmt.Errorf("%w: usage: folders", ErrUsage)
+	}
+
+	folders, err := client.ListFolders()
+	if err != nil {
+		return fmt.Errorf("failed get folders: %w", err)
+	}
+
+	paths := folderPaths(folders)
+	views := make([]folderView, 0, len(folders))
+	for _, v := range folders {
+		views = append(views, folderView{ID: v.Id, Parent: v.Parent, Path: paths[v.Id]})
+	}
+	sort.Slice(views, func(i, j int) bool { return views[i].Path < views[j].Path })
+
+	return s.render(views, func(w io.Writer) error {
+		rows := make([][]string, 0, len(views))
+		for _, v := range views {
+			rows = append(rows, []string{strconv.Itoa(int(v.ID)), v.Path})
+		}
+
+		if s.Output == OutputTable {
+			return writeTable(w, []string{"ID", "PATH"}, rows)
+		}
+
+		for _, v := range rows {
+			fmt.Fprintf(w, "%s\t%s\n", v[0], v[1])
+		}
+		return nil
+	})
+}
+
+// runMkdir creates the folder with the path, missing parents are created too.
+func runMkdir(client *client.Client, _ *config.ConfigENV, args []string, s *Streams) error {
+	pos, err := parseArgs(newFlagSet("mkdir", s), args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return fmt.Errorf("%w: usage: mkdir <path>", ErrUsage)
+	}
+
+	folders, err := client.ListFolders()
+	if err != nil {
+		return fmt.Errorf("failed get folders: %w", err)
+	}
+
+	parent, folders, err := createFolder(client, folders, pos[0])
+	if err != nil {
+		return err
+	}
+
+	if parent == 0 {
+		return fmt.Errorf("%w: folder path is required", ErrUsage)
+	}
+
+	path := folderPaths(folders)[parent]
+	fmt.Fprintf(s.Err, "Folder create: %s \n", path)
+
+	return s.render(map[string]any{"id": parent, "path": path}, discard)
+}
+
+// runRmdir deletes the folder with its subfolders, the records of the folders
+// are moved to the parent of the folder.
+func runRmdir(client *client.Client, _ *config.ConfigENV, args []string, s *Streams) error {
+	pos, err := parseArgs(newFlagSet("rmdir", s), args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return fmt.Errorf("%w: usage: rmdir <path>", ErrUsage)
+	}
+
+	id, err := findFolder(client, pos[0])
+	if err != nil {
+		return err
+	}
+	if id == 0 {
+		return fmt.Errorf("%w: the root folder can not be deleted", ErrUsage)
+	}
+
+	err = client.DeleteFolder(id)
+	if err != nil {
+		return fmt.Errorf("failed delete folder: %w", err)
+	}
+
+	fmt.Fprintf(s.Err, "Folder delete: %s \n", pos[0])
+
+	return s.render(map[string]any{"id": id}, discard)
+}
+
+// runRenameFolder renames the folder with the path.
+func runRenameFolder(client *client.Client, _ *config.ConfigENV, args []string, s *Streams) error {
+	pos, err := parseArgs(newFlagSet("mvdir", s), args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 2 {
+		return fmt.Errorf("%w: usage: mvdir <path> <new name>", ErrUsage)
+	}
+
+	id, err := findFolder(client, pos[0])
+	if err != nil {
+		return err
+	}
+	if id == 0 {
+		return fmt.Errorf("%w: the root folder can not be renamed", ErrUsage)
+	}
+
+	err = client.RenameFolder(id, pos[1])
+	if err != nil {
+		return fmt.Errorf("failed rename folder: %w", err)
+	}
+
+	return s.render(map[string]any{"id": id, "name": pos[1]}, discard)
+}
+
+// runMove moves the record to the folder with the path, `/` is the root.
+func runMove(client *client.Client, _ *config.ConfigENV, args []string, s *Streams) error {
+	pos, err := parseArgs(newFlagSet("mv", s), args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 2 {
+		return fmt.Errorf("%w: usage: mv <id|name> <folder>", ErrUsage)
+	}
+
+	unit, err := findUnit(client, pos[0])
+	if err != nil {
+		return err
+	}
+
+	folder, err := findFolder(client, pos[1])
+	if err != nil {
+		return err
+	}
+
+	err = client.MoveRecord(unit.Id, folder)
+	if err != nil {
+		return fmt.Errorf("failed move file: %w", err)
+	}
+
+	return s.render(map[string]any{"id": unit.Id, "name": unit.Name, "folder": folder}, discard)
+}
+
+// runTag labels the record with the tags.
+func runTag(client *client.Client, _ *config.ConfigENV, args []string, s *Streams) error {
+	return changeTags(client, args, s, "tag", client.TagRecord)
+}
+
+// runUntag removes the tags from the record.
+func runUntag(client *client.Client, _ *config.ConfigENV, args []string, s *Streams) error {
+	return changeTags(client, args, s, "untag", client.UntagRecord)
+}
+
+// changeTags changes the tags of the record with the function.
+func changeTags(client *client.Client, args []string, s *Streams, name string, change func(int32, []string) error) error {
+	pos, err := parseArgs(newFlagSet(name, s), args)
+	if err != nil {
+		return err
+	}
+	if len(pos) < 2 {
+		return fmt.Errorf("%w: usage: %s <id|name> <tag>...", ErrUsage, name)
+	}
+
+	unit, err := findUnit(client, pos[0])
+	if err != nil {
+		return err
+	}
+
+	err = change(unit.Id, pos[1:])
+	if err != nil {
+		return fmt.Errorf("failed %s file: %w", name, err)
+	}
+
+	return s.render(map[string]any{"id": unit.Id, "name": unit.Name, "tags": pos[1:]}, discard)
+}
+
+// findFolder returns the ID of the folder with the path, `/` is the root
+// with zero ID.
+func findFolder(client *client.Client, path string) (int32, error) {
+	names := pathNames(path)
+	if len(names) == 0 {
+		return 0, nil
+	}
+
+	folders, err := client.ListFolders()
+	if err != nil {
+		return 0, fmt.Errorf("failed get folders: %w", err)
+	}
+
+	var id int32
+	for _, name := range names {
+		folder := childFolder(folders, id, name)
+		if folder == nil {
+			return 0, fmt.Errorf("%w: folder %s", ErrNotFound, path)
+		}
+		id = folder.Id
+	}
+
+	return id, nil
+}
+
+// createFolder returns the ID of the folder with the path, the missing
+// folders of the path are created and added to the folders.
+func createFolder(client *client.Client, folders []*proto.Folder, path string) (int32, []*proto.Folder, error) {
+	var parent int32
+	for _, name := range pathNames(path) {
+		if folder := childFolder(folders, parent, name); folder != nil {
+			parent = folder.Id
+			continue
+		}
+
+		folder, err := client.CreateFolder(parent, name)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed create folder: %w", err)
+		}
+
+		folders = append(folders, folder)
+		parent = folder.Id
+	}
+
+	return parent, folders, nil
+}
+
+// pathNames returns the names of the folders of the path.
+func pathNames(path string) []string {
+	var names []string
+	for _, v := range strings.Split(path, record.Separator) {
+		if v = strings.TrimSpace(v); v != "" {
+			names = append(names, v)
+		}
+	}
+
+	return names
+}
+
+// childFolder returns the folder of the parent with the name.
+func childFolder(folders []*proto.Folder, parent int32, name string) *proto.Folder {
+	for _, v := range folders {
+		if v.Parent == parent && v.Name == name {
+			return v
+		}
+	}
+
+	return nil
+}
+
+// folderPaths returns the paths of the folders by their IDs.
+func folderPaths(folders []*proto.Folder) map[int32]string {
+	byID := make(map[int32]*proto.Folder, len(folders))
+	for _, v := range folders {
+		byID[v.Id] = v
+	}
+
+	paths := make(map[int32]string, len(folders))
+	for _, v := range folders {
+		names := []string{}
+		// The depth is limited by the number of folders, so a broken tree ends
+		for f := v; f != nil && len(names) <= len(folders); f = byID[f.Parent] {
+			names = append([]string{f.Name}, names...)
+		}
+		paths[v.Id] = strings.Join(names, record.Separator)
+	}
+
+	return paths
+}
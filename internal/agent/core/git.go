@@ -0,0 +1,318 @@
+package core
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"strings"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/client"
+	"github.com/dedpnd/GophKeeper/internal/agent/config"
+	"github.com/dedpnd/GophKeeper/internal/record"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+)
+
+// Metadata attributes of the login records of Git credentials.
+const (
+	metaGitProtocol = "git_protocol"
+	metaGitHost     = "git_host"
+	metaGitPath     = "git_path"
+)
+
+var errGitValue = errors.New("credential value contains a newline or NUL")
+
+// gitCredential is the description of a credential of the Git credential
+// helper protocol.
+type gitCredential struct {
+	Protocol string
+	Host     string
+	Path     string
+	Username string
+	Password string
+}
+
+// UTILS FOR GIT.
+
+// runGitCredential implements the Git credential helper protocol. Git writes
+// the description of the credential to the input, `get` prints the username
+// and the password of the matching login record, `store` creates or updates
+// the record, `erase` deletes the matching records.
+func runGitCredential(client *client.Client, _ *config.ConfigENV, args []string, s *Streams) error {
+	pos, err := parseArgs(newFlagSet("git-credential", s), args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return fmt.Errorf("%w: usage: git-credential <get|store|erase>", ErrUsage)
+	}
+
+	cred, err := readGitCredential(s)
+	if err != nil {
+		return err
+	}
+
+	switch pos[0] {
+	case "get":
+		return getGitCredential(client, cred, s)
+	case "store":
+		return storeGitCredential(client, cred)
+	case "erase":
+		return eraseGitCredential(client, cred)
+	}
+
+	// Git ignores the operations unknown to the helper
+	return nil
+}
+
+// getGitCredential prints the username and the password of the record of
+// the credential. The records of the path are preferred to the records of
+// the whole host. Nothing is printed if there is no record, so Git asks the
+// user.
+func getGitCredential(client *client.Client, cred gitCredential, s *Streams) error {
+	if cred.Host == "" {
+		return nil
+	}
+
+	units, err := findGitUnits(client, cred)
+	if err != nil {
+		return err
+	}
+
+	var found map[string]string
+	for _, path := range []string{cred.Path, ""} {
+		for _, v := range units {
+			if v.Metadata[metaGitPath] != path {
+				continue
+			}
+
+			values, err := gitValues(client, v)
+			if err != nil {
+				return err
+			}
+
+			if cred.Username == "" || values["username"] == cred.Username {
+				found = values
+				break
+			}
+		}
+
+		if found != nil || cred.Path == "" {
+			break
+		}
+	}
+
+	if found == nil {
+		return nil
+	}
+
+	// Nothing is printed if one of the values is invalid
+	var out strings.Builder
+	if found["username"] != "" {
+		err = writeGitAttribute(&out, "username", found["username"])
+		if err != nil {
+			return err
+		}
+	}
+
+	err = writeGitAttribute(&out, "password", found["password"])
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(s.Out, out.String())
+
+	return nil
+}
+
+// storeGitCredential writes the credential to the login record named after
+// the credential, the record is created if it does not exist.
+func storeGitCredential(client *client.Client, cred gitCredential) error {
+	if cred.Protocol == "" || cred.Host == "" || cred.Password == "" {
+		return nil
+	}
+
+	name := gitRecordName(cred)
+
+	var id int32
+	metadata := map[string]string{}
+	unit, err := findUnitByName(client, name)
+	switch {
+	case err == nil:
+		id = unit.Id
+		maps.Copy(metadata, unit.Metadata)
+	case !errors.Is(err, ErrNotFound):
+		return err
+	}
+
+	metadata[metaGitProtocol] = cred.Protocol
+	metadata[metaGitHost] = cred.Host
+	metadata[metaGitPath] = cred.Path
+
+	data, err := record.Encode(record.Login, map[string]string{
+		"username": cred.Username,
+		"password": cred.Password,
+		"url":      gitURL(cred),
+	})
+	if err != nil {
+		return fmt.Errorf("failed parse record: %w", err)
+	}
+
+	_, err = client.WriteDataWithMetadata(id, record.Login, name, data, metadata)
+	if err != nil {
+		return fmt.Errorf("failed write file: %w", err)
+	}
+
+	return nil
+}
+
+// eraseGitCredential deletes the records of the credential. Only the records
+// of the same path are deleted, the username and the password must match if
+// Git passes them.
+func eraseGitCredential(client *client.Client, cred gitCredential) error {
+	if cred.Host == "" {
+		return nil
+	}
+
+	units, err := findGitUnits(client, cred)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range units {
+		if v.Metadata[metaGitPath] != cred.Path {
+			continue
+		}
+
+		values, err := gitValues(client, v)
+		if err != nil {
+			return err
+		}
+
+		if cred.Username != "" && values["username"] != cred.Username ||
+			cred.Password != "" && values["password"] != cred.Password {
+			continue
+		}
+
+		_, err = client.DeleteFile(v.Id)
+		if err != nil {
+			return fmt.Errorf("failed delete file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// findGitUnits returns the login records of the protocol and the host of
+// the credential.
+func findGitUnits(client *client.Client, cred gitCredential) ([]*proto.StorageUnit, error) {
+	return searchAll(client, &proto.SearchRecordsRequest{
+		Type: record.Login,
+		Metadata: map[string]string{
+			metaGitProtocol: cred.Protocol,
+			metaGitHost:     cred.Host,
+		},
+	})
+}
+
+// gitValues returns the fields of the login record.
+func gitValues(client *client.Client, unit *proto.StorageUnit) (map[string]string, error) {
+	rFile, err := client.ReadFile(unit.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed get file: %w", err)
+	}
+
+	values, err := record.Values(rFile.Type, rFile.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed read fields: %w", err)
+	}
+
+	return values, nil
+}
+
+// readGitCredential reads the attributes of the credential up to an empty
+// line or the end of the input. A `url` attribute is split into the
+// protocol, the host and the path.
+func readGitCredential(s *Streams) (gitCredential, error) {
+	var cred gitCredential
+
+	scanner := bufio.NewScanner(s.In)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			break
+		}
+
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			return cred, fmt.Errorf("%w: invalid attribute %q", ErrUsage, line)
+		}
+
+		switch k {
+		case "protocol":
+			cred.Protocol = v
+		case "host":
+			cred.Host = v
+		case "path":
+			cred.Path = v
+		case "username":
+			cred.Username = v
+		case "password":
+			cred.Password = v
+		case "url":
+			u, err := url.Parse(v)
+			if err != nil {
+				return cred, fmt.Errorf("%w: invalid url: %w", ErrUsage, err)
+			}
+
+			cred.Protocol = u.Scheme
+			cred.Host = u.Host
+			cred.Path = strings.TrimPrefix(u.Path, "/")
+			if u.User != nil {
+				cred.Username = u.User.Username()
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return cred, fmt.Errorf(errorFailedReadSTDIN, err)
+	}
+
+	return cred, nil
+}
+
+// writeGitAttribute writes the attribute of the credential. Values with a
+// newline or NUL are rejected, Git would read their rest as other attributes.
+func writeGitAttribute(w io.Writer, key string, value string) error {
+	if strings.ContainsAny(value, "\n\x00") {
+		return fmt.Errorf("%w: %s", errGitValue, key)
+	}
+
+	fmt.Fprintf(w, "%s=%s\n", key, value)
+
+	return nil
+}
+
+// gitRecordName returns the name of the record of the credential.
+func gitRecordName(cred gitCredential) string {
+	name := "git/" + cred.Protocol + "/"
+	if cred.Username != "" {
+		name += cred.Username + "@"
+	}
+	name += cred.Host
+
+	if cred.Path != "" {
+		name += "/" + cred.Path
+	}
+
+	return name
+}
+
+// gitURL returns the URL of the credential.
+func gitURL(cred gitCredential) string {
+	u := url.URL{Scheme: cred.Protocol, Host: cred.Host, Path: "/" + cred.Path}
+
+	return strings.TrimSuffix(u.String(), "/")
+}
@@ -0,0 +1,30 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteGitAttribute(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		out   string
+		err   error
+	}{
+		{name: "Plain value must be written", value: "p@ss=word", out: "password=p@ss=word\n"},
+		{name: "Value with newline must be rejected", value: "secret\nusername=admin", err: errGitValue},
+		{name: "Value with NUL must be rejected", value: "secret\x00", err: errGitValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			err := writeGitAttribute(&out, "password", tt.value)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.out, out.String())
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/client"
+	"github.com/dedpnd/GophKeeper/internal/agent/config"
+	"github.com/dedpnd/GophKeeper/internal/agent/importer"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+)
+
+// importOptions are the options of the import formats. `Password` returns
+// the password of the encrypted formats.
+type importOptions struct {
+	Type     string
+	Mapping  map[string]string
+	Password func() (string, error)
+}
+
+// importFormats maps the names of the formats of `import` to their parsers.
+var importFormats = map[string]func(r io.Reader, opt importOptions) (*importer.Result, error){
+	"keepass": func(r io.Reader, _ importOptions) (*importer.Result, error) {
+		//nolint:wrapcheck // This legal return
+		return importer.KeePassXML(r)
+	},
+	"bitwarden": func(r io.Reader, _ importOptions) (*importer.Result, error) {
+		//nolint:wrapcheck // This legal return
+		return importer.BitwardenJSON(r)
+	},
+	"csv": func(r io.Reader, opt importOptions) (*importer.Result, error) {
+		//nolint:wrapcheck // This legal return
+		return importer.CSV(r, opt.Type, opt.Mapping)
+	},
+	"gophkeeper": readArchive,
+}
+
+// importView is the report of the import.
+type importView struct {
+	Imported int                `json:"imported" yaml:"imported"`
+	Skipped  []importer.Skipped `json:"skipped"  yaml:"skipped"`
+}
+
+// UTILS FOR IMPORT.
+
+// runImport imports the export of another password manager or an archive of
+// `export`. Records with the name of an existing record or of a record
+// imported before are skipped, the skipped entries are reported with the
+// reasons.
+func runImport(client *client.Client, cfg *config.ConfigENV, args []string, s *Streams) error {
+	fs := newFlagSet("import", s)
+	format := fs.String("format", "", "format of the file: "+importFormatNames())
+	// The archive read from stdin leaves no input for the password prompt
+	var fromStdin bool
+	opt := importOptions{Password: func() (string, error) {
+		if cfg.ExportPassword != "" {
+			return cfg.ExportPassword, nil
+		}
+		if fromStdin {
+			return "", fmt.Errorf("%w: set EXPORT_PASSWORD to import an archive from stdin", ErrUsage)
+		}
+
+		return s.askPassword("Enter archive password: ")
+	}}
+	fs.StringVar(&opt.Type, "type", "login", "type of the records of csv rows")
+	mapping := fs.String("map", "", "csv columns of the fields: name=Title,password=Secret,...")
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return fmt.Errorf("%w: usage: import -format <%s> <file|->", ErrUsage, importFormatNames())
+	}
+
+	parse, ok := importFormats[*format]
+	if !ok {
+		return fmt.Errorf("%w: unknown format %q, use %s", ErrUsage, *format, importFormatNames())
+	}
+
+	opt.Mapping, err = importer.ParseMapping(*mapping)
+	if err != nil {
+		//nolint:wrapcheck // This legal return
+		return err
+	}
+
+	in := s.In
+	fromStdin = pos[0] == "-"
+	if !fromStdin {
+		file, err := os.Open(pos[0])
+		if err != nil {
+			return fmt.Errorf("failed open file: %w", err)
+		}
+		defer file.Close() //nolint:errcheck // The file is only read
+
+		in = file
+	}
+
+	res, err := parse(in, opt)
+	if err != nil {
+		return fmt.Errorf("failed parse file: %w", err)
+	}
+
+	// Records are deduplicated by name
+	rAllFile, err := client.ReadAllFile()
+	if err != nil {
+		return fmt.Errorf("failed get all file: %w", err)
+	}
+
+	names := map[string]bool{}
+	for _, v := range rAllFile.Units {
+		if !v.Shared {
+			names[v.Name] = true
+		}
+	}
+
+	var folders []*proto.Folder
+
+	view := importView{Skipped: res.Skipped}
+	for i, v := range res.Items {
+		if names[v.Name] {
+			view.Skipped = append(view.Skipped, importer.Skipped{Name: v.Name, Reason: "record with the same name exists"})
+			continue
+		}
+
+		fmt.Fprintf(s.Err, "Import %v/%v: %s \n", i+1, len(res.Items), v.Name)
+
+		_, err = client.WriteDataWithMetadata(0, v.Type, v.Name, v.Data, v.Metadata)
+		if err != nil {
+			view.Skipped = append(view.Skipped, importer.Skipped{Name: v.Name, Reason: err.Error()})
+			continue
+		}
+
+		names[v.Name] = true
+		view.Imported++
+
+		folders, err = restoreAttributes(client, v, folders)
+		if err != nil {
+			fmt.Fprintf(s.Err, "Attributes of %s not restored: %s \n", v.Name, err.Error())
+		}
+	}
+
+	return s.render(view, func(w io.Writer) error {
+		fmt.Fprintf(s.Err, "Imported %v records, skipped %v \n", view.Imported, len(view.Skipped))
+		if len(view.Skipped) == 0 {
+			return nil
+		}
+
+		rows := make([][]string, 0, len(view.Skipped))
+		for _, v := range view.Skipped {
+			rows = append(rows, []string{v.Name, v.Reason})
+		}
+
+		return writeTable(w, []string{"SKIPPED", "REASON"}, rows)
+	})
+}
+
+// restoreAttributes moves the written record to its folder and restores its
+// tags and expiry. The folders are listed on the first use, missing folders
+// are created.
+func restoreAttributes(client *client.Client, item importer.Item, folders []*proto.Folder) ([]*proto.Folder, error) {
+	if item.Folder == "" && len(item.Tags) == 0 && item.ExpiresAt == 0 && item.RotateDays == 0 {
+		return folders, nil
+	}
+
+	unit, err := findUnitByName(client, item.Name)
+	if err != nil {
+		return folders, err
+	}
+
+	if item.Folder != "" {
+		if folders == nil {
+			folders, err = client.ListFolders()
+			if err != nil {
+				return nil, fmt.Errorf("failed get folders: %w", err)
+			}
+		}
+
+		var id int32
+		id, folders, err = createFolder(client, folders, item.Folder)
+		if err != nil {
+			return folders, err
+		}
+
+		err = client.MoveRecord(unit.Id, id)
+		if err != nil {
+			return folders, fmt.Errorf("failed move file: %w", err)
+		}
+	}
+
+	if len(item.Tags) != 0 {
+		err = client.TagRecord(unit.Id, item.Tags)
+		if err != nil {
+			return folders, fmt.Errorf("failed tag file: %w", err)
+		}
+	}
+
+	if item.ExpiresAt != 0 || item.RotateDays != 0 {
+		err = client.SetRecordExpiry(unit.Id, item.ExpiresAt, item.RotateDays)
+		if err != nil {
+			return folders, fmt.Errorf("failed set expiry: %w", err)
+		}
+	}
+
+	return folders, nil
+}
+
+// importFormatNames returns the names of the import formats.
+func importFormatNames() string {
+	names := make([]string, 0, len(importFormats))
+	for k := range importFormats {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, "|")
+}
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/client"
+	"github.com/dedpnd/GophKeeper/internal/agent/config"
+)
+
+// injectPermition allows only the user to read the rendered file, it holds
+// the secrets.
+var injectPermition os.FileMode = 0o600
+
+// UTILS FOR INJECT.
+
+// runInject renders the template with the secrets of the records. The
+// template refers to them as `{{ gk "name" "field" }}`, the field is omitted
+// for the records without fields. Every record is read once. The file is
+// written only if all the references are resolved.
+func runInject(client *client.Client, _ *config.ConfigENV, args []string, s *Streams) error {
+	fs := newFlagSet("inject", s)
+	out := fs.String("out", "", "write the rendered template to the file, stdout by default")
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return fmt.Errorf("%w: usage: inject <template> [-out path]", ErrUsage)
+	}
+
+	text, err := os.ReadFile(pos[0])
+	if err != nil {
+		return fmt.Errorf("failed read template: %w", err)
+	}
+
+	secrets := newSecretCache(client)
+	tmpl, err := template.New(filepath.Base(pos[0])).
+		Option("missingkey=error").
+		Funcs(template.FuncMap{
+			"gk": func(ref string, field ...string) (string, error) {
+				if len(field) > 1 {
+					return "", fmt.Errorf("%w: gk takes the record and one field", ErrUsage)
+				}
+
+				return secrets.field(ref, append(field, "")[0])
+			},
+		}).
+		Parse(string(text))
+	if err != nil {
+		return fmt.Errorf("%w: failed parse template: %w", ErrUsage, err)
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, nil)
+	if err != nil {
+		return fmt.Errorf("failed render template: %w", err)
+	}
+
+	if *out == "" {
+		_, err = s.Out.Write(buf.Bytes())
+		if err != nil {
+			return fmt.Errorf("failed write data: %w", err)
+		}
+
+		return nil
+	}
+
+	err = writeSecretFile(*out, buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(s.Err, "File save in: %s \n", *out)
+
+	return s.render(map[string]any{"file": *out, "records": len(secrets.records)}, discard)
+}
+
+// writeSecretFile replaces the file with the data. The data is written to a
+// temporary file in the same directory first, so the file is never partly
+// written or readable by others.
+func writeSecretFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed create file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // The file is renamed on success
+
+	err = tmp.Chmod(injectPermition)
+	if err == nil {
+		_, err = tmp.Write(data)
+	}
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return fmt.Errorf("failed write data: %w", err)
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return fmt.Errorf("failed write data: %w", err)
+	}
+
+	return nil
+}
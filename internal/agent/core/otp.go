@@ -0,0 +1,82 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/client"
+	"github.com/dedpnd/GophKeeper/internal/agent/config"
+	"github.com/dedpnd/GophKeeper/internal/record"
+)
+
+// otpView is a one-time password, `Remaining` is the number of seconds the
+// TOTP code is valid, `Counter` is the counter of the HOTP code.
+type otpView struct {
+	Code      string `json:"code"                yaml:"code"`
+	Remaining int    `json:"remaining,omitempty" yaml:"remaining,omitempty"`
+	Counter   uint64 `json:"counter,omitempty"   yaml:"counter,omitempty"`
+}
+
+// UTILS FOR OTP.
+
+// runOTP prints the current code of the OTP record found by ID or name. HOTP
+// codes take the next counter of the record on the server, so every call
+// prints a new code.
+func runOTP(client *client.Client, _ *config.ConfigENV, args []string, s *Streams) error {
+	pos, err := parseArgs(newFlagSet("otp", s), args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return fmt.Errorf("%w: usage: otp <id|name>", ErrUsage)
+	}
+
+	unit, err := findUnit(client, pos[0])
+	if err != nil {
+		return err
+	}
+
+	rFile, err := client.ReadFile(unit.Id)
+	if err != nil {
+		return fmt.Errorf("failed get file: %w", err)
+	}
+
+	if rFile.Type != record.OTP {
+		return fmt.Errorf("%w: file %s is not an otp", ErrUsage, unit.Name)
+	}
+
+	values, err := record.Values(rFile.Type, rFile.Data)
+	if err != nil {
+		return fmt.Errorf("failed read fields: %w", err)
+	}
+
+	key, err := record.ParseOTP(values["uri"])
+	if err != nil {
+		return fmt.Errorf("failed read otp: %w", err)
+	}
+
+	var view otpView
+	if key.Kind == record.HOTP {
+		issued, err := client.NextOTPCounter(unit.Id)
+		if err != nil {
+			return fmt.Errorf("failed take otp counter: %w", err)
+		}
+
+		view.Counter = key.Counter + uint64(issued)
+		view.Code = key.HOTP(view.Counter)
+	} else {
+		code, left := key.TOTP(time.Now())
+		view.Code = code
+		view.Remaining = int(left.Seconds())
+	}
+
+	return s.render(view, func(w io.Writer) error {
+		if view.Remaining != 0 {
+			fmt.Fprintf(s.Err, "Valid for %vs \n", view.Remaining)
+		}
+
+		fmt.Fprintln(w, view.Code)
+		return nil
+	})
+}
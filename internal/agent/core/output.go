@@ -0,0 +1,317 @@
+package core
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dedpnd/GophKeeper/internal/record"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats of the subcommands. The text format is the default one.
+const (
+	OutputText  = "text"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+	OutputTable = "table"
+)
+
+// Encodings of record values in the structured output.
+const encodingBase64 = "base64"
+
+// unitView is a record in the list of records.
+type unitView struct {
+	ID         int32             `json:"id"                    yaml:"id"`
+	Name       string            `json:"name"                  yaml:"name"`
+	Type       string            `json:"type"                  yaml:"type"`
+	Owner      int32             `json:"owner"                 yaml:"owner"`
+	OwnerLogin string            `json:"owner_login,omitempty" yaml:"owner_login,omitempty"`
+	Shared     bool              `json:"shared"                yaml:"shared"`
+	Permission string            `json:"permission,omitempty"  yaml:"permission,omitempty"`
+	Conflict   bool              `json:"conflict"              yaml:"conflict"`
+	CreatedAt  string            `json:"created_at,omitempty"  yaml:"created_at,omitempty"`
+	UpdatedAt  string            `json:"updated_at,omitempty"  yaml:"updated_at,omitempty"`
+	Revision   int64             `json:"revision,omitempty"    yaml:"revision,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"    yaml:"metadata,omitempty"`
+	Folder     int32             `json:"folder,omitempty"      yaml:"folder,omitempty"`
+	Tags       []string          `json:"tags,omitempty"        yaml:"tags,omitempty"`
+	DeletedAt  string            `json:"deleted_at,omitempty"  yaml:"deleted_at,omitempty"`
+	ExpiresAt  string            `json:"expires_at,omitempty"  yaml:"expires_at,omitempty"`
+	RotateDays int32             `json:"rotate_days,omitempty" yaml:"rotate_days,omitempty"`
+	DueAt      string            `json:"due_at,omitempty"      yaml:"due_at,omitempty"`
+	Status     string            `json:"status,omitempty"      yaml:"status,omitempty"`
+}
+
+// recordView is a record with its value. Fields of structured records are
+// decoded, values of files are encoded with base64.
+type recordView struct {
+	ID         int32             `json:"id"                    yaml:"id"`
+	Name       string            `json:"name"                  yaml:"name"`
+	Type       string            `json:"type"                  yaml:"type"`
+	Owner      int32             `json:"owner"                 yaml:"owner"`
+	OwnerLogin string            `json:"owner_login,omitempty" yaml:"owner_login,omitempty"`
+	Conflict   bool              `json:"conflict"              yaml:"conflict"`
+	Fields     map[string]string `json:"fields,omitempty"      yaml:"fields,omitempty"`
+	Data       string            `json:"data,omitempty"        yaml:"data,omitempty"`
+	Encoding   string            `json:"encoding,omitempty"    yaml:"encoding,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"    yaml:"metadata,omitempty"`
+	Versions   map[string]int64  `json:"versions,omitempty"    yaml:"versions,omitempty"`
+}
+
+// errorView is the error of a subcommand. `Class` names the class of the
+// error, `Code` is the exit code of the process.
+type errorView struct {
+	Error string `json:"error" yaml:"error"`
+	Class string `json:"class" yaml:"class"`
+	Code  int    `json:"code"  yaml:"code"`
+}
+
+// errorClasses names the classes of errors by exit codes.
+var errorClasses = map[int]string{
+	ExitError:       "error",
+	ExitUsage:       "usage",
+	ExitAuth:        "auth",
+	ExitNotFound:    "not_found",
+	ExitUnavailable: "unavailable",
+}
+
+// PrintError writes the error of the subcommand. In the structured formats the
+// error is written to stdout, so it is always a single document, otherwise it
+// is written to stderr.
+func PrintError(s *Streams, err error) {
+	// The child process reports its errors itself
+	var exitStatus *exitStatusError
+	if errors.As(err, &exitStatus) {
+		return
+	}
+
+	code := ExitCode(err)
+	view := errorView{Error: err.Error(), Class: errorClasses[code], Code: code}
+
+	if s.Output == OutputJSON || s.Output == OutputYAML {
+		if s.render(view, nil) == nil {
+			return
+		}
+	}
+
+	fmt.Fprintf(s.Err, "error: %s\n", err.Error())
+}
+
+// UTILS FOR OUTPUT.
+
+// printUnitList writes the list of records in the output format.
+func printUnitList(s *Streams, found []*proto.StorageUnit) error {
+	units := make([]unitView, 0, len(found))
+	for _, v := range found {
+		if v.Id <= 0 {
+			continue
+		}
+
+		units = append(units, newUnitView(v))
+	}
+
+	return s.render(units, func(w io.Writer) error {
+		if s.Output == OutputTable {
+			return writeTable(w, []string{"ID", "TYPE", "NAME", "OWNER", "UPDATED", "TAGS", "FLAGS"}, unitRows(units))
+		}
+
+		for _, v := range units {
+			fmt.Fprintf(w, "%v\t%s\t%s\n", v.ID, v.Type, v.Name)
+		}
+		return nil
+	})
+}
+
+// checkOutput checks the name of the output format.
+func checkOutput(output string) error {
+	switch output {
+	case "", OutputText, OutputJSON, OutputYAML, OutputTable:
+		return nil
+	}
+
+	return fmt.Errorf("%w: unknown output %q, use json, yaml or table", ErrUsage, output)
+}
+
+// render writes the value in the structured formats, the text and table
+// formats are written by `plain`.
+func (s *Streams) render(v any, plain func(w io.Writer) error) error {
+	switch s.Output {
+	case OutputJSON:
+		enc := json.NewEncoder(s.Out)
+		enc.SetIndent("", "  ")
+
+		err := enc.Encode(v)
+		if err != nil {
+			return fmt.Errorf("failed encode output: %w", err)
+		}
+
+		return nil
+	case OutputYAML:
+		enc := yaml.NewEncoder(s.Out)
+		enc.SetIndent(2) //nolint:gomnd // This legal number
+
+		err := enc.Encode(v)
+		if err != nil {
+			return fmt.Errorf("failed encode output: %w", err)
+		}
+
+		err = enc.Close()
+		if err != nil {
+			return fmt.Errorf("failed encode output: %w", err)
+		}
+
+		return nil
+	default:
+		return plain(s.Out)
+	}
+}
+
+// writeTable writes the rows aligned in columns under the header.
+func writeTable(w io.Writer, header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:gomnd // This legal number
+
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	err := tw.Flush()
+	if err != nil {
+		return fmt.Errorf("failed write table: %w", err)
+	}
+
+	return nil
+}
+
+// newUnitView converts the record of a list to its view.
+func newUnitView(v *proto.StorageUnit) unitView {
+	return unitView{
+		ID:         v.Id,
+		Name:       v.Name,
+		Type:       v.Type,
+		Owner:      v.Owner,
+		OwnerLogin: v.OwnerLogin,
+		Shared:     v.Shared,
+		Permission: v.Permission,
+		Conflict:   v.Conflict,
+		CreatedAt:  formatTime(v.CreatedAt),
+		UpdatedAt:  formatTime(v.UpdatedAt),
+		Revision:   v.Revision,
+		Metadata:   v.Metadata,
+		Folder:     v.Folder,
+		Tags:       v.Tags,
+		DeletedAt:  formatTime(v.DeletedAt),
+		ExpiresAt:  formatTime(v.ExpiresAt),
+		RotateDays: v.RotateDays,
+		DueAt:      formatTime(v.DueAt),
+		Status:     v.ExpiryStatus,
+	}
+}
+
+// newRecordView converts the record read from the server to its view.
+func newRecordView(id int32, rec *proto.ReadRecordResponse) recordView {
+	view := recordView{
+		ID:         id,
+		Name:       rec.Name,
+		Type:       rec.Type,
+		Owner:      rec.Owner,
+		OwnerLogin: rec.OwnerLogin,
+		Conflict:   rec.Conflict,
+		Metadata:   rec.Metadata,
+		Versions:   rec.Versions,
+	}
+
+	if record.Structured(rec.Type) {
+		if fields, err := record.Values(rec.Type, rec.Data); err == nil {
+			view.Fields = fields
+			return view
+		}
+	}
+
+	if rec.Type == record.File {
+		view.Data = base64.StdEncoding.EncodeToString(rec.Data)
+		view.Encoding = encodingBase64
+		return view
+	}
+
+	view.Data = string(rec.Data)
+
+	return view
+}
+
+// unitRows returns the rows of the table of records.
+func unitRows(units []unitView) [][]string {
+	rows := make([][]string, 0, len(units))
+	for _, v := range units {
+		var flags []string
+		if v.Shared {
+			flags = append(flags, "shared:"+v.Permission)
+		}
+		if v.Conflict {
+			flags = append(flags, "conflict")
+		}
+		if v.Status != "" && v.Status != expiryOK {
+			flags = append(flags, v.Status)
+		}
+
+		rows = append(rows, []string{
+			strconv.Itoa(int(v.ID)), v.Type, v.Name, v.OwnerLogin, v.UpdatedAt, strings.Join(v.Tags, ","),
+			strings.Join(flags, ","),
+		})
+	}
+
+	return rows
+}
+
+// recordRows returns the rows of the table of a record, fields of structured
+// records are rows too.
+func recordRows(v recordView) [][]string {
+	rows := [][]string{
+		{"id", strconv.Itoa(int(v.ID))},
+		{"name", v.Name},
+		{"type", v.Type},
+		{"owner", v.OwnerLogin},
+	}
+
+	for _, f := range record.Fields(v.Type) {
+		if value, ok := v.Fields[f.Name]; ok {
+			rows = append(rows, []string{f.Name, value})
+		}
+	}
+
+	switch {
+	case v.Encoding == encodingBase64:
+		size := base64.StdEncoding.DecodedLen(len(v.Data))
+		rows = append(rows, []string{"data", fmt.Sprintf("<file, about %v bytes>", size)})
+	case v.Fields == nil:
+		rows = append(rows, []string{"data", v.Data})
+	}
+
+	if v.Conflict {
+		rows = append(rows, []string{"conflict", "true"})
+	}
+
+	return rows
+}
+
+// discard writes nothing, the text result of the subcommand is its messages.
+func discard(io.Writer) error {
+	return nil
+}
+
+// formatTime formats the Unix time, zero times are empty.
+func formatTime(sec int64) string {
+	if sec == 0 {
+		return ""
+	}
+
+	return time.Unix(sec, 0).UTC().Format(time.RFC3339)
+}
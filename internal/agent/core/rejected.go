@@ -0,0 +1,72 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/client"
+	"github.com/dedpnd/GophKeeper/internal/agent/config"
+)
+
+// UTILS FOR REJECTED CHANGES.
+
+// rejectedView is the printed form of a change made offline and rejected
+// by the server on replay.
+type rejectedView struct {
+	Kind  string `json:"kind"  yaml:"kind"`
+	ID    int32  `json:"id"    yaml:"id"`
+	Name  string `json:"name"  yaml:"name"`
+	Error string `json:"error" yaml:"error"`
+}
+
+// runRejected prints the changes made offline which the server rejected.
+// `-retry` queues them again, `-clear` drops them.
+func runRejected(client *client.Client, _ *config.ConfigENV, args []string, s *Streams) error {
+	fs := newFlagSet("rejected", s)
+	retry := fs.Bool("retry", false, "send the rejected changes again on the next connection")
+	clear := fs.Bool("clear", false, "drop the rejected changes")
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 || (*retry && *clear) {
+		return fmt.Errorf("%w: usage: rejected [-retry|-clear]", ErrUsage)
+	}
+
+	if client.Cache == nil {
+		return fmt.Errorf("%w: local cache is not enabled", ErrUsage)
+	}
+
+	rejected := client.Cache.Rejected()
+	if *retry || *clear {
+		err := client.Cache.ClearRejected(*retry)
+		if err != nil {
+			return fmt.Errorf("failed update cache: %w", err)
+		}
+
+		return s.render(map[string]any{"changes": len(rejected), "retry": *retry}, discard)
+	}
+
+	views := make([]rejectedView, 0, len(rejected))
+	for _, v := range rejected {
+		views = append(views, rejectedView{Kind: v.Kind, ID: v.ID, Name: v.Name, Error: v.Error})
+	}
+
+	return s.render(views, func(w io.Writer) error {
+		rows := make([][]string, 0, len(views))
+		for _, v := range views {
+			rows = append(rows, []string{v.Kind, fmt.Sprint(v.ID), v.Name, v.Error})
+		}
+
+		if s.Output == OutputTable {
+			return writeTable(w, []string{"KIND", "ID", "NAME", "ERROR"}, rows)
+		}
+
+		for _, v := range rows {
+			fmt.Fprintln(w, strings.Join(v, "\t"))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,276 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/client"
+	"github.com/dedpnd/GophKeeper/internal/agent/config"
+)
+
+// secretMask replaces the secrets in the output of the child process.
+var secretMask = []byte("*****")
+
+// exitStatusError is the exit status of the child process, the agent exits
+// with the same status.
+type exitStatusError struct {
+	Code int
+}
+
+// Error returns the description of the exit status.
+func (e *exitStatusError) Error() string {
+	return fmt.Sprintf("process exited with status %v", e.Code)
+}
+
+// UTILS FOR RUN.
+
+// runProcess runs the command with the secrets of the records set in its
+// environment. Signals are forwarded to the child, the secrets are masked in
+// its stdout and stderr, the agent exits with the status of the child. The
+// child stays in the process group of the agent, so it keeps the terminal.
+func runProcess(client *client.Client, _ *config.ConfigENV, args []string, s *Streams) error {
+	fs := newFlagSet("run", s)
+	env := map[string]string{}
+	fs.Func("env", "environment variable NAME=reference, the reference is <id|name> or <name>/<field>, repeatable",
+		func(v string) error {
+			k, ref, ok := strings.Cut(v, "=")
+			if !ok || strings.TrimSpace(k) == "" || ref == "" {
+				return fmt.Errorf("expected NAME=reference, got %q", v)
+			}
+
+			env[strings.TrimSpace(k)] = ref
+			return nil
+		})
+
+	// Flags of the command are not parsed, they follow `--` or the command
+	err := fs.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUsage, err)
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("%w: usage: run -env NAME=reference... -- <command> [args...]", ErrUsage)
+	}
+
+	secrets := newSecretCache(client)
+	values := make([]string, 0, len(env))
+
+	cmd := exec.Command(fs.Arg(0), fs.Args()[1:]...) //nolint:gosec // The command is given by the user
+	cmd.Env = os.Environ()
+	for k, ref := range env {
+		v, err := secrets.resolve(ref)
+		if err != nil {
+			return fmt.Errorf("failed resolve %s: %w", k, err)
+		}
+
+		cmd.Env = append(cmd.Env, k+"="+v)
+		values = append(values, v)
+	}
+
+	stdout := newMaskWriter(s.Out, values)
+	stderr := newMaskWriter(s.Err, values)
+	cmd.Stdin = s.In
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err = cmd.Start()
+	if err != nil {
+		return fmt.Errorf("failed start process: %w", err)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT)
+	defer signal.Stop(signals)
+
+	terminal := isTerminal(os.Stdin) || isTerminal(os.Stdout) || isTerminal(os.Stderr)
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				if forwardSignal(sig, terminal) {
+					_ = cmd.Process.Signal(sig)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err = cmd.Wait()
+
+	for _, w := range []*maskWriter{stdout, stderr} {
+		if errFlush := w.Flush(); errFlush != nil && err == nil {
+			err = errFlush
+		}
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code := exitErr.ExitCode()
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			code = 128 + int(status.Signal())
+		}
+
+		return &exitStatusError{Code: code}
+	}
+	if err != nil {
+		return fmt.Errorf("failed run process: %w", err)
+	}
+
+	return nil
+}
+
+// forwardSignal tells whether the signal received by the agent is forwarded
+// to the child. The signals of the terminal are sent to the whole foreground
+// process group, the child gets them without the agent, so they are not
+// forwarded when the agent runs in a terminal.
+func forwardSignal(sig os.Signal, terminal bool) bool {
+	switch sig {
+	case os.Interrupt, syscall.SIGQUIT, syscall.SIGHUP:
+		return !terminal
+	default:
+		return true
+	}
+}
+
+// maskWriter replaces the secrets in the written data. The end of the data
+// which may start a secret is held until the next write or `Flush`.
+type maskWriter struct {
+	w       io.Writer
+	secrets [][]byte
+	buf     []byte
+}
+
+// newMaskWriter returns the writer which masks the non-empty secrets.
+func newMaskWriter(w io.Writer, secrets []string) *maskWriter {
+	m := &maskWriter{w: w}
+	for _, v := range secrets {
+		if v != "" {
+			m.secrets = append(m.secrets, []byte(v))
+		}
+	}
+
+	return m
+}
+
+// Write writes the data with the secrets masked.
+func (m *maskWriter) Write(p []byte) (int, error) {
+	m.buf = append(m.buf, p...)
+
+	_, err := m.w.Write(m.mask(false))
+	if err != nil {
+		//nolint:wrapcheck // This legal return
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Flush writes the held data with the secrets masked.
+func (m *maskWriter) Flush() error {
+	out := m.mask(true)
+	if len(out) == 0 {
+		return nil
+	}
+
+	_, err := m.w.Write(out)
+
+	//nolint:wrapcheck // This legal return
+	return err
+}
+
+// mask returns the data of the buffer with the secrets masked. Unless the
+// data is final, the end of the buffer which may start a secret or continue
+// a masked range stays in the buffer.
+func (m *maskWriter) mask(final bool) []byte {
+	var out []byte
+	for {
+		pos, end, open := m.match()
+		if pos < 0 {
+			break
+		}
+
+		out = append(out, m.buf[:pos]...)
+
+		// The masked range may go on in the next write
+		if open && !final {
+			m.buf = append([]byte(nil), m.buf[pos:]...)
+			return out
+		}
+
+		out = append(out, secretMask...)
+		m.buf = m.buf[end:]
+	}
+
+	held := 0
+	if !final {
+		held = m.partial()
+	}
+
+	out = append(out, m.buf[:len(m.buf)-held]...)
+	m.buf = append([]byte(nil), m.buf[len(m.buf)-held:]...)
+
+	return out
+}
+
+// match returns the range of the first secret in the buffer, the longest
+// secret is taken at the same position. Secrets overlapping the range join
+// it, so no part of them is shown. The range is open if a secret
+// overlapping it is cut by the end of the buffer.
+func (m *maskWriter) match() (int, int, bool) {
+	pos, end := -1, 0
+	for _, v := range m.secrets {
+		i := bytes.Index(m.buf, v)
+		if i < 0 {
+			continue
+		}
+
+		if pos < 0 || i < pos || i == pos && i+len(v) > end {
+			pos, end = i, i+len(v)
+		}
+	}
+
+	if pos < 0 {
+		return -1, 0, false
+	}
+
+	open := false
+	for i := pos + 1; i < end; i++ {
+		for _, v := range m.secrets {
+			switch rest := m.buf[i:]; {
+			case bytes.HasPrefix(rest, v):
+				end = max(end, i+len(v))
+			case bytes.HasPrefix(v, rest):
+				open = true
+			}
+		}
+	}
+
+	return pos, end, open
+}
+
+// partial returns the size of the longest end of the buffer which starts a
+// secret.
+func (m *maskWriter) partial() int {
+	held := 0
+	for _, v := range m.secrets {
+		for n := min(len(v)-1, len(m.buf)); n > held; n-- {
+			if bytes.HasSuffix(m.buf, v[:n]) {
+				held = n
+				break
+			}
+		}
+	}
+
+	return held
+}
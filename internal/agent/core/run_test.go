@@ -0,0 +1,90 @@
+package core
+
+import (
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskWriter(t *testing.T) {
+	tests := []struct {
+		name    string
+		secrets []string
+		writes  []string
+		out     string
+	}{
+		{
+			name:    "Secret in one write must be masked",
+			secrets: []string{"abc123"},
+			writes:  []string{"token=abc123\n"},
+			out:     "token=*****\n",
+		},
+		{
+			name:    "Secret split across writes must be masked",
+			secrets: []string{"abc123"},
+			writes:  []string{"token: ab", "c1", "23 end\n"},
+			out:     "token: ***** end\n",
+		},
+		{
+			name:    "Secret written byte by byte must be masked",
+			secrets: []string{"abc123"},
+			writes:  strings.Split("x abc123 y abc12", ""),
+			out:     "x ***** y abc12",
+		},
+		{
+			name:    "Longest secret at the same position must be masked",
+			secrets: []string{"abc", "abcdef"},
+			writes:  []string{"1abcdef2"},
+			out:     "1*****2",
+		},
+		{
+			name:    "Overlapping secrets must be masked together",
+			secrets: []string{"abcd", "cdef"},
+			writes:  []string{"1abcdef2 cdef"},
+			out:     "1*****2 *****",
+		},
+		{
+			name:    "Overlapping secrets split across writes must be masked together",
+			secrets: []string{"abcd", "cdef"},
+			writes:  []string{"1abcde", "f2"},
+			out:     "1*****2",
+		},
+		{
+			name:    "Start of a secret must be written on flush",
+			secrets: []string{"abcd", ""},
+			writes:  []string{"1ab", "c"},
+			out:     "1abc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			w := newMaskWriter(&out, tt.secrets)
+
+			for _, v := range tt.writes {
+				n, err := w.Write([]byte(v))
+				assert.NoError(t, err)
+				assert.Equal(t, len(v), n)
+
+				for _, secret := range tt.secrets {
+					if secret != "" {
+						assert.NotContains(t, out.String(), secret)
+					}
+				}
+			}
+
+			assert.NoError(t, w.Flush())
+			assert.Equal(t, tt.out, out.String())
+		})
+	}
+}
+
+func TestForwardSignal(t *testing.T) {
+	assert.False(t, forwardSignal(os.Interrupt, true))
+	assert.True(t, forwardSignal(os.Interrupt, false))
+	assert.True(t, forwardSignal(syscall.SIGTERM, true))
+}
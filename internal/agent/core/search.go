@@ -0,0 +1,114 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/client"
+	"github.com/dedpnd/GophKeeper/internal/agent/config"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+)
+
+// dateLayout is the layout of dates accepted instead of RFC 3339 times.
+var dateLayout = time.DateOnly
+
+// UTILS FOR SEARCH.
+
+// runSearch prints the records which match the filters. Without `-limit`
+// and `-page` all pages are printed, otherwise one page is printed and the
+// token of the next page is written to stderr.
+func runSearch(client *client.Client, _ *config.ConfigENV, args []string, s *Streams) error {
+	in := &proto.SearchRecordsRequest{Metadata: map[string]string{}}
+
+	fs := newFlagSet("search", s)
+	fs.StringVar(&in.Name, "name", "", "substring of the name")
+	fs.StringVar(&in.Type, "type", "", "type of the records")
+	fs.Func("meta", "metadata attribute key=value, key only requires the attribute, repeatable", func(v string) error {
+		k, value, _ := strings.Cut(v, "=")
+		if strings.TrimSpace(k) == "" {
+			return fmt.Errorf("empty key in %q", v)
+		}
+
+		in.Metadata[strings.TrimSpace(k)] = strings.TrimSpace(value)
+		return nil
+	})
+	fs.Func("tag", "tag of the records, repeatable", func(v string) error {
+		in.Tags = append(in.Tags, v)
+		return nil
+	})
+	fs.StringVar(&in.Folder, "folder", "", "path of the folder of the own records, / is the root")
+	fs.Func("created-after", "created at or after the time, RFC 3339 or YYYY-MM-DD", unixFlag(&in.CreatedAfter))
+	fs.Func("created-before", "created at or before the time, RFC 3339 or YYYY-MM-DD", unixFlag(&in.CreatedBefore))
+	fs.Func("updated-after", "updated at or after the time, RFC 3339 or YYYY-MM-DD", unixFlag(&in.UpdatedAfter))
+	fs.Func("updated-before", "updated at or before the time, RFC 3339 or YYYY-MM-DD", unixFlag(&in.UpdatedBefore))
+	limit := fs.Int("limit", 0, "print one page of the size")
+	fs.StringVar(&in.PageToken, "page", "", "token of the page to print")
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 {
+		return fmt.Errorf("%w: search takes no arguments, use the flags", ErrUsage)
+	}
+
+	if *limit < 0 {
+		return fmt.Errorf("%w: limit must be positive", ErrUsage)
+	}
+
+	in.PageSize = int32(*limit)
+	if *limit == 0 && in.PageToken == "" {
+		found, err := searchAll(client, in)
+		if err != nil {
+			return err
+		}
+
+		return printUnitList(s, found)
+	}
+
+	resp, err := client.SearchRecords(in)
+	if err != nil {
+		return fmt.Errorf("failed search files: %w", err)
+	}
+
+	if resp.NextPageToken != "" {
+		fmt.Fprintf(s.Err, "Next page: -page %s \n", resp.NextPageToken)
+	}
+
+	return printUnitList(s, resp.Units)
+}
+
+// searchAll returns the records of all pages of the search.
+func searchAll(client *client.Client, in *proto.SearchRecordsRequest) ([]*proto.StorageUnit, error) {
+	var found []*proto.StorageUnit
+	for {
+		resp, err := client.SearchRecords(in)
+		if err != nil {
+			return nil, fmt.Errorf("failed search files: %w", err)
+		}
+
+		found = append(found, resp.Units...)
+
+		if resp.NextPageToken == "" {
+			return found, nil
+		}
+		in.PageToken = resp.NextPageToken
+	}
+}
+
+// unixFlag parses the time of a flag into the Unix time.
+func unixFlag(sec *int64) func(string) error {
+	return func(v string) error {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			t, err = time.ParseInLocation(dateLayout, v, time.Local)
+		}
+		if err != nil {
+			return fmt.Errorf("expected RFC 3339 time or YYYY-MM-DD, got %q", v)
+		}
+
+		*sec = t.Unix()
+		return nil
+	}
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/client"
+	"github.com/dedpnd/GophKeeper/internal/record"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+)
+
+// secretCache resolves references to secrets, every record is read from the
+// server once.
+type secretCache struct {
+	client  *client.Client
+	records map[string]*proto.ReadRecordResponse
+}
+
+// UTILS FOR SECRETS.
+
+// newSecretCache returns an empty cache of the records of the client.
+func newSecretCache(client *client.Client) *secretCache {
+	return &secretCache{client: client, records: map[string]*proto.ReadRecordResponse{}}
+}
+
+// resolve returns the secret of the reference. The reference is the ID or
+// the name of a record, or the name followed by `/` and a field of the record.
+func (c *secretCache) resolve(ref string) (string, error) {
+	v, err := c.field(ref, "")
+	if !errors.Is(err, ErrNotFound) {
+		return v, err
+	}
+
+	name, field, ok := cutLast(ref, "/")
+	if !ok {
+		return "", err
+	}
+
+	return c.field(name, field)
+}
+
+// field returns the field of the record found by ID or name. An empty field
+// is the whole value of a record without fields.
+func (c *secretCache) field(ref string, field string) (string, error) {
+	rFile, err := c.record(ref)
+	if err != nil {
+		return "", err
+	}
+
+	if field == "" {
+		if record.Structured(rFile.Type) {
+			return "", fmt.Errorf("%w: file %s has fields, the field is required", ErrUsage, ref)
+		}
+
+		return string(rFile.Data), nil
+	}
+
+	values, err := record.Values(rFile.Type, rFile.Data)
+	if err != nil {
+		return "", fmt.Errorf("failed read fields: %w", err)
+	}
+
+	v, ok := values[field]
+	if !ok {
+		return "", fmt.Errorf("%w: field %q of file %s", ErrNotFound, field, ref)
+	}
+
+	return v, nil
+}
+
+// record returns the record found by ID or name.
+func (c *secretCache) record(ref string) (*proto.ReadRecordResponse, error) {
+	if rFile, ok := c.records[ref]; ok {
+		return rFile, nil
+	}
+
+	unit, err := findUnit(c.client, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	rFile, err := c.client.ReadFile(unit.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed get file: %w", err)
+	}
+
+	c.records[ref] = rFile
+
+	return rFile, nil
+}
+
+// cutLast slices the string around the last separator.
+func cutLast(s string, sep string) (string, string, bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+
+	return s[:i], s[i+len(sep):], true
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/client"
+)
+
+// UTILS FOR SHARING.
+
+// selectFileID shows the records and asks for the ID or name of one of them.
+func selectFileID(client *client.Client) (int32, error) {
+	rAllFile, err := client.ReadAllFile()
+	if err != nil {
+		return 0, fmt.Errorf("failed get all file: %w", err)
+	}
+
+	if len(rAllFile.Units) == 0 {
+		return 0, fmt.Errorf("not found files")
+	}
+
+	printUnits(rAllFile.Units)
+
+	i, err := selectReadFile(client)
+	if err != nil {
+		return 0, fmt.Errorf("wrong id file: %w", err)
+	}
+
+	return int32(i), nil
+}
+
+// shareFile shares the selected record with another user by login.
+func shareFile(client *client.Client) error {
+	id, err := selectFileID(client)
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+
+	login, err := readLine(reader, "Enter login of the user: ")
+	if err != nil {
+		return err
+	}
+
+	permission, err := readLine(reader, "Enter permission [read/write]: ")
+	if err != nil {
+		return err
+	}
+
+	_, err = client.ShareFile(id, login, permission)
+	if err != nil {
+		return fmt.Errorf("failed share file: %w", err)
+	}
+
+	fmt.Printf("File shared with %s! \n", login)
+
+	return nil
+}
+
+// unshareFile revokes the access of another user to the selected record.
+func unshareFile(client *client.Client) error {
+	id, err := selectFileID(client)
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+
+	login, err := readLine(reader, "Enter login of the user: ")
+	if err != nil {
+		return err
+	}
+
+	_, err = client.UnshareFile(id, login)
+	if err != nil {
+		return fmt.Errorf("failed unshare file: %w", err)
+	}
+
+	fmt.Printf("File unshared with %s! \n", login)
+
+	return nil
+}
+
+// listSharedFiles shows the records other users shared with the user.
+func listSharedFiles(client *client.Client) error {
+	r, err := client.ListSharedWithMe()
+	if err != nil {
+		return fmt.Errorf("failed get shared files: %w", err)
+	}
+
+	if len(r.Units) == 0 {
+		fmt.Println("Not found shared files.")
+		return nil
+	}
+
+	printUnits(r.Units)
+
+	return nil
+}
+
+// updateFile replaces the value of the selected record.
+func updateFile(client *client.Client) error {
+	id, err := selectFileID(client)
+	if err != nil {
+		return err
+	}
+
+	err = selectWriteData(client, id)
+	if err != nil {
+		return fmt.Errorf("select write data has error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,273 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/client"
+	"github.com/dedpnd/GophKeeper/internal/agent/config"
+	"github.com/dedpnd/GophKeeper/internal/record"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// socketPermition allows only the user to connect to the ssh-agent socket.
+var socketPermition os.FileMode = 0o600
+
+var errSignRefused = errors.New("use of the key is not confirmed")
+
+// UTILS FOR SSH.
+
+// runSSHAgent serves the ssh-agent protocol on a Unix socket with the SSH
+// keys found by IDs or names. The keys are kept in memory only. `-confirm`
+// asks before every use of a key, `-timeout` removes the keys and stops the
+// agent after the time.
+func runSSHAgent(client *client.Client, _ *config.ConfigENV, args []string, s *Streams) error {
+	fs := newFlagSet("ssh-agent", s)
+	socket := fs.String("socket", "", "path of the socket, a new temporary directory by default")
+	confirm := fs.Bool("confirm", false, "ask for confirmation before every use of a key")
+	timeout := fs.Duration("timeout", 0, "remove the keys and stop after the time, zero serves until interrupted")
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) == 0 {
+		return fmt.Errorf("%w: usage: ssh-agent [-socket path] [-confirm] [-timeout 1h] <id|name>...", ErrUsage)
+	}
+
+	if *timeout < 0 {
+		return fmt.Errorf("%w: timeout must be positive", ErrUsage)
+	}
+
+	if *confirm && !s.TTY {
+		return fmt.Errorf("%w: confirmation requires a terminal", ErrUsage)
+	}
+
+	keyring := agent.NewKeyring()
+	for _, ref := range pos {
+		err = loadSSHKey(client, keyring, ref, *timeout)
+		if err != nil {
+			return err
+		}
+	}
+
+	path, remove, err := sshSocketPath(*socket)
+	if err != nil {
+		return err
+	}
+	defer remove()
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return fmt.Errorf("failed listen socket: %w", err)
+	}
+	defer listener.Close() //nolint:errcheck // The socket is closed on stop
+
+	err = os.Chmod(path, socketPermition)
+	if err != nil {
+		return fmt.Errorf("failed protect socket: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	var served agent.Agent = keyring
+	if *confirm {
+		//nolint:forcetypeassert // The keyring implements the extended agent
+		served = &confirmAgent{ExtendedAgent: keyring.(agent.ExtendedAgent), s: s}
+	}
+
+	err = s.render(map[string]any{"socket": path, "keys": len(pos)}, func(w io.Writer) error {
+		fmt.Fprintf(w, "SSH_AUTH_SOCK=%s; export SSH_AUTH_SOCK;\n", path)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(s.Err, "SSH agent serves %v keys, press Ctrl+C to stop \n", len(pos))
+
+	err = serveSSHAgent(ctx, listener, served)
+
+	// Keys never outlive the agent
+	if errRemove := keyring.RemoveAll(); errRemove != nil && err == nil {
+		err = fmt.Errorf("failed remove keys: %w", errRemove)
+	}
+
+	fmt.Fprintln(s.Err, "SSH agent stopped.")
+
+	return err
+}
+
+// sshSocketPath returns the path of the socket. By default the socket is in a
+// new temporary directory which only the user can access, so other users
+// cannot connect before the socket is protected. The returned function
+// removes the directory.
+func sshSocketPath(path string) (string, func(), error) {
+	if path != "" {
+		return path, func() {}, nil
+	}
+
+	dir, err := os.MkdirTemp("", "gophkeeper-ssh-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed create socket directory: %w", err)
+	}
+
+	return filepath.Join(dir, "agent.sock"), func() { _ = os.RemoveAll(dir) }, nil
+}
+
+// serveSSHAgent serves the agent to the connections of the listener until
+// the context is done.
+func serveSSHAgent(ctx context.Context, listener net.Listener, served agent.Agent) error {
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed accept connection: %w", err)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Close() //nolint:errcheck // The client may close first
+
+			_ = agent.ServeAgent(served, conn)
+		}()
+
+		// Connections are closed with the agent
+		go func() {
+			<-ctx.Done()
+			_ = conn.Close()
+		}()
+	}
+}
+
+// loadSSHKey reads the SSH key record found by ID or name and adds its key
+// to the keyring. The key is removed after the timeout, if it is set.
+func loadSSHKey(client *client.Client, keyring agent.Agent, ref string, timeout time.Duration) error {
+	unit, err := findUnit(client, ref)
+	if err != nil {
+		return err
+	}
+
+	rFile, err := client.ReadFile(unit.Id)
+	if err != nil {
+		return fmt.Errorf("failed get file: %w", err)
+	}
+
+	if rFile.Type != record.SSHKey {
+		return fmt.Errorf("%w: file %s is not an ssh key", ErrUsage, unit.Name)
+	}
+
+	values, err := record.Values(rFile.Type, rFile.Data)
+	if err != nil {
+		return fmt.Errorf("failed read fields: %w", err)
+	}
+
+	key, err := record.SSHPrivateKey(values)
+	if err != nil {
+		return fmt.Errorf("failed read ssh key %s: %w", unit.Name, err)
+	}
+
+	err = keyring.Add(agent.AddedKey{
+		PrivateKey:   key,
+		Comment:      unit.Name,
+		LifetimeSecs: uint32(timeout.Seconds()),
+	})
+	if err != nil {
+		return fmt.Errorf("failed add ssh key %s: %w", unit.Name, err)
+	}
+
+	return nil
+}
+
+// sshMetadata returns the metadata of the record with the public key and the
+// fingerprint of the SSH key, the other attributes of an existing record are
+// kept.
+func sshMetadata(unit *proto.StorageUnit, data []byte) (map[string]string, error) {
+	metadata := map[string]string{}
+	if unit != nil {
+		maps.Copy(metadata, unit.Metadata)
+	}
+
+	derived, err := record.SSHMetadata(data)
+	if err != nil {
+		//nolint:wrapcheck // This legal return
+		return nil, err
+	}
+
+	maps.Copy(metadata, derived)
+
+	return metadata, nil
+}
+
+// confirmAgent asks the user before every signature made with a key. The
+// questions are asked one at a time.
+type confirmAgent struct {
+	agent.ExtendedAgent
+	s  *Streams
+	mu sync.Mutex
+}
+
+// Sign signs the data with the key if the user allows it.
+func (a *confirmAgent) Sign(key ssh.PublicKey, data []byte) (*ssh.Signature, error) {
+	return a.SignWithFlags(key, data, 0)
+}
+
+// SignWithFlags signs the data with the key and the flags if the user allows it.
+func (a *confirmAgent) SignWithFlags(key ssh.PublicKey, data []byte, flags agent.SignatureFlags) (*ssh.Signature, error) {
+	if !a.confirm(key) {
+		return nil, errSignRefused
+	}
+
+	//nolint:wrapcheck // This legal return
+	return a.ExtendedAgent.SignWithFlags(key, data, flags)
+}
+
+// confirm asks the user whether the key may be used.
+func (a *confirmAgent) confirm(key ssh.PublicKey) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	name := ssh.FingerprintSHA256(key)
+	if keys, err := a.List(); err == nil {
+		for _, v := range keys {
+			if bytes.Equal(v.Marshal(), key.Marshal()) {
+				name = v.Comment
+			}
+		}
+	}
+
+	answer, err := a.s.ask(fmt.Sprintf("Allow use of SSH key %s? [y/N]: ", name))
+	return err == nil && strings.EqualFold(strings.TrimSpace(answer), "y")
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func TestSSHSocketPath(t *testing.T) {
+	path, remove, err := sshSocketPath("")
+	assert.NoError(t, err)
+
+	info, err := os.Stat(filepath.Dir(path))
+	assert.NoError(t, err)
+	assert.True(t, info.IsDir())
+	assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+
+	remove()
+	_, err = os.Stat(filepath.Dir(path))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	path, _, err = sshSocketPath("/run/agent.sock")
+	assert.NoError(t, err)
+	assert.Equal(t, "/run/agent.sock", path)
+}
+
+func TestServeSSHAgent(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	keyring := agent.NewKeyring()
+	assert.NoError(t, keyring.Add(agent.AddedKey{PrivateKey: key, Comment: "test-key"}))
+
+	path := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := net.Listen("unix", path)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- serveSSHAgent(ctx, listener, keyring)
+	}()
+
+	conn, err := net.Dial("unix", path)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	client := agent.NewClient(conn)
+
+	t.Run("Served keys must be listed", func(t *testing.T) {
+		keys, err := client.List()
+		assert.NoError(t, err)
+		if assert.Len(t, keys, 1) {
+			assert.Equal(t, "test-key", keys[0].Comment)
+		}
+	})
+
+	t.Run("Signature must be verified by the public key", func(t *testing.T) {
+		keys, err := client.List()
+		assert.NoError(t, err)
+		if !assert.Len(t, keys, 1) {
+			return
+		}
+
+		data := []byte("data to sign")
+		sig, err := client.Sign(keys[0], data)
+		assert.NoError(t, err)
+
+		pub, err := ssh.ParsePublicKey(keys[0].Marshal())
+		assert.NoError(t, err)
+		assert.NoError(t, pub.Verify(data, sig))
+	})
+
+	t.Run("Stopped agent must close connections", func(t *testing.T) {
+		cancel()
+		assert.NoError(t, <-done)
+
+		_, err := client.List()
+		assert.Error(t, err)
+	})
+}
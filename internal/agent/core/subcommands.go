@@ -0,0 +1,437 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/client"
+	"github.com/dedpnd/GophKeeper/internal/agent/config"
+	"github.com/dedpnd/GophKeeper/internal/record"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+)
+
+// UTILS FOR SUBCOMMANDS.
+
+// runLogin signs in and prints the token. The password is read from stdin
+// with `-password-stdin`, otherwise it is asked for.
+func runLogin(client *client.Client, cfg *config.ConfigENV, args []string, s *Streams) error {
+	fs := newFlagSet("login", s)
+	user := fs.String("user", "", "login of the account")
+	passwordStdin := fs.Bool("password-stdin", false, "read the password from stdin")
+	save := fs.Bool("save", false, "save the token in .env")
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 {
+		return fmt.Errorf("%w: login takes no arguments", ErrUsage)
+	}
+
+	if *user == "" {
+		*user, err = s.ask("Enter your login: ")
+		if err != nil {
+			return err
+		}
+	}
+
+	var password string
+	if *passwordStdin {
+		password, err = s.readLine()
+	} else {
+		password, err = s.askPassword("Enter your password: ")
+	}
+	if err != nil {
+		return err
+	}
+
+	jwt, err := login(client, cfg.LegacyAuth, userCredentials{login: *user, password: password})
+	if err != nil {
+		if isUnavailable(err) {
+			return err
+		}
+		return fmt.Errorf("%w: %w", ErrAuth, err)
+	}
+
+	if *save {
+		err = writeAuthToken(jwt)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(s.Err, "Token saved in .env file.")
+	}
+
+	// Warn about the files to rotate
+	client.Token = jwt
+	warnExpiring(client, s.Err)
+
+	return s.render(map[string]string{"token": jwt}, func(w io.Writer) error {
+		fmt.Fprintln(w, jwt)
+		return nil
+	})
+}
+
+// runList prints the ID, type and name of every record, the table and the
+// structured formats show the owner, times and flags of records too. With
+// a prefix only the own records whose names start with it are printed.
+// `-sort` reads the records page by page in the sort order of the server.
+// `-folder` lists the own records of the folder, `-tag` lists the records
+// with all the tags.
+func runList(client *client.Client, _ *config.ConfigENV, args []string, s *Streams) error {
+	in := &proto.SearchRecordsRequest{}
+
+	fs := newFlagSet("ls", s)
+	sort := fs.String("sort", "", "sort by id, name, created or updated, prefix with - to reverse")
+	fs.StringVar(&in.Folder, "folder", "", "path of the folder, / is the root")
+	fs.Func("tag", "tag of the records, repeatable", func(v string) error {
+		in.Tags = append(in.Tags, v)
+		return nil
+	})
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	filtered := in.Folder != "" || len(in.Tags) != 0
+	if len(pos) > 1 || (len(pos) == 1 && (*sort != "" || filtered)) || (*sort != "" && filtered) {
+		return fmt.Errorf("%w: usage: ls [-sort order] | ls [-folder path] [-tag tag] | ls [prefix]", ErrUsage)
+	}
+
+	var found []*proto.StorageUnit
+	switch {
+	case filtered:
+		found, err = searchAll(client, in)
+		if err != nil {
+			return err
+		}
+	case len(pos) == 1:
+		found, err = client.FindRecordByName(pos[0], true)
+		if err != nil {
+			return fmt.Errorf("failed find file: %w", err)
+		}
+	case *sort != "":
+		it := client.IterateFiles(*sort, 0)
+		for it.Next() {
+			found = append(found, it.Unit())
+		}
+		if err = it.Err(); err != nil {
+			return fmt.Errorf("failed get all file: %w", err)
+		}
+	default:
+		rAllFile, err := client.ReadAllFile()
+		if err != nil {
+			return fmt.Errorf("failed get all file: %w", err)
+		}
+		found = rAllFile.Units
+	}
+
+	return printUnitList(s, found)
+}
+
+// runGet prints the value of the record found by ID or name, or writes it
+// to a file with `-out`. `-field` prints one field of a structured record.
+// The table and the structured formats show the record with decoded fields.
+func runGet(client *client.Client, _ *config.ConfigENV, args []string, s *Streams) error {
+	fs := newFlagSet("get", s)
+	out := fs.String("out", "", "write the value to the file")
+	field := fs.String("field", "", "print one field of a structured record")
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return fmt.Errorf("%w: usage: get <id|name>", ErrUsage)
+	}
+
+	unit, err := findUnit(client, pos[0])
+	if err != nil {
+		return err
+	}
+
+	rFile, err := client.ReadFile(unit.Id)
+	if err != nil {
+		return fmt.Errorf("failed get file: %w", err)
+	}
+
+	data := rFile.Data
+	if *field != "" {
+		values, err := record.Values(rFile.Type, rFile.Data)
+		if err != nil {
+			return fmt.Errorf("failed read fields: %w", err)
+		}
+
+		v, ok := values[*field]
+		if !ok {
+			return fmt.Errorf("%w: field %q", ErrNotFound, *field)
+		}
+
+		data = []byte(v)
+
+		if s.Output == OutputJSON || s.Output == OutputYAML {
+			return s.render(map[string]string{*field: v}, nil)
+		}
+	}
+
+	if *out != "" {
+		err = os.WriteFile(*out, data, defaultPermition)
+		if err != nil {
+			return fmt.Errorf("failed write data: %w", err)
+		}
+
+		fmt.Fprintf(s.Err, "File save in: %s \n", *out)
+		return nil
+	}
+
+	if *field == "" && s.Output != OutputText && s.Output != "" {
+		view := newRecordView(unit.Id, rFile)
+
+		return s.render(view, func(w io.Writer) error {
+			return writeTable(w, []string{"FIELD", "VALUE"}, recordRows(view))
+		})
+	}
+
+	// Values of files are written as is, so they can be redirected
+	if rFile.Type != record.File && !bytes.HasSuffix(data, []byte("\n")) {
+		data = append(data, '\n')
+	}
+
+	_, err = s.Out.Write(data)
+	if err != nil {
+		return fmt.Errorf("failed write data: %w", err)
+	}
+
+	return nil
+}
+
+// runPut writes the record with the name, the existing record with the name
+// is replaced. The value is read from `-file`, from stdin if it is piped
+// or `-file -` is given, otherwise it is asked for.
+func runPut(client *client.Client, _ *config.ConfigENV, args []string, s *Streams) error {
+	fs := newFlagSet("put", s)
+	typ := fs.String("type", "", "type of the record: text, file, login, card, otp, ssh")
+	name := fs.String("name", "", "name of the record")
+	file := fs.String("file", "", "read the value from the file, - for stdin")
+
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 {
+		return fmt.Errorf("%w: put takes no arguments, use -name", ErrUsage)
+	}
+
+	fromFile := *file != "" && *file != "-"
+	if *typ == "" {
+		*typ = record.Text
+		if fromFile {
+			*typ = record.File
+		}
+	}
+
+	if !record.Known(*typ) {
+		return fmt.Errorf("%w: unknown type %q", ErrUsage, *typ)
+	}
+
+	if *name == "" && *typ == record.File && fromFile {
+		*name = filepath.Base(*file)
+	}
+
+	if *name == "" {
+		*name, err = s.ask("Enter name: ")
+		if err != nil {
+			return err
+		}
+	}
+
+	var data []byte
+	switch {
+	case fromFile:
+		data, err = os.ReadFile(*file)
+		if err != nil {
+			return fmt.Errorf("failed read file: %w", err)
+		}
+	case *file == "-" || !s.TTY:
+		data, err = s.readAll()
+	default:
+		data, err = askValue(*typ, s)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *typ == record.Text {
+		data = bytes.TrimRight(data, "\r\n")
+	}
+
+	data, err = record.Parse(*typ, data)
+	if err != nil {
+		return fmt.Errorf("failed parse record: %w", err)
+	}
+
+	// The record with the same name is replaced
+	var id int32
+	unit, err := findUnitByName(client, *name)
+	switch {
+	case err == nil:
+		id = unit.Id
+	case !errors.Is(err, ErrNotFound):
+		return err
+	}
+
+	// The public key and the fingerprint of SSH keys are kept in metadata
+	var metadata map[string]string
+	if *typ == record.SSHKey {
+		metadata, err = sshMetadata(unit, data)
+		if err != nil {
+			return fmt.Errorf("failed parse record: %w", err)
+		}
+	}
+
+	resp, err := client.WriteDataWithMetadata(id, *typ, *name, data, metadata)
+	if err != nil {
+		return fmt.Errorf("failed write file: %w", err)
+	}
+
+	if resp.Conflict {
+		fmt.Fprintln(s.Err, "The file was changed on another device, your version is kept as a conflict.")
+	}
+
+	fmt.Fprintf(s.Err, "File write: %s \n", *name)
+
+	return s.render(map[string]any{"name": *name, "type": *typ, "conflict": resp.Conflict}, discard)
+}
+
+// runRemove deletes the record found by ID or name.
+func runRemove(client *client.Client, _ *config.ConfigENV, args []string, s *Streams) error {
+	pos, err := parseArgs(newFlagSet("rm", s), args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return fmt.Errorf("%w: usage: rm <id|name>", ErrUsage)
+	}
+
+	unit, err := findUnit(client, pos[0])
+	if err != nil {
+		return err
+	}
+
+	_, err = client.DeleteFile(unit.Id)
+	if err != nil {
+		return fmt.Errorf("failed delete file: %w", err)
+	}
+
+	fmt.Fprintf(s.Err, "File delete: %s \n", unit.Name)
+
+	return s.render(map[string]any{"id": unit.Id, "name": unit.Name}, discard)
+}
+
+// askValue asks for the value of the record, structured records are asked
+// for field by field. Secret fields are not echoed.
+func askValue(typ string, s *Streams) ([]byte, error) {
+	switch {
+	case record.Structured(typ):
+		values := map[string]string{}
+		for _, f := range record.Fields(typ) {
+			ask := s.ask
+			if f.Secret {
+				ask = s.askPassword
+			}
+
+			v, err := ask(f.Label + ": ")
+			if err != nil {
+				return nil, err
+			}
+
+			values[f.Name] = v
+		}
+
+		//nolint:wrapcheck // This legal return
+		return record.Encode(typ, values)
+	case typ == record.File:
+		path, err := s.ask("Enter the link to the file: ")
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed read file: %w", err)
+		}
+
+		return data, nil
+	default:
+		v, err := s.ask("Enter text: ")
+		if err != nil {
+			return nil, err
+		}
+
+		return []byte(v), nil
+	}
+}
+
+// findUnit finds the record by ID, or by name if the reference is not a number.
+func findUnit(client *client.Client, ref string) (*proto.StorageUnit, error) {
+	id, err := strconv.Atoi(ref)
+	if err != nil {
+		return findUnitByName(client, ref)
+	}
+
+	rAllFile, err := client.ReadAllFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed get all file: %w", err)
+	}
+
+	for _, v := range rAllFile.Units {
+		if v.Id == int32(id) {
+			return v, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: file %v", ErrNotFound, id)
+}
+
+// findUnitByName finds the own record by name. Records shared with the user
+// are looked up in the list of records, names of records of different owners
+// may repeat, so several shared records with the name are a usage error.
+func findUnitByName(client *client.Client, name string) (*proto.StorageUnit, error) {
+	units, err := client.FindRecordByName(name, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed find file: %w", err)
+	}
+
+	if len(units) != 0 {
+		return units[0], nil
+	}
+
+	rAllFile, err := client.ReadAllFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed get all file: %w", err)
+	}
+
+	var found *proto.StorageUnit
+	for _, v := range rAllFile.Units {
+		if !v.Shared || v.Name != name {
+			continue
+		}
+
+		if found != nil {
+			return nil, fmt.Errorf("%w: several files named %q, use the ID", ErrUsage, name)
+		}
+		found = v
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("%w: file %q", ErrNotFound, name)
+	}
+
+	return found, nil
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/client"
+)
+
+// UTILS FOR ACCESS TOKENS.
+
+// createAccessToken asks for the token settings and creates a personal access token.
+func createAccessToken(client *client.Client) error {
+	reader := bufio.NewReader(os.Stdin)
+
+	name, err := readLine(reader, "Enter token name: ")
+	if err != nil {
+		return err
+	}
+
+	scopes, err := readLine(reader, "Enter scopes (read,write,delete): ")
+	if err != nil {
+		return err
+	}
+
+	ids, err := readLine(reader, "Restrict to record IDs (comma separated, empty for all): ")
+	if err != nil {
+		return err
+	}
+
+	prefix, err := readLine(reader, "Restrict to name prefix (empty for all): ")
+	if err != nil {
+		return err
+	}
+
+	ttl, err := readLine(reader, "Expires in days (empty for never): ")
+	if err != nil {
+		return err
+	}
+
+	recordIDs := []int32{}
+	for _, v := range splitComma(ids) {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("failed parse int: %w", err)
+		}
+		recordIDs = append(recordIDs, int32(id))
+	}
+
+	ttlDays := 0
+	if ttl != "" {
+		ttlDays, err = strconv.Atoi(ttl)
+		if err != nil {
+			return fmt.Errorf("failed parse int: %w", err)
+		}
+	}
+
+	r, err := client.CreateAccessToken(name, splitComma(scopes), recordIDs, prefix, int32(ttlDays))
+	if err != nil {
+		return fmt.Errorf("failed create access token: %w", err)
+	}
+
+	fmt.Printf("Token [%v]: %s \n", r.Id, r.Token)
+	fmt.Println("Save it now, the token is shown only once.")
+
+	return nil
+}
+
+// listAccessTokens shows the personal access tokens of the user.
+func listAccessTokens(client *client.Client) error {
+	r, err := client.ListAccessTokens()
+	if err != nil {
+		return fmt.Errorf("failed get access tokens: %w", err)
+	}
+
+	if len(r.Tokens) == 0 {
+		fmt.Println("Not found tokens.")
+		return nil
+	}
+
+	for _, v := range r.Tokens {
+		state := "active"
+		if v.Revoked {
+			state = "revoked"
+		} else if v.ExpiresAt > 0 {
+			state = "expires " + time.Unix(v.ExpiresAt, 0).Format(time.DateOnly)
+		}
+
+		fmt.Printf("[%v] - %s (%s) %s \n", v.Id, v.Name, strings.Join(v.Scopes, ","), state)
+	}
+
+	return nil
+}
+
+// revokeAccessToken revokes the selected personal access token.
+func revokeAccessToken(client *client.Client) error {
+	err := listAccessTokens(client)
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+
+	r, err := readLine(reader, "Select ID token: ")
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(r)
+	if err != nil {
+		return fmt.Errorf("failed parse int: %w", err)
+	}
+
+	_, err = client.RevokeAccessToken(int32(id))
+	if err != nil {
+		return fmt.Errorf("failed revoke access token: %w", err)
+	}
+
+	fmt.Println("Token revoked!")
+
+	return nil
+}
+
+// splitComma splits a comma separated list, empty elements are skipped.
+func splitComma(s string) []string {
+	res := []string{}
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/client"
+	"github.com/dedpnd/GophKeeper/internal/agent/config"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+)
+
+// UTILS FOR TRASH.
+
+// runTrash prints the deleted records, the latest deleted first.
+func runTrash(client *client.Client, _ *config.ConfigENV, args []string, s *Streams) error {
+	pos, err := parseArgs(newFlagSet("trash", s), args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 {
+		return fmt.Errorf("%w: usage: trash", ErrUsage)
+	}
+
+	found, err := client.ListTrash()
+	if err != nil {
+		return fmt.Errorf("failed get trash: %w", err)
+	}
+
+	units := make([]unitView, 0, len(found))
+	for _, v := range found {
+		units = append(units, newUnitView(v))
+	}
+
+	return s.render(units, func(w io.Writer) error {
+		if s.Output == OutputTable {
+			rows := make([][]string, 0, len(units))
+			for _, v := range units {
+				rows = append(rows, []string{strconv.Itoa(int(v.ID)), v.Type, v.Name, v.DeletedAt})
+			}
+
+			return writeTable(w, []string{"ID", "TYPE", "NAME", "DELETED"}, rows)
+		}
+
+		for _, v := range units {
+			fmt.Fprintf(w, "%v\t%s\t%s\t%s\n", v.ID, v.Type, v.Name, v.DeletedAt)
+		}
+		return nil
+	})
+}
+
+// runRestore moves the deleted record found by ID or name back from the trash.
+func runRestore(client *client.Client, _ *config.ConfigENV, args []string, s *Streams) error {
+	pos, err := parseArgs(newFlagSet("restore", s), args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return fmt.Errorf("%w: usage: restore <id|name>", ErrUsage)
+	}
+
+	found, err := client.ListTrash()
+	if err != nil {
+		return fmt.Errorf("failed get trash: %w", err)
+	}
+
+	unit, err := findTrashUnit(found, pos[0])
+	if err != nil {
+		return err
+	}
+
+	err = client.RestoreRecord(unit.Id)
+	if err != nil {
+		return fmt.Errorf("failed restore file: %w", err)
+	}
+
+	fmt.Fprintf(s.Err, "File restore: %s \n", unit.Name)
+
+	return s.render(map[string]any{"id": unit.Id, "name": unit.Name}, discard)
+}
+
+// runEmptyTrash permanently removes the deleted records.
+func runEmptyTrash(client *client.Client, _ *config.ConfigENV, args []string, s *Streams) error {
+	pos, err := parseArgs(newFlagSet("empty-trash", s), args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 {
+		return fmt.Errorf("%w: usage: empty-trash", ErrUsage)
+	}
+
+	count, err := client.EmptyTrash()
+	if err != nil {
+		return fmt.Errorf("failed empty trash: %w", err)
+	}
+
+	fmt.Fprintf(s.Err, "Trash empty: %v files removed \n", count)
+
+	return s.render(map[string]any{"count": count}, discard)
+}
+
+// findTrashUnit returns the deleted record with the ID or the name. A name
+// may be shared by several deleted records, then the ID is required.
+func findTrashUnit(units []*proto.StorageUnit, ref string) (*proto.StorageUnit, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		for _, v := range units {
+			if int(v.Id) == id {
+				return v, nil
+			}
+		}
+	}
+
+	var found *proto.StorageUnit
+	for _, v := range units {
+		if v.Name != ref {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("%w: several deleted files are named %s, use the ID", ErrUsage, ref)
+		}
+		found = v
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("%w: deleted file %s", ErrNotFound, ref)
+	}
+
+	return found, nil
+}
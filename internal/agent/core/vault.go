@@ -0,0 +1,142 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/client"
+)
+
+// UTILS FOR TEAM VAULTS.
+
+// readOrgID asks for the ID of an organization.
+func readOrgID(reader *bufio.Reader) (int32, error) {
+	r, err := readLine(reader, "Enter organization ID: ")
+	if err != nil {
+		return 0, err
+	}
+
+	i, err := strconv.Atoi(r)
+	if err != nil {
+		return 0, fmt.Errorf("failed parse int: %w", err)
+	}
+
+	return int32(i), nil
+}
+
+// createOrganization creates a new organization owned by the user.
+func createOrganization(client *client.Client) error {
+	reader := bufio.NewReader(os.Stdin)
+
+	name, err := readLine(reader, "Enter organization name: ")
+	if err != nil {
+		return err
+	}
+
+	r, err := client.CreateOrganization(name)
+	if err != nil {
+		return fmt.Errorf("failed create organization: %w", err)
+	}
+
+	fmt.Printf("Organization created, ID: %v \n", r.Id)
+
+	return nil
+}
+
+// createVault creates a new vault in the organization.
+func createVault(client *client.Client) error {
+	reader := bufio.NewReader(os.Stdin)
+
+	orgID, err := readOrgID(reader)
+	if err != nil {
+		return err
+	}
+
+	name, err := readLine(reader, "Enter vault name: ")
+	if err != nil {
+		return err
+	}
+
+	r, err := client.CreateVault(orgID, name)
+	if err != nil {
+		return fmt.Errorf("failed create vault: %w", err)
+	}
+
+	fmt.Printf("Vault created, use it with -vault %v \n", r.Id)
+
+	return nil
+}
+
+// inviteMember adds a user to the organization with a role.
+func inviteMember(client *client.Client) error {
+	reader := bufio.NewReader(os.Stdin)
+
+	orgID, err := readOrgID(reader)
+	if err != nil {
+		return err
+	}
+
+	login, err := readLine(reader, "Enter login of the user: ")
+	if err != nil {
+		return err
+	}
+
+	role, err := readLine(reader, "Enter role [owner/admin/editor/viewer]: ")
+	if err != nil {
+		return err
+	}
+
+	_, err = client.AddMember(orgID, login, role)
+	if err != nil {
+		return fmt.Errorf("failed add member: %w", err)
+	}
+
+	fmt.Printf("%s is %s now! \n", login, role)
+
+	return nil
+}
+
+// removeMember removes a user from the organization.
+func removeMember(client *client.Client) error {
+	reader := bufio.NewReader(os.Stdin)
+
+	orgID, err := readOrgID(reader)
+	if err != nil {
+		return err
+	}
+
+	login, err := readLine(reader, "Enter login of the user: ")
+	if err != nil {
+		return err
+	}
+
+	_, err = client.RemoveMember(orgID, login)
+	if err != nil {
+		return fmt.Errorf("failed remove member: %w", err)
+	}
+
+	fmt.Printf("%s removed! \n", login)
+
+	return nil
+}
+
+// listVaults shows the vaults available to the user.
+func listVaults(client *client.Client) error {
+	r, err := client.ListVaults()
+	if err != nil {
+		return fmt.Errorf("failed get vaults: %w", err)
+	}
+
+	if len(r.Vaults) == 0 {
+		fmt.Println("Not found vaults.")
+		return nil
+	}
+
+	for _, v := range r.Vaults {
+		fmt.Printf("[%v] - %s (organization [%v] %s, %s) \n", v.Id, v.Name, v.OrgId, v.OrgName, v.Role)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/client"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+)
+
+// UTILS FOR WATCH.
+
+// watchFiles prints the changes of the records until the user interrupts
+// the agent. With the local cache the changes are synchronized into it.
+func watchFiles(client *client.Client) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var errSync error
+	err := client.Watch(ctx, func(event *proto.WatchEvent) {
+		fmt.Printf("[%s] %v - %s (revision %v) \n", event.Kind, event.Id, event.Name, event.Revision)
+
+		if client.Cache == nil || errSync != nil {
+			return
+		}
+
+		_, errSync = client.Sync()
+		if errSync != nil {
+			stop()
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed watch files: %w", err)
+	}
+
+	if errSync != nil {
+		return fmt.Errorf("failed sync files: %w", errSync)
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/dedpnd/GophKeeper/internal/record"
+)
+
+// Types of the Bitwarden items.
+const (
+	bitwardenLogin = 1
+	bitwardenNote  = 2
+	bitwardenCard  = 3
+)
+
+// bitwardenFile is the unencrypted JSON export of Bitwarden.
+type bitwardenFile struct {
+	Encrypted bool `json:"encrypted"`
+	Folders   []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"folders"`
+	Items []bitwardenItem `json:"items"`
+}
+
+// bitwardenItem is an item of the export, only the fields of its type are set.
+type bitwardenItem struct {
+	Type     int    `json:"type"`
+	Name     string `json:"name"`
+	Notes    string `json:"notes"`
+	FolderID string `json:"folderId"`
+	Login    *struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		TOTP     string `json:"totp"`
+		URIs     []struct {
+			URI string `json:"uri"`
+		} `json:"uris"`
+	} `json:"login"`
+	Card *struct {
+		CardholderName string `json:"cardholderName"`
+		Number         string `json:"number"`
+		ExpMonth       string `json:"expMonth"`
+		ExpYear        string `json:"expYear"`
+		Code           string `json:"code"`
+	} `json:"card"`
+}
+
+// BitwardenJSON converts the unencrypted JSON export of Bitwarden. Logins,
+// secure notes and cards are imported, the TOTP secrets of logins are OTP
+// records. Folders are the folders of the names. Identities are skipped, the
+// export has no attachments.
+func BitwardenJSON(r io.Reader) (*Result, error) {
+	var file bitwardenFile
+	err := json.NewDecoder(r).Decode(&file)
+	if err != nil {
+		return nil, fmt.Errorf("failed decode bitwarden json: %w", err)
+	}
+
+	if file.Encrypted {
+		return nil, fmt.Errorf("%w: encrypted bitwarden exports are not supported", record.ErrInvalid)
+	}
+
+	folders := map[string]string{}
+	for _, v := range file.Folders {
+		folders[v.ID] = v.Name
+	}
+
+	res := &Result{}
+	for _, v := range file.Items {
+		name := joinName(folders[v.FolderID], v.Name)
+
+		switch {
+		case v.Type == bitwardenLogin && v.Login != nil:
+			values := map[string]string{
+				"username": v.Login.Username,
+				"password": v.Login.Password,
+				"notes":    v.Notes,
+			}
+			if len(v.Login.URIs) != 0 {
+				values["url"] = v.Login.URIs[0].URI
+			}
+
+			res.addLogin(name, values)
+
+			if v.Login.TOTP != "" {
+				res.addOTP(name, v.Login.TOTP)
+			}
+		case v.Type == bitwardenNote:
+			res.addText(name, v.Notes)
+		case v.Type == bitwardenCard && v.Card != nil:
+			values := map[string]string{
+				"number": v.Card.Number,
+				"holder": v.Card.CardholderName,
+				"cvv":    v.Card.Code,
+			}
+			if v.Card.ExpMonth != "" && v.Card.ExpYear != "" {
+				month, year := v.Card.ExpMonth, v.Card.ExpYear
+				if len(month) == 1 {
+					month = "0" + month
+				}
+				values["expiry"] = month + "/" + year[max(len(year)-2, 0):]
+			}
+
+			res.add(record.Card, name, values)
+		default:
+			res.skip(name, fmt.Sprintf("unsupported item type %v", v.Type))
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,173 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dedpnd/GophKeeper/internal/record"
+)
+
+// Attributes of the CSV rows which are not fields of the records.
+const (
+	csvName   = "name"
+	csvFolder = "folder"
+	csvOTP    = "otp"
+)
+
+// csvAliases are the columns of the usual CSV exports for the fields, the
+// columns are compared in lower case.
+var csvAliases = map[string][]string{
+	csvName:    {"name", "title"},
+	csvFolder:  {"folder", "group", "grouping"},
+	csvOTP:     {"otp", "totp", "login_totp"},
+	"username": {"username", "user", "login", "login_username"},
+	"password": {"password", "login_password"},
+	"url":      {"url", "uri", "login_uri", "website"},
+	"notes":    {"notes", "note", "extra", "comments"},
+	"number":   {"number", "card_number"},
+	"holder":   {"holder", "cardholder", "card_holder"},
+	"expiry":   {"expiry", "expiration"},
+	"cvv":      {"cvv", "code", "card_code"},
+}
+
+// CSV converts the rows of the CSV file with a header into records of the
+// type. `mapping` maps the fields of the records, `name`, `folder` and `otp`
+// to the columns, the columns of the other fields are found by their usual
+// names. The notes are the value of text records.
+func CSV(r io.Reader, typ string, mapping map[string]string) (*Result, error) {
+	if typ != record.Text && !record.Structured(typ) {
+		return nil, fmt.Errorf("%w: type %q can not be imported from csv", record.ErrInvalid, typ)
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed read csv header: %w", err)
+	}
+
+	columns, err := csvColumns(header, mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := []string{"notes"}
+	if typ != record.Text {
+		fields = fields[:0]
+		for _, f := range record.Fields(typ) {
+			fields = append(fields, f.Name)
+		}
+	}
+
+	known := map[string]bool{csvName: true, csvFolder: true, csvOTP: true}
+	for _, f := range fields {
+		known[f] = true
+	}
+	for k := range mapping {
+		if !known[k] {
+			return nil, fmt.Errorf("%w: unknown field %q of %s", record.ErrInvalid, k, typ)
+		}
+	}
+
+	res := &Result{}
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed read csv: %w", err)
+		}
+
+		cell := func(k string) string {
+			i, ok := columns[k]
+			if !ok || i >= len(row) {
+				return ""
+			}
+			return row[i]
+		}
+
+		name := cell(csvName)
+		if strings.TrimSpace(name) == "" {
+			name = fmt.Sprintf("line %v", line)
+		}
+		name = joinName(cell(csvFolder), name)
+
+		values := map[string]string{}
+		for _, f := range fields {
+			values[f] = cell(f)
+		}
+
+		switch typ {
+		case record.Text:
+			res.addText(name, values["notes"])
+		case record.Login:
+			res.addLogin(name, values)
+		default:
+			res.add(typ, name, values)
+		}
+
+		if otp := cell(csvOTP); otp != "" && typ != record.OTP {
+			res.addOTP(name, otp)
+		}
+	}
+
+	return res, nil
+}
+
+// ParseMapping parses the mapping of the fields to the columns given as
+// `field=column` pairs separated by commas.
+func ParseMapping(s string) (map[string]string, error) {
+	mapping := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(k) == "" || strings.TrimSpace(v) == "" {
+			return nil, fmt.Errorf("%w: expected field=column, got %q", record.ErrInvalid, pair)
+		}
+
+		mapping[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+
+	return mapping, nil
+}
+
+// csvColumns returns the indexes of the columns of the fields. The mapped
+// columns must be in the header.
+func csvColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	index := map[string]int{}
+	for i, v := range header {
+		k := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(v, "\ufeff")))
+		if _, ok := index[k]; !ok {
+			index[k] = i
+		}
+	}
+
+	columns := map[string]int{}
+	for field, aliases := range csvAliases {
+		for _, v := range aliases {
+			if i, ok := index[v]; ok {
+				columns[field] = i
+				break
+			}
+		}
+	}
+
+	for field, column := range mapping {
+		i, ok := index[strings.ToLower(column)]
+		if !ok {
+			return nil, fmt.Errorf("%w: column %q of field %q is not in the header", record.ErrInvalid, column, field)
+		}
+
+		columns[field] = i
+	}
+
+	return columns, nil
+}
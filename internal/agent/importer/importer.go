@@ -0,0 +1,112 @@
+// Package importer converts the exports of other password managers into
+// records. Entries which can not be converted are reported as skipped, the
+// import goes on without them.
+package importer
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/dedpnd/GophKeeper/internal/record"
+)
+
+// Item is a record converted from an entry, `Data` is in the stored form of
+// the type. `Folder` is the path of the folder, `ExpiresAt` is the expiry
+// date in Unix time, the formats of other password managers leave them and
+// the metadata empty.
+type Item struct {
+	Type       string
+	Name       string
+	Data       []byte
+	Metadata   map[string]string
+	Folder     string
+	Tags       []string
+	ExpiresAt  int64
+	RotateDays int32
+}
+
+// Skipped is an entry which is not imported and the reason.
+type Skipped struct {
+	Name   string `json:"name"   yaml:"name"`
+	Reason string `json:"reason" yaml:"reason"`
+}
+
+// Result is the records converted from the export and the skipped entries.
+type Result struct {
+	Items   []Item
+	Skipped []Skipped
+}
+
+// add converts the fields of the entry into a record of the type, the entry
+// is skipped if the fields do not match the type.
+func (r *Result) add(typ string, name string, values map[string]string) {
+	data, err := record.Encode(typ, values)
+	if err != nil {
+		r.skip(name, err.Error())
+		return
+	}
+
+	r.Items = append(r.Items, Item{Type: typ, Name: name, Data: data})
+}
+
+// addText adds a text record, empty texts are skipped.
+func (r *Result) addText(name string, text string) {
+	if strings.TrimSpace(text) == "" {
+		r.skip(name, "empty entry")
+		return
+	}
+
+	r.Items = append(r.Items, Item{Type: record.Text, Name: name, Data: []byte(text)})
+}
+
+// addFile adds a file record of an attachment.
+func (r *Result) addFile(name string, data []byte) {
+	r.Items = append(r.Items, Item{Type: record.File, Name: name, Data: data})
+}
+
+// addOTP adds an OTP record of the TOTP secret of a login. The secret is
+// either an otpauth:// URI or a base32 key.
+func (r *Result) addOTP(name string, secret string) {
+	uri := strings.TrimSpace(secret)
+	if !strings.HasPrefix(uri, "otpauth://") {
+		uri = fmt.Sprintf("otpauth://totp/%s?secret=%s", url.PathEscape(name), url.QueryEscape(strings.ReplaceAll(uri, " ", "")))
+	}
+
+	r.add(record.OTP, name+"/otp", map[string]string{"uri": uri})
+}
+
+// skip reports the entry as skipped.
+func (r *Result) skip(name string, reason string) {
+	r.Skipped = append(r.Skipped, Skipped{Name: name, Reason: reason})
+}
+
+// addLogin adds the entry as a login, an entry without a password but with
+// notes is a text record.
+func (r *Result) addLogin(name string, values map[string]string) {
+	if strings.TrimSpace(values["password"]) == "" {
+		if strings.TrimSpace(values["notes"]) != "" {
+			r.addText(name, values["notes"])
+			return
+		}
+
+		r.skip(name, "no password")
+		return
+	}
+
+	r.add(record.Login, name, values)
+}
+
+// joinName returns the name of the entry in the folder.
+func joinName(folder string, name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "untitled"
+	}
+
+	if folder == "" {
+		return name
+	}
+
+	return folder + "/" + name
+}
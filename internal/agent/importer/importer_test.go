@@ -0,0 +1,176 @@
+package importer
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dedpnd/GophKeeper/internal/record"
+)
+
+// readFixture converts the fixture of the testdata directory.
+func readFixture(t *testing.T, name string, convert func(r io.Reader) (*Result, error)) *Result {
+	t.Helper()
+
+	f, err := os.Open("testdata/" + name)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	res, err := convert(f)
+	assert.NoError(t, err)
+
+	return res
+}
+
+// findItem returns the item with the name, nil if there is none.
+func findItem(res *Result, name string) *Item {
+	for i := range res.Items {
+		if res.Items[i].Name == name {
+			return &res.Items[i]
+		}
+	}
+
+	return nil
+}
+
+func TestKeePassXML(t *testing.T) {
+	res := readFixture(t, "keepass.xml", KeePassXML)
+
+	tests := []struct {
+		name string
+		item string
+		typ  string
+		data string
+		// values are the fields of a structured record
+		values map[string]string
+	}{
+		{
+			name: "Entry of the root group must be a login without folder", item: "Mail", typ: record.Login,
+			values: map[string]string{"password": "secret", "url": "https://mail.example.com", "username": "alice"},
+		},
+		{name: "Entry with only notes must be a text", item: "Memo", typ: record.Text, data: "only notes"},
+		{
+			name: "Entry of nested groups must be in their folder", item: "Work/Servers/db", typ: record.Login,
+			values: map[string]string{"password": "hunter2", "username": "postgres"},
+		},
+		{
+			name: "OTP field must be an OTP record", item: "Work/Servers/db/otp", typ: record.OTP,
+			values: map[string]string{"uri": "otpauth://totp/db?secret=JBSWY3DPEHPK3PXP"},
+		},
+		{
+			name: "Compressed attachment by reference must be decompressed", item: "Work/Servers/db/ref-compressed.txt",
+			typ: record.File, data: "compressed attachment",
+		},
+		{name: "Attachment by reference must be decoded", item: "Work/Servers/db/ref.txt", typ: record.File, data: "plain attachment"},
+		{name: "Inline attachment must be decoded", item: "Work/Servers/db/inline.txt", typ: record.File, data: "inline attachment"},
+		{
+			name: "Compressed inline attachment must be decompressed", item: "Work/Servers/db/inline-compressed.txt",
+			typ: record.File, data: "compressed attachment",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := findItem(res, tt.item)
+			if !assert.NotNil(t, item) {
+				return
+			}
+
+			assert.Equal(t, tt.typ, item.Type)
+			if tt.values == nil {
+				assert.Equal(t, tt.data, string(item.Data))
+				return
+			}
+
+			values, err := record.Values(item.Type, item.Data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.values, values)
+		})
+	}
+
+	t.Run("Recycle bin must be left out", func(t *testing.T) {
+		assert.Nil(t, findItem(res, "Recycle Bin/Deleted"))
+		assert.Len(t, res.Items, len(tests))
+	})
+
+	t.Run("Entries which can not be converted must be skipped", func(t *testing.T) {
+		assert.Equal(t, []Skipped{
+			{Name: "Blank", Reason: "no password"},
+			{Name: "Work/Servers/db/missing.txt", Reason: "attachment not found"},
+		}, res.Skipped)
+	})
+}
+
+func TestBitwardenJSON(t *testing.T) {
+	res := readFixture(t, "bitwarden.json", BitwardenJSON)
+
+	tests := []struct {
+		name string
+		item string
+		typ  string
+		data string
+		// values are the fields of a structured record
+		values map[string]string
+	}{
+		{
+			name: "Login must be in its folder with the first URI", item: "Personal/GitHub", typ: record.Login,
+			values: map[string]string{"notes": "work account", "password": "secret", "url": "https://github.com", "username": "alice"},
+		},
+		{
+			name: "TOTP key must be an OTP URI", item: "Personal/GitHub/otp", typ: record.OTP,
+			values: map[string]string{"uri": "otpauth://totp/Personal%2FGitHub?secret=JBSWY3DPEHPK3PXP"},
+		},
+		{
+			name: "Login without folder must be at the root", item: "Bank", typ: record.Login,
+			values: map[string]string{"password": "pin"},
+		},
+		{
+			name: "TOTP URI must be kept", item: "Bank/otp", typ: record.OTP,
+			values: map[string]string{"uri": "otpauth://totp/Bank:alice?secret=JBSWY3DPEHPK3PXP&issuer=Bank"},
+		},
+		{name: "Secure note must be a text", item: "Personal/Wifi", typ: record.Text, data: "guest / guest"},
+		{
+			name: "Card expiry must be MM/YY", item: "Visa", typ: record.Card,
+			values: map[string]string{"cvv": "123", "expiry": "03/31", "holder": "Alice", "number": "4111111111111111"},
+		},
+		{
+			name: "Card expiry with short year must be kept", item: "Mastercard", typ: record.Card,
+			values: map[string]string{"expiry": "11/29", "number": "5555555555554444"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := findItem(res, tt.item)
+			if !assert.NotNil(t, item) {
+				return
+			}
+
+			assert.Equal(t, tt.typ, item.Type)
+			if tt.values == nil {
+				assert.Equal(t, tt.data, string(item.Data))
+				return
+			}
+
+			values, err := record.Values(item.Type, item.Data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.values, values)
+		})
+	}
+
+	t.Run("Unsupported and empty items must be skipped", func(t *testing.T) {
+		assert.Len(t, res.Items, len(tests))
+		assert.Equal(t, []Skipped{
+			{Name: "Empty", Reason: "empty entry"},
+			{Name: "Personal/Passport", Reason: "unsupported item type 4"},
+		}, res.Skipped)
+	})
+
+	t.Run("Encrypted export must be rejected", func(t *testing.T) {
+		_, err := BitwardenJSON(strings.NewReader(`{"encrypted": true, "items": []}`))
+		assert.ErrorIs(t, err, record.ErrInvalid)
+	})
+}
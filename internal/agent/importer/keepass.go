@@ -0,0 +1,161 @@
+package importer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// keePassFile is the XML export of KeePass 2.
+type keePassFile struct {
+	Meta struct {
+		RecycleBinUUID string          `xml:"RecycleBinUUID"`
+		Binaries       []keePassBinary `xml:"Binaries>Binary"`
+	} `xml:"Meta"`
+	Root struct {
+		Groups []keePassGroup `xml:"Group"`
+	} `xml:"Root"`
+}
+
+// keePassBinary is an attachment kept in the metadata of the file.
+type keePassBinary struct {
+	ID         string `xml:"ID,attr"`
+	Compressed bool   `xml:"Compressed,attr"`
+	Data       string `xml:",chardata"`
+}
+
+// keePassGroup is a group of entries, groups are nested.
+type keePassGroup struct {
+	UUID    string         `xml:"UUID"`
+	Name    string         `xml:"Name"`
+	Entries []keePassEntry `xml:"Entry"`
+	Groups  []keePassGroup `xml:"Group"`
+}
+
+// keePassEntry is an entry with its string fields and attachments. The
+// history of the entry is not imported.
+type keePassEntry struct {
+	Strings []struct {
+		Key   string `xml:"Key"`
+		Value string `xml:"Value"`
+	} `xml:"String"`
+	Binaries []struct {
+		Key   string `xml:"Key"`
+		Value struct {
+			Ref        string `xml:"Ref,attr"`
+			Compressed bool   `xml:"Compressed,attr"`
+			Data       string `xml:",chardata"`
+		} `xml:"Value"`
+	} `xml:"Binary"`
+}
+
+// KeePassXML converts the XML export of KeePass 2. Entries are logins, or
+// text records if they have only notes, attachments are file records named
+// after the entry. Groups are the folders of the names, the root group and
+// the recycle bin are left out.
+func KeePassXML(r io.Reader) (*Result, error) {
+	var file keePassFile
+	err := xml.NewDecoder(r).Decode(&file)
+	if err != nil {
+		return nil, fmt.Errorf("failed decode keepass xml: %w", err)
+	}
+
+	binaries := map[string]keePassBinary{}
+	for _, v := range file.Meta.Binaries {
+		binaries[v.ID] = v
+	}
+
+	res := &Result{}
+
+	var walk func(g keePassGroup, folder string)
+	walk = func(g keePassGroup, folder string) {
+		if g.UUID != "" && g.UUID == file.Meta.RecycleBinUUID {
+			return
+		}
+
+		for _, e := range g.Entries {
+			res.addKeePassEntry(e, folder, binaries)
+		}
+
+		for _, v := range g.Groups {
+			walk(v, joinName(folder, v.Name))
+		}
+	}
+
+	for _, root := range file.Root.Groups {
+		walk(root, "")
+	}
+
+	return res, nil
+}
+
+// addKeePassEntry converts the entry and its attachments.
+func (r *Result) addKeePassEntry(e keePassEntry, folder string, binaries map[string]keePassBinary) {
+	fields := map[string]string{}
+	for _, v := range e.Strings {
+		fields[v.Key] = v.Value
+	}
+
+	name := joinName(folder, fields["Title"])
+
+	r.addLogin(name, map[string]string{
+		"username": fields["UserName"],
+		"password": fields["Password"],
+		"url":      fields["URL"],
+		"notes":    fields["Notes"],
+	})
+
+	if otp := fields["otp"]; otp != "" {
+		r.addOTP(name, otp)
+	}
+
+	for _, v := range e.Binaries {
+		attachment := name + "/" + v.Key
+
+		bin := keePassBinary{Compressed: v.Value.Compressed, Data: v.Value.Data}
+		if v.Value.Ref != "" {
+			var ok bool
+			bin, ok = binaries[v.Value.Ref]
+			if !ok {
+				r.skip(attachment, "attachment not found")
+				continue
+			}
+		}
+
+		data, err := bin.decode()
+		if err != nil {
+			r.skip(attachment, err.Error())
+			continue
+		}
+
+		r.addFile(attachment, data)
+	}
+}
+
+// decode returns the content of the attachment.
+func (b keePassBinary) decode() ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(b.Data))
+	if err != nil {
+		return nil, fmt.Errorf("failed decode attachment: %w", err)
+	}
+
+	if !b.Compressed {
+		return data, nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed decompress attachment: %w", err)
+	}
+
+	data, err = io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("failed decompress attachment: %w", err)
+	}
+
+	return data, nil
+}
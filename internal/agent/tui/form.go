@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dedpnd/GophKeeper/internal/record"
+)
+
+// Name of the field of a note, notes are text records.
+const noteField = "text"
+
+// formField is an editable field of the form.
+type formField struct {
+	name   string
+	label  string
+	secret bool
+	value  []rune
+}
+
+// form creates or edits a record. The first field is the name of the record,
+// the rest are the fields of its type.
+type form struct {
+	id     int32
+	typ    string
+	fields []*formField
+	focus  int
+}
+
+// newForm creates the form of the type with the values of the record. The
+// record is new if `id` is zero.
+func newForm(id int32, typ string, name string, values map[string]string) *form {
+	f := &form{
+		id:     id,
+		typ:    typ,
+		fields: []*formField{{name: "name", label: "Name", value: []rune(name)}},
+	}
+
+	if !record.Structured(typ) {
+		f.fields = append(f.fields, &formField{name: noteField, label: "Text", value: []rune(values[noteField])})
+		return f
+	}
+
+	for _, v := range record.Fields(typ) {
+		f.fields = append(f.fields, &formField{
+			name:   v.Name,
+			label:  v.Label,
+			secret: v.Secret,
+			value:  []rune(values[v.Name]),
+		})
+	}
+
+	return f
+}
+
+// title returns the title of the form.
+func (f *form) title() string {
+	if f.id == 0 {
+		return "New " + f.typ
+	}
+
+	return fmt.Sprintf("Edit %s %v", f.typ, f.id)
+}
+
+// move moves the focus by `n` fields, the focus wraps around.
+func (f *form) move(n int) {
+	f.focus = (f.focus + n + len(f.fields)) % len(f.fields)
+}
+
+// input edits the focused field with the key.
+func (f *form) input(k key) {
+	field := f.fields[f.focus]
+
+	switch k.code {
+	case keyRune:
+		field.value = append(field.value, k.r)
+	case keyBackspace:
+		if len(field.value) > 0 {
+			field.value = field.value[:len(field.value)-1]
+		}
+	default:
+	}
+}
+
+// name returns the name of the record.
+func (f *form) name() string {
+	return strings.TrimSpace(string(f.fields[0].value))
+}
+
+// data checks the fields and returns the value of the record in the stored form.
+func (f *form) data() ([]byte, error) {
+	if f.name() == "" {
+		return nil, fmt.Errorf("%w: name is required", record.ErrInvalid)
+	}
+
+	values := map[string]string{}
+	for _, v := range f.fields[1:] {
+		values[v.name] = string(v.value)
+	}
+
+	if !record.Structured(f.typ) {
+		return []byte(values[noteField]), nil
+	}
+
+	//nolint:wrapcheck // This legal return
+	return record.Encode(f.typ, values)
+}
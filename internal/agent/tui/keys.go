@@ -0,0 +1,107 @@
+package tui
+
+import "unicode/utf8"
+
+// keyCode is a special key, printable characters have the code keyRune.
+type keyCode int
+
+const (
+	keyRune keyCode = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyEnter
+	keyTab
+	keyBacktab
+	keyBackspace
+	keyEsc
+	keyCtrlC
+	keyCtrlR
+	keyCtrlS
+	keyPageUp
+	keyPageDown
+	keyUnknown
+)
+
+// key is a pressed key.
+type key struct {
+	code keyCode
+	r    rune
+}
+
+// escapes maps the escape sequences of the terminal to keys.
+var escapes = map[string]keyCode{
+	"\x1b[A":  keyUp,
+	"\x1b[B":  keyDown,
+	"\x1b[C":  keyRight,
+	"\x1b[D":  keyLeft,
+	"\x1bOA":  keyUp,
+	"\x1bOB":  keyDown,
+	"\x1bOC":  keyRight,
+	"\x1bOD":  keyLeft,
+	"\x1b[Z":  keyBacktab,
+	"\x1b[5~": keyPageUp,
+	"\x1b[6~": keyPageDown,
+}
+
+// parseKeys splits the input read from the terminal into keys. One read may
+// hold several keys, for example when text is pasted.
+func parseKeys(b []byte) []key {
+	var keys []key
+	for len(b) > 0 {
+		k, n := parseKey(b)
+		keys = append(keys, k)
+		b = b[n:]
+	}
+
+	return keys
+}
+
+// parseKey returns the first key of the input and its length.
+func parseKey(b []byte) (key, int) {
+	switch b[0] {
+	case '\r', '\n':
+		return key{code: keyEnter}, 1
+	case '\t':
+		return key{code: keyTab}, 1
+	case 0x7f, 0x08:
+		return key{code: keyBackspace}, 1
+	case 0x03:
+		return key{code: keyCtrlC}, 1
+	case 0x12:
+		return key{code: keyCtrlR}, 1
+	case 0x13:
+		return key{code: keyCtrlS}, 1
+	case 0x1b:
+		return parseEscape(b)
+	}
+
+	if b[0] < 0x20 {
+		return key{code: keyUnknown}, 1
+	}
+
+	r, n := utf8.DecodeRune(b)
+
+	return key{code: keyRune, r: r}, n
+}
+
+// parseEscape parses the escape sequence, a lone escape is the Esc key.
+func parseEscape(b []byte) (key, int) {
+	if len(b) == 1 || (b[1] != '[' && b[1] != 'O') {
+		return key{code: keyEsc}, 1
+	}
+
+	// The sequence ends with a letter or a tilde
+	for i := 2; i < len(b); i++ {
+		c := b[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || c == '~' {
+			if code, ok := escapes[string(b[:i+1])]; ok {
+				return key{code: code}, i + 1
+			}
+			return key{code: keyUnknown}, i + 1
+		}
+	}
+
+	return key{code: keyUnknown}, len(b)
+}
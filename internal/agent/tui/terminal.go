@@ -0,0 +1,51 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// terminal is the terminal switched to the raw mode, keys are read without
+// echo and line buffering.
+type terminal struct {
+	fd    int
+	state *term.State
+}
+
+// openTerminal switches the terminal of the file to the raw mode.
+func openTerminal(f *os.File) (*terminal, error) {
+	fd := int(f.Fd())
+
+	if !term.IsTerminal(fd) {
+		return nil, ErrNoTerminal
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNoTerminal, err)
+	}
+
+	return &terminal{fd: fd, state: state}, nil
+}
+
+// restore returns the terminal to the mode it had before.
+func (t *terminal) restore() error {
+	err := term.Restore(t.fd, t.state)
+	if err != nil {
+		return fmt.Errorf("failed restore terminal: %w", err)
+	}
+
+	return nil
+}
+
+// size returns the width and height of the terminal.
+func (t *terminal) size() (int, int, error) {
+	width, height, err := term.GetSize(t.fd)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed get terminal size: %w", err)
+	}
+
+	return width, height, nil
+}
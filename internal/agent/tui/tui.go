@@ -0,0 +1,670 @@
+// Package tui is the full-screen terminal interface of the agent. It shows a
+// searchable list of records next to the details of the selected one, secret
+// fields are masked until revealed. Logins, cards and notes are created and
+// edited in forms. The interface works on top of the methods of
+// `client.Client`, so the local cache is used the same way as by commands.
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dedpnd/GophKeeper/internal/agent/client"
+	"github.com/dedpnd/GophKeeper/internal/record"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+)
+
+// ErrNoTerminal is returned if the input is not a terminal.
+var ErrNoTerminal = errors.New("terminal is required")
+
+// mode is the state of the interface, keys are handled depending on it.
+type mode int
+
+const (
+	modeList mode = iota
+	modeSearch
+	modeNew
+	modeForm
+	modeConfirm
+)
+
+const (
+	mask          = "••••••••"
+	minListWidth  = 24
+	separator     = " │ "
+	readBufferLen = 256
+)
+
+// Control sequences of the terminal.
+const (
+	seqEnter   = "\x1b[?1049h\x1b[?25l"
+	seqLeave   = "\x1b[?25h\x1b[?1049l"
+	seqHome    = "\x1b[H"
+	seqClear   = "\x1b[K"
+	seqClearTo = "\x1b[J"
+	seqReverse = "\x1b[7m"
+	seqBold    = "\x1b[1m"
+	seqReset   = "\x1b[0m"
+)
+
+// App is the state of the interface. Keys are passed to `Input`, the screen
+// is drawn by `Render`.
+type App struct {
+	client  *client.Client
+	units   []*proto.StorageUnit
+	visible []*proto.StorageUnit
+	details map[int32]*proto.ReadRecordResponse
+	filter  []rune
+	cursor  int
+	offset  int
+	reveal  bool
+	mode    mode
+	form    *form
+	status  string
+}
+
+// NewApp creates the interface and reads the list of records.
+func NewApp(client *client.Client) (*App, error) {
+	a := &App{client: client}
+
+	err := a.refresh()
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Run shows the interface in the terminal until the user quits.
+func Run(client *client.Client) error {
+	term, err := openTerminal(os.Stdin)
+	if err != nil {
+		return err
+	}
+	defer term.restore() //nolint:errcheck // The terminal is restored on a best-effort basis
+
+	a, err := NewApp(client)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(os.Stdout, seqEnter)
+	defer fmt.Fprint(os.Stdout, seqLeave)
+
+	buf := make([]byte, readBufferLen)
+	for {
+		width, height, err := term.size()
+		if err != nil {
+			return err
+		}
+
+		_, err = io.WriteString(os.Stdout, a.Render(width, height))
+		if err != nil {
+			return fmt.Errorf("failed draw screen: %w", err)
+		}
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return fmt.Errorf("failed read stdin: %w", err)
+		}
+
+		if !a.Input(buf[:n]) {
+			return nil
+		}
+	}
+}
+
+// Input handles the keys read from the terminal. It returns false when the
+// user quits.
+func (a *App) Input(b []byte) bool {
+	for _, k := range parseKeys(b) {
+		if k.code == keyCtrlC {
+			return false
+		}
+
+		if !a.handle(k) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Render draws the screen of the size. Lines end with CRLF, because the
+// terminal in the raw mode does not translate line feeds.
+func (a *App) Render(width, height int) string {
+	var lines []string
+
+	title := fmt.Sprintf("%sGophKeeper%s  %v records", seqBold, seqReset, len(a.visible))
+	if a.mode == modeSearch || len(a.filter) != 0 {
+		title += "  search: " + sanitize(string(a.filter))
+		if a.mode == modeSearch {
+			title += "_"
+		}
+	}
+	lines = append(lines, title, "")
+
+	bodyHeight := max(height-len(lines)-2, 1) //nolint:gomnd // Status and help lines
+	listWidth := max(width*2/5, minListWidth) //nolint:gomnd // The list takes two fifths
+	paneWidth := max(width-listWidth-utf8.RuneCountInString(separator), 0)
+
+	left := a.listLines(listWidth, bodyHeight)
+	right := a.paneLines(paneWidth)
+	for i := 0; i < bodyHeight; i++ {
+		// Lines of the list are padded already, they may hold control sequences
+		l, r := pad("", listWidth), ""
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+
+		lines = append(lines, l+separator+r)
+	}
+
+	lines = append(lines, truncate(a.status, width), a.help())
+
+	var sb strings.Builder
+	sb.WriteString(seqHome)
+	for i, l := range lines {
+		if i != 0 {
+			sb.WriteString("\r\n")
+		}
+		sb.WriteString(l)
+		sb.WriteString(seqClear)
+	}
+	sb.WriteString(seqClearTo)
+
+	return sb.String()
+}
+
+// handle handles the key in the current mode. It returns false when the user quits.
+func (a *App) handle(k key) bool {
+	switch a.mode {
+	case modeSearch:
+		a.handleSearch(k)
+	case modeNew:
+		a.handleNew(k)
+	case modeForm:
+		a.handleForm(k)
+	case modeConfirm:
+		a.handleConfirm(k)
+	default:
+		return a.handleList(k)
+	}
+
+	return true
+}
+
+// handleList handles the keys of the list of records.
+func (a *App) handleList(k key) bool {
+	a.status = ""
+
+	switch {
+	case k.code == keyUp || k.r == 'k':
+		a.move(-1)
+	case k.code == keyDown || k.r == 'j':
+		a.move(1)
+	case k.code == keyPageUp:
+		a.move(-10) //nolint:gomnd // Page of the list
+	case k.code == keyPageDown:
+		a.move(10) //nolint:gomnd // Page of the list
+	case k.code == keyEnter:
+		a.load()
+	case k.code == keyEsc:
+		a.filter = nil
+		a.applyFilter()
+	case k.r == '/':
+		a.mode = modeSearch
+	case k.r == 'r':
+		a.reveal = !a.reveal
+	case k.r == 'g':
+		a.setError(a.refresh())
+	case k.r == 'n':
+		a.mode = modeNew
+		a.status = "New record: l - login, c - card, o - otp, t - note, esc - cancel"
+	case k.r == 'e':
+		a.edit()
+	case k.r == 'd':
+		if unit := a.selected(); unit != nil {
+			a.mode = modeConfirm
+			a.status = fmt.Sprintf("Delete %q? y/n", unit.Name)
+		}
+	case k.r == 'q':
+		return false
+	}
+
+	return true
+}
+
+// handleSearch edits the filter of the list, the list is filtered as the
+// user types.
+func (a *App) handleSearch(k key) {
+	switch k.code {
+	case keyEnter:
+		a.mode = modeList
+	case keyEsc:
+		a.mode = modeList
+		a.filter = nil
+	case keyBackspace:
+		if len(a.filter) > 0 {
+			a.filter = a.filter[:len(a.filter)-1]
+		}
+	case keyRune:
+		a.filter = append(a.filter, k.r)
+	default:
+		return
+	}
+
+	a.applyFilter()
+}
+
+// handleNew chooses the type of the new record.
+func (a *App) handleNew(k key) {
+	a.mode = modeList
+	a.status = ""
+
+	switch k.r {
+	case 'l':
+		a.openForm(newForm(0, record.Login, "", nil))
+	case 'c':
+		a.openForm(newForm(0, record.Card, "", nil))
+	case 'o':
+		a.openForm(newForm(0, record.OTP, "", nil))
+	case 't':
+		a.openForm(newForm(0, record.Text, "", nil))
+	}
+}
+
+// handleForm edits the form, the record is written with Ctrl+S.
+func (a *App) handleForm(k key) {
+	switch k.code {
+	case keyEsc:
+		a.mode = modeList
+		a.form = nil
+		a.status = "Canceled"
+	case keyTab, keyDown:
+		a.form.move(1)
+	case keyBacktab, keyUp:
+		a.form.move(-1)
+	case keyEnter:
+		if a.form.focus == len(a.form.fields)-1 {
+			a.save()
+			return
+		}
+		a.form.move(1)
+	case keyCtrlS:
+		a.save()
+	case keyCtrlR:
+		a.reveal = !a.reveal
+	default:
+		a.form.input(k)
+	}
+}
+
+// handleConfirm deletes the selected record if the user agrees.
+func (a *App) handleConfirm(k key) {
+	a.mode = modeList
+	a.status = ""
+
+	unit := a.selected()
+	if k.r != 'y' || unit == nil {
+		return
+	}
+
+	_, err := a.client.DeleteFile(unit.Id)
+	if err != nil {
+		a.setError(err)
+		return
+	}
+
+	a.setError(a.refresh())
+	if a.status == "" {
+		a.status = fmt.Sprintf("Deleted %q", unit.Name)
+	}
+}
+
+// UTILS FOR APP.
+
+// refresh reads the list of records again, the details are read on demand.
+func (a *App) refresh() error {
+	rAllFile, err := a.client.ReadAllFile()
+	if err != nil {
+		return fmt.Errorf("failed get all file: %w", err)
+	}
+
+	a.units = a.units[:0]
+	for _, v := range rAllFile.Units {
+		if v.Id > 0 {
+			a.units = append(a.units, v)
+		}
+	}
+
+	sort.SliceStable(a.units, func(i, j int) bool {
+		return strings.ToLower(a.units[i].Name) < strings.ToLower(a.units[j].Name)
+	})
+
+	a.details = map[int32]*proto.ReadRecordResponse{}
+	a.applyFilter()
+
+	return nil
+}
+
+// applyFilter shows the records whose name or type contains the filter.
+func (a *App) applyFilter() {
+	filter := strings.ToLower(string(a.filter))
+
+	a.visible = a.visible[:0]
+	for _, v := range a.units {
+		if strings.Contains(strings.ToLower(v.Name), filter) || strings.Contains(v.Type, filter) {
+			a.visible = append(a.visible, v)
+		}
+	}
+
+	a.cursor = min(a.cursor, max(len(a.visible)-1, 0))
+	a.offset = 0
+}
+
+// move moves the cursor by `n` records.
+func (a *App) move(n int) {
+	a.cursor = min(max(a.cursor+n, 0), max(len(a.visible)-1, 0))
+}
+
+// selected returns the record under the cursor.
+func (a *App) selected() *proto.StorageUnit {
+	if a.cursor >= len(a.visible) {
+		return nil
+	}
+
+	return a.visible[a.cursor]
+}
+
+// load reads the details of the selected record.
+func (a *App) load() *proto.ReadRecordResponse {
+	unit := a.selected()
+	if unit == nil {
+		return nil
+	}
+
+	if rec, ok := a.details[unit.Id]; ok {
+		return rec
+	}
+
+	rec, err := a.client.ReadFile(unit.Id)
+	if err != nil {
+		a.setError(err)
+		return nil
+	}
+
+	a.details[unit.Id] = rec
+
+	return rec
+}
+
+// edit opens the form of the selected record.
+func (a *App) edit() {
+	unit := a.selected()
+	rec := a.load()
+	if rec == nil {
+		return
+	}
+
+	var values map[string]string
+	switch {
+	case rec.Type == record.SSHKey:
+		a.status = "SSH keys are edited with the put command"
+		return
+	case record.Structured(rec.Type):
+		v, err := record.Values(rec.Type, rec.Data)
+		if err != nil {
+			a.setError(err)
+			return
+		}
+		values = v
+	case rec.Type == record.Text:
+		values = map[string]string{noteField: string(rec.Data)}
+	default:
+		a.status = "Files are edited with the put command"
+		return
+	}
+
+	a.openForm(newForm(unit.Id, rec.Type, rec.Name, values))
+}
+
+// openForm shows the form instead of the details.
+func (a *App) openForm(f *form) {
+	a.form = f
+	a.mode = modeForm
+	a.reveal = false
+}
+
+// save writes the record of the form.
+func (a *App) save() {
+	data, err := a.form.data()
+	if err != nil {
+		a.setError(err)
+		return
+	}
+
+	name := a.form.name()
+	resp, err := a.client.WriteData(a.form.id, a.form.typ, name, data)
+	if err != nil {
+		a.setError(err)
+		return
+	}
+
+	a.mode = modeList
+	a.form = nil
+
+	err = a.refresh()
+	if err != nil {
+		a.setError(err)
+		return
+	}
+
+	a.status = fmt.Sprintf("Saved %q", name)
+	if resp.Conflict {
+		a.status += ", the record was changed on another device and your version is kept as a conflict"
+	}
+
+	for i, v := range a.visible {
+		if v.Name == name {
+			a.cursor = i
+		}
+	}
+}
+
+// setError shows the error in the status line.
+func (a *App) setError(err error) {
+	if err != nil {
+		a.status = "Error: " + err.Error()
+	}
+}
+
+// listLines returns the lines of the list of records, the list scrolls to
+// keep the cursor visible.
+func (a *App) listLines(width int, height int) []string {
+	if a.cursor < a.offset {
+		a.offset = a.cursor
+	}
+	if a.cursor >= a.offset+height {
+		a.offset = a.cursor - height + 1
+	}
+
+	var lines []string
+	for i := a.offset; i < len(a.visible) && i < a.offset+height; i++ {
+		v := a.visible[i]
+
+		flag := " "
+		if v.Conflict {
+			flag = "!"
+		}
+
+		line := pad(fmt.Sprintf("%s%-6s %s", flag, v.Type, v.Name), width)
+		if i == a.cursor {
+			line = seqReverse + line + seqReset
+		}
+
+		lines = append(lines, line)
+	}
+
+	if len(a.visible) == 0 {
+		lines = append(lines, pad(" no records", width))
+	}
+
+	return lines
+}
+
+// paneLines returns the lines of the form or of the details of the selected record.
+func (a *App) paneLines(width int) []string {
+	if a.mode == modeForm {
+		return a.formLines(width)
+	}
+
+	unit := a.selected()
+	if unit == nil {
+		return nil
+	}
+
+	lines := []string{
+		seqBold + truncate(unit.Name, width) + seqReset,
+		"",
+		row("ID", strconv.Itoa(int(unit.Id)), width),
+		row("Type", unit.Type, width),
+		row("Owner", unit.OwnerLogin, width),
+	}
+	if unit.UpdatedAt != 0 {
+		lines = append(lines, row("Updated", time.Unix(unit.UpdatedAt, 0).Format(time.DateTime), width))
+	}
+	if unit.Shared {
+		lines = append(lines, row("Shared", unit.Permission, width))
+	}
+	if unit.Conflict {
+		lines = append(lines, row("Conflict", "yes, resolve with -c resolve", width))
+	}
+	lines = append(lines, "")
+
+	rec, ok := a.details[unit.Id]
+	if !ok {
+		return append(lines, "Press enter to show the value")
+	}
+
+	return append(lines, a.valueLines(rec, width)...)
+}
+
+// valueLines returns the lines of the value of the record, secret fields are
+// masked unless revealed.
+func (a *App) valueLines(rec *proto.ReadRecordResponse, width int) []string {
+	var lines []string
+
+	switch {
+	case record.Structured(rec.Type):
+		values, err := record.Values(rec.Type, rec.Data)
+		if err != nil {
+			return []string{"Error: " + err.Error()}
+		}
+
+		for _, f := range record.Fields(rec.Type) {
+			v, ok := values[f.Name]
+			if !ok {
+				continue
+			}
+			if f.Secret && !a.reveal {
+				v = mask
+			}
+
+			lines = append(lines, row(f.Label, v, width))
+		}
+	case rec.Type == record.File:
+		lines = append(lines, fmt.Sprintf("<file, %v bytes>", len(rec.Data)))
+	default:
+		for _, l := range strings.Split(string(rec.Data), "\n") {
+			lines = append(lines, truncate(strings.TrimRight(l, "\r"), width))
+		}
+	}
+
+	return lines
+}
+
+// formLines returns the lines of the form, the focused field has a cursor.
+func (a *App) formLines(width int) []string {
+	lines := []string{seqBold + a.form.title() + seqReset, ""}
+
+	for i, f := range a.form.fields {
+		v := string(f.value)
+		if f.secret && !a.reveal {
+			v = strings.Repeat("•", len(f.value))
+		}
+
+		marker := "  "
+		if i == a.form.focus {
+			marker = "> "
+			v += "_"
+		}
+
+		lines = append(lines, marker+row(f.label, v, width-2)) //nolint:gomnd // Width of the marker
+	}
+
+	return lines
+}
+
+// help returns the keys of the current mode.
+func (a *App) help() string {
+	switch a.mode {
+	case modeSearch:
+		return "type to search  enter done  esc clear"
+	case modeForm:
+		return "tab/↑/↓ field  ctrl+s save  ctrl+r reveal  esc cancel"
+	case modeNew, modeConfirm:
+		return ""
+	default:
+		return "↑/↓ move  enter show  / search  r reveal  n new  e edit  d delete  g refresh  q quit"
+	}
+}
+
+// row formats the labeled value.
+func row(label string, value string, width int) string {
+	return truncate(fmt.Sprintf("%-10s %s", label+":", value), width)
+}
+
+// pad truncates the line or pads it with spaces to the width.
+func pad(s string, width int) string {
+	s = truncate(s, width)
+	return s + strings.Repeat(" ", width-utf8.RuneCountInString(s))
+}
+
+// truncate cuts the line to the width. Control characters are replaced, so
+// names and values of records cannot send sequences to the terminal.
+func truncate(s string, width int) string {
+	s = sanitize(s)
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	if width <= 0 {
+		return ""
+	}
+
+	r := []rune(s)
+
+	return string(r[:width-1]) + "…"
+}
+
+// sanitize replaces control characters of the line with the replacement character.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return utf8.RuneError
+		}
+
+		return r
+	}, s)
+}
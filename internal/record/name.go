@@ -0,0 +1,93 @@
+package record
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Separator separates the segments of hierarchical names like `work/aws/prod`.
+const Separator = "/"
+
+// maxNameLen is the maximum length of a name, it is limited by the storage.
+const maxNameLen = 256
+
+// CleanName returns the canonical form of the hierarchical name. Spaces around
+// segments and empty segments are removed, so ` work//aws/ ` is `work/aws`.
+// Names of relative segments `.` and `..` are invalid.
+func CleanName(name string) (string, error) {
+	var segments []string
+	for _, v := range strings.Split(name, Separator) {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		if v == "." || v == ".." {
+			return "", fmt.Errorf("%w: name %q must not contain %q segments", ErrInvalid, name, v)
+		}
+
+		segments = append(segments, v)
+	}
+
+	clean := strings.Join(segments, Separator)
+	if clean == "" {
+		return "", fmt.Errorf("%w: name is required", ErrInvalid)
+	}
+
+	if utf8.RuneCountInString(clean) > maxNameLen {
+		return "", fmt.Errorf("%w: name is longer than %v characters", ErrInvalid, maxNameLen)
+	}
+
+	return clean, nil
+}
+
+// CleanFolderName returns the canonical form of the name of a folder. Folders
+// form their own tree, so the name is a single segment.
+func CleanFolderName(name string) (string, error) {
+	clean, err := CleanName(name)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.Contains(clean, Separator) {
+		return "", fmt.Errorf("%w: folder name %q must not contain %q", ErrInvalid, name, Separator)
+	}
+
+	return clean, nil
+}
+
+// maxTagLen is the maximum length of a tag, it is limited by the storage.
+const maxTagLen = 64
+
+// TagSeparator separates tags in lists, tags must not contain it.
+const TagSeparator = ","
+
+// CleanTags returns the canonical form of the tags: spaces around tags are
+// removed and duplicates are dropped, the order of the tags is kept.
+func CleanTags(tags []string) ([]string, error) {
+	clean := make([]string, 0, len(tags))
+	seen := map[string]bool{}
+
+	for _, v := range tags {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			return nil, fmt.Errorf("%w: tag is empty", ErrInvalid)
+		}
+
+		if strings.Contains(v, TagSeparator) {
+			return nil, fmt.Errorf("%w: tag %q must not contain %q", ErrInvalid, v, TagSeparator)
+		}
+
+		if utf8.RuneCountInString(v) > maxTagLen {
+			return nil, fmt.Errorf("%w: tag is longer than %v characters", ErrInvalid, maxTagLen)
+		}
+
+		if !seen[v] {
+			seen[v] = true
+			clean = append(clean, v)
+		}
+	}
+
+	return clean, nil
+}
@@ -0,0 +1,106 @@
+package record
+
+import (
+	"encoding/base32"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// otpURI returns the otpauth URI of the secret with the query parameters.
+func otpURI(kind string, secret string, params string) string {
+	encoded := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte(secret))
+	return fmt.Sprintf("otpauth://%s/test?secret=%s%s", kind, encoded, params)
+}
+
+func TestHOTP(t *testing.T) {
+	// Test values of RFC 4226, appendix D
+	key, err := ParseOTP(otpURI(HOTP, "12345678901234567890", ""))
+	assert.NoError(t, err)
+
+	codes := []string{"755224", "287082", "359152", "969429", "338314",
+		"254676", "287922", "162583", "399871", "520489"}
+
+	for i, code := range codes {
+		t.Run(fmt.Sprintf("Counter %v must give the code of RFC 4226", i), func(t *testing.T) {
+			assert.Equal(t, code, key.HOTP(uint64(i)))
+		})
+	}
+}
+
+func TestTOTP(t *testing.T) {
+	// Seeds of RFC 6238, appendix B, the seed has the size of the hash
+	seeds := map[string]string{
+		"SHA1":   "12345678901234567890",
+		"SHA256": "12345678901234567890123456789012",
+		"SHA512": "1234567890123456789012345678901234567890123456789012345678901234",
+	}
+
+	tests := []struct {
+		time  int64
+		codes map[string]string
+	}{
+		{time: 59, codes: map[string]string{"SHA1": "94287082", "SHA256": "46119246", "SHA512": "90693936"}},
+		{time: 1111111109, codes: map[string]string{"SHA1": "07081804", "SHA256": "68084774", "SHA512": "25091201"}},
+		{time: 1111111111, codes: map[string]string{"SHA1": "14050471", "SHA256": "67062674", "SHA512": "99943326"}},
+		{time: 1234567890, codes: map[string]string{"SHA1": "89005924", "SHA256": "91819424", "SHA512": "93441116"}},
+		{time: 2000000000, codes: map[string]string{"SHA1": "69279037", "SHA256": "90698825", "SHA512": "38618901"}},
+		{time: 20000000000, codes: map[string]string{"SHA1": "65353130", "SHA256": "77737706", "SHA512": "47863826"}},
+	}
+
+	for alg, seed := range seeds {
+		key, err := ParseOTP(otpURI(TOTP, seed, "&digits=8&algorithm="+strings.ToLower(alg)))
+		assert.NoError(t, err)
+
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s at %v must give the code of RFC 6238", alg, tt.time), func(t *testing.T) {
+				code, left := key.TOTP(time.Unix(tt.time, 0))
+				assert.Equal(t, tt.codes[alg], code)
+				assert.Equal(t, time.Duration(30-tt.time%30)*time.Second, left)
+			})
+		}
+	}
+}
+
+func TestParseOTP(t *testing.T) {
+	t.Run("Defaults must be set", func(t *testing.T) {
+		key, err := ParseOTP(" otpauth://TOTP/GitHub:alice?secret=jbsw y3dp ehpk 3pxp====&issuer=GitHub ")
+		assert.NoError(t, err)
+		assert.Equal(t, TOTP, key.Kind)
+		assert.Equal(t, "GitHub:alice", key.Label)
+		assert.Equal(t, "GitHub", key.Issuer)
+		assert.Equal(t, []byte("Hello!\xde\xad\xbe\xef"), key.Secret)
+		assert.Equal(t, "SHA1", key.Algorithm)
+		assert.Equal(t, defaultDigits, key.Digits)
+		assert.Equal(t, defaultPeriod, key.Period)
+	})
+
+	tests := []struct {
+		name string
+		uri  string
+	}{
+		{name: "Invalid URI", uri: "otpauth://totp/%zz?secret=JBSWY3DPEHPK3PXP"},
+		{name: "Other scheme", uri: "https://totp/test?secret=JBSWY3DPEHPK3PXP"},
+		{name: "Unknown kind", uri: "otpauth://motp/test?secret=JBSWY3DPEHPK3PXP"},
+		{name: "Missing secret", uri: "otpauth://totp/test"},
+		{name: "Secret not in base32", uri: "otpauth://totp/test?secret=JBSWY3DP1"},
+		{name: "Unknown algorithm", uri: "otpauth://totp/test?secret=JBSWY3DPEHPK3PXP&algorithm=MD5"},
+		{name: "Too few digits", uri: "otpauth://totp/test?secret=JBSWY3DPEHPK3PXP&digits=5"},
+		{name: "Too many digits", uri: "otpauth://totp/test?secret=JBSWY3DPEHPK3PXP&digits=9"},
+		{name: "Digits not a number", uri: "otpauth://totp/test?secret=JBSWY3DPEHPK3PXP&digits=six"},
+		{name: "Zero period", uri: "otpauth://totp/test?secret=JBSWY3DPEHPK3PXP&period=0"},
+		{name: "Negative period", uri: "otpauth://totp/test?secret=JBSWY3DPEHPK3PXP&period=-30"},
+		{name: "Negative counter", uri: "otpauth://hotp/test?secret=JBSWY3DPEHPK3PXP&counter=-1"},
+		{name: "Counter not a number", uri: "otpauth://hotp/test?secret=JBSWY3DPEHPK3PXP&counter=one"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" must be rejected", func(t *testing.T) {
+			_, err := ParseOTP(tt.uri)
+			assert.ErrorIs(t, err, ErrInvalid)
+		})
+	}
+}
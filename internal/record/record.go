@@ -0,0 +1,257 @@
+// Package record describes the types of records stored in GophKeeper. Text
+// and file records keep any value, structured records (logins, cards, OTP
+// secrets, SSH keys) keep a JSON object with the fields of their type. The package is shared by every
+// part of the agent which reads or writes record values, so that values
+// written by one command are understood by the others.
+package record
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Types of records.
+const (
+	Text   = "text"
+	File   = "file"
+	Login  = "login"
+	Card   = "card"
+	OTP    = "otp"
+	SSHKey = "ssh"
+)
+
+// ErrInvalid is returned for values which do not match their type.
+var ErrInvalid = errors.New("invalid record")
+
+// Field is a field of a structured record. Secret fields are not shown
+// unless asked for explicitly.
+type Field struct {
+	Name     string
+	Label    string
+	Required bool
+	Secret   bool
+}
+
+// fields lists the fields of the structured types in the order they are shown.
+var fields = map[string][]Field{
+	Login: {
+		{Name: "username", Label: "Username"},
+		{Name: "password", Label: "Password", Required: true, Secret: true},
+		{Name: "url", Label: "URL"},
+		{Name: "notes", Label: "Notes"},
+	},
+	Card: {
+		{Name: "number", Label: "Number", Required: true},
+		{Name: "holder", Label: "Holder"},
+		{Name: "expiry", Label: "Expiry (MM/YY)"},
+		{Name: "cvv", Label: "CVV", Secret: true},
+	},
+	OTP: {
+		{Name: "uri", Label: "otpauth:// URI", Required: true, Secret: true},
+		{Name: "notes", Label: "Notes"},
+	},
+	SSHKey: {
+		{Name: "private_key", Label: "Private key (PEM)", Required: true, Secret: true},
+		{Name: "passphrase", Label: "Passphrase", Secret: true},
+		{Name: "comment", Label: "Comment"},
+	},
+}
+
+var (
+	reExpiry = regexp.MustCompile(`^(0[1-9]|1[0-2])/\d{2}$`)
+	reCVV    = regexp.MustCompile(`^\d{3,4}$`)
+)
+
+// Types returns the names of all known types.
+func Types() []string {
+	return []string{Text, File, Login, Card, OTP, SSHKey}
+}
+
+// Known reports whether the type is one of the known types.
+func Known(typ string) bool {
+	return typ == Text || typ == File || Structured(typ)
+}
+
+// Structured reports whether the values of the type are JSON objects of fields.
+func Structured(typ string) bool {
+	_, ok := fields[typ]
+	return ok
+}
+
+// Fields returns the fields of the structured type.
+func Fields(typ string) []Field {
+	return fields[typ]
+}
+
+// Parse checks the value of the type and returns it in the stored form. The
+// value of a structured type is either a JSON object or `key=value` lines,
+// it is stored as a JSON object. A PEM private key is the value of an SSH
+// key record without a passphrase. Values of other types are returned as is.
+func Parse(typ string, data []byte) ([]byte, error) {
+	if !Structured(typ) {
+		if !Known(typ) {
+			return nil, fmt.Errorf("%w: unknown type %q", ErrInvalid, typ)
+		}
+		return data, nil
+	}
+
+	if typ == SSHKey && bytes.HasPrefix(bytes.TrimSpace(data), []byte(pemPrefix)) {
+		return Encode(typ, map[string]string{"private_key": string(data)})
+	}
+
+	values, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return Encode(typ, values)
+}
+
+// Values decodes the value of the structured type into its fields.
+func Values(typ string, data []byte) (map[string]string, error) {
+	if !Structured(typ) {
+		return nil, fmt.Errorf("%w: type %q has no fields", ErrInvalid, typ)
+	}
+
+	values := map[string]string{}
+	err := json.Unmarshal(data, &values)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+
+	return values, nil
+}
+
+// Encode checks the fields of the structured type and encodes them into the
+// stored form. Empty fields are omitted.
+func Encode(typ string, values map[string]string) ([]byte, error) {
+	known := map[string]bool{}
+	out := map[string]string{}
+	for _, f := range Fields(typ) {
+		known[f.Name] = true
+
+		v := strings.TrimSpace(values[f.Name])
+		if v == "" {
+			if f.Required {
+				return nil, fmt.Errorf("%w: field %q is required", ErrInvalid, f.Name)
+			}
+			continue
+		}
+
+		out[f.Name] = v
+	}
+
+	for k := range values {
+		if !known[k] {
+			return nil, fmt.Errorf("%w: unknown field %q of %s", ErrInvalid, k, typ)
+		}
+	}
+
+	if typ == Card {
+		err := checkCard(out)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if typ == OTP {
+		err := checkOTP(out)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if typ == SSHKey {
+		err := checkSSH(out)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		return nil, fmt.Errorf("failed encode record: %w", err)
+	}
+
+	return data, nil
+}
+
+/* UTILS. */
+
+// decode reads the fields from a JSON object or from `key=value` lines.
+func decode(data []byte) (map[string]string, error) {
+	values := map[string]string{}
+
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("{")) {
+		err := json.Unmarshal(data, &values)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+		}
+		return values, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: expected key=value, got %q", ErrInvalid, line)
+		}
+
+		values[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed read record: %w", err)
+	}
+
+	return values, nil
+}
+
+// checkCard checks the number with the Luhn algorithm, the expiry date and
+// the CVV of a card. Spaces and dashes of the number are removed.
+func checkCard(values map[string]string) error {
+	number := strings.NewReplacer(" ", "", "-", "").Replace(values["number"])
+
+	var sum int
+	for i := range number {
+		d := int(number[len(number)-1-i] - '0')
+		if d < 0 || d > 9 {
+			return fmt.Errorf("%w: card number must contain only digits", ErrInvalid)
+		}
+
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+
+	//nolint:gomnd // This legal number
+	if len(number) < 12 || sum%10 != 0 {
+		return fmt.Errorf("%w: wrong card number", ErrInvalid)
+	}
+	values["number"] = number
+
+	if v, ok := values["expiry"]; ok && !reExpiry.MatchString(v) {
+		return fmt.Errorf("%w: expiry must be MM/YY", ErrInvalid)
+	}
+
+	if v, ok := values["cvv"]; ok && !reCVV.MatchString(v) {
+		return fmt.Errorf("%w: CVV must be 3 or 4 digits", ErrInvalid)
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package record
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Metadata attributes of SSH key records, derived from the private key when
+// the record is written.
+const (
+	MetaPublicKey   = "public_key"
+	MetaFingerprint = "fingerprint"
+)
+
+// pemPrefix starts the PEM encoded private keys.
+const pemPrefix = "-----BEGIN"
+
+// SSHPrivateKey parses the private key of an SSH key record, encrypted keys
+// are decrypted with the passphrase of the record. The result is one of the
+// key types of `crypto`, as expected by `ssh/agent`.
+func SSHPrivateKey(values map[string]string) (any, error) {
+	pem := []byte(values["private_key"])
+
+	var key any
+	var err error
+	if passphrase := values["passphrase"]; passphrase != "" {
+		key, err = ssh.ParseRawPrivateKeyWithPassphrase(pem, []byte(passphrase))
+	} else {
+		key, err = ssh.ParseRawPrivateKey(pem)
+	}
+
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		return nil, fmt.Errorf("%w: ssh key is encrypted, the passphrase is required", ErrInvalid)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: ssh key: %w", ErrInvalid, err)
+	}
+
+	return key, nil
+}
+
+// SSHMetadata returns the public key in the authorized_keys format and the
+// SHA256 fingerprint of the SSH key record.
+func SSHMetadata(data []byte) (map[string]string, error) {
+	values, err := Values(SSHKey, data)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := SSHPrivateKey(values)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: ssh key: %w", ErrInvalid, err)
+	}
+
+	public := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+	if comment := values["comment"]; comment != "" {
+		public += " " + comment
+	}
+
+	return map[string]string{
+		MetaPublicKey:   public,
+		MetaFingerprint: ssh.FingerprintSHA256(signer.PublicKey()),
+	}, nil
+}
+
+// checkSSH checks the private key of an SSH key record. Keys keep their
+// line breaks, so they are parsed as written.
+func checkSSH(values map[string]string) error {
+	_, err := SSHPrivateKey(values)
+	return err
+}
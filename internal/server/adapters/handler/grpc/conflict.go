@@ -0,0 +1,302 @@
+// Package handler contains gRPC handlers that implement the server-side logic for the application.
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+
+	"github.com/dedpnd/GophKeeper/internal/server/adapters/middleware"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+	"go.uber.org/zap"
+)
+
+// unknownDevice names the writes of clients which do not send a device.
+var unknownDevice = "unknown"
+
+// recordWrite contains the revision vector the writer has seen, the device
+// of the writer and the metadata of the written value.
+type recordWrite struct {
+	device   string
+	versions map[string]int64
+	metadata map[string]string
+}
+
+// ListConflicts returns the current version of the record and the versions
+// written concurrently with it, with decrypted values.
+func (s StorageHandler) ListConflicts(ctx context.Context,
+	in *proto.ListConflictsRequest) (*proto.ListConflictsResponse, error) {
+	var resp proto.ListConflictsResponse
+
+	// Get token from context
+	token, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		s.Logger.Error(errorInvalidToken)
+		resp.Error = errorInvalidToken
+		return &resp, nil
+	}
+
+	if !token.HasScope(domain.ScopeRead) {
+		resp.Error = errorAccessDenied
+		return &resp, nil
+	}
+
+	rec, err := s.readRecord(ctx, int(in.Id), token.ID)
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed read record")
+		resp.Error = "failed read record"
+		return &resp, nil
+	}
+
+	if rec == nil || !token.CanAccessRecord(rec.ID, rec.Name) {
+		resp.Error = "record not found"
+		return &resp, nil
+	}
+
+	conflicts, err := s.Svc.ListConflicts(rec.ID)
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed get conflicts")
+		resp.Error = "failed get conflicts"
+		return &resp, nil
+	}
+
+	resp.Current, err = s.recordVersion(domain.Conflict{
+		Name:      rec.Name,
+		Type:      rec.Type,
+		Value:     rec.Value,
+		Key:       rec.Key,
+		Versions:  rec.Versions,
+		Metadata:  rec.Metadata,
+		CreatedAt: rec.UpdatedAt,
+	})
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed decrypt data")
+		resp.Error = "failed decrypt data"
+		return &resp, nil
+	}
+
+	resp.Conflicts = make([]*proto.RecordVersion, 0, len(conflicts))
+	for _, v := range conflicts {
+		version, err := s.recordVersion(*v)
+		if err != nil {
+			s.Logger.With(zap.Error(err)).Error("failed decrypt data")
+			resp.Error = "failed decrypt data"
+			return &resp, nil
+		}
+
+		resp.Conflicts = append(resp.Conflicts, version)
+	}
+
+	return &resp, nil
+}
+
+// ResolveConflict replaces the record with the version picked by the caller,
+// `keep` is the ID of a conflicting version or zero for the current one. The
+// other versions are removed. With `merge_metadata` the metadata of all
+// versions is merged, the picked version wins on the same keys.
+func (s StorageHandler) ResolveConflict(ctx context.Context,
+	in *proto.ResolveConflictRequest) (*proto.ResolveConflictResponse, error) {
+	var resp proto.ResolveConflictResponse
+
+	// Get token from context
+	token, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		s.Logger.Error(errorInvalidToken)
+		resp.Error = errorInvalidToken
+		return &resp, nil
+	}
+
+	if !token.HasScope(domain.ScopeWrite) {
+		resp.Error = errorAccessDenied
+		return &resp, nil
+	}
+
+	rec, err := s.readRecord(ctx, int(in.Id), token.ID)
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed read record")
+		resp.Error = "failed read record"
+		return &resp, nil
+	}
+
+	if rec == nil || !token.CanAccessRecord(rec.ID, rec.Name) || !canWriteRecord(rec, token.ID) {
+		resp.Error = errRecordNotWritable.Error()
+		return &resp, nil
+	}
+
+	conflicts, err := s.Svc.ListConflicts(rec.ID)
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed get conflicts")
+		resp.Error = "failed get conflicts"
+		return &resp, nil
+	}
+
+	if len(conflicts) == 0 {
+		resp.Error = "record has no conflicts"
+		return &resp, nil
+	}
+
+	// The current version is kept unless a conflicting one is picked
+	keep := domain.Conflict{
+		Name:     rec.Name,
+		Type:     rec.Type,
+		Value:    rec.Value,
+		Key:      rec.Key,
+		Metadata: rec.Metadata,
+	}
+	found := in.Keep == 0
+	versions := decodeVersions(rec.Versions)
+	metadata := map[string]string{}
+	if in.MergeMetadata {
+		maps.Copy(metadata, decodeMetadata(rec.Metadata))
+	}
+
+	resolved := make([]int, 0, len(conflicts))
+	for _, v := range conflicts {
+		resolved = append(resolved, v.ID)
+
+		if int32(v.ID) == in.Keep {
+			keep = *v
+			found = true
+		}
+
+		versions = mergeVersions(versions, decodeVersions(v.Versions))
+		if in.MergeMetadata {
+			maps.Copy(metadata, decodeMetadata(v.Metadata))
+		}
+	}
+
+	if !found {
+		resp.Error = "conflict version not found"
+		return &resp, nil
+	}
+
+	// The picked version wins on the same keys
+	maps.Copy(metadata, decodeMetadata(keep.Metadata))
+
+	// Only the listed versions are resolved, later writes stay conflicts
+	ok, err = s.Svc.ResolveConflict(domain.Storage{
+		ID:       rec.ID,
+		Name:     keep.Name,
+		Type:     keep.Type,
+		Value:    keep.Value,
+		Key:      keep.Key,
+		Versions: encodeJSON(nextVersions(versions, in.Device)),
+		Metadata: encodeJSON(metadata),
+	}, rec.Revision, resolved)
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed resolve conflict")
+		resp.Error = "failed resolve conflict"
+		return &resp, nil
+	}
+
+	if !ok {
+		resp.Error = "record was changed, list the conflicts again"
+		return &resp, nil
+	}
+
+	return &resp, nil
+}
+
+/* UTILS. */
+
+// recordVersion converts the version of the record to the response unit.
+func (s StorageHandler) recordVersion(v domain.Conflict) (*proto.RecordVersion, error) {
+	data, err := decryptionData(s.MasterKey, v.Key, v.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.RecordVersion{
+		Id:        int32(v.ID),
+		Device:    v.Device,
+		Name:      v.Name,
+		Type:      v.Type,
+		Data:      data,
+		Versions:  decodeVersions(v.Versions),
+		Metadata:  decodeMetadata(v.Metadata),
+		CreatedAt: v.CreatedAt.Unix(),
+	}, nil
+}
+
+// deviceName returns the name of the device in revision vectors.
+func deviceName(device string) string {
+	if device == "" {
+		return unknownDevice
+	}
+
+	return device
+}
+
+// nextVersions returns a copy of the revision vector with the write
+// of the device counted.
+func nextVersions(versions map[string]int64, device string) map[string]int64 {
+	next := maps.Clone(versions)
+	if next == nil {
+		next = map[string]int64{}
+	}
+
+	next[deviceName(device)]++
+
+	return next
+}
+
+// descends reports whether the revision vector `base` includes every write
+// counted in `current`, that is the writer has seen the current value.
+func descends(base map[string]int64, current map[string]int64) bool {
+	for device, n := range current {
+		if base[device] < n {
+			return false
+		}
+	}
+
+	return true
+}
+
+// mergeVersions returns the revision vector which includes the writes
+// of both vectors.
+func mergeVersions(a map[string]int64, b map[string]int64) map[string]int64 {
+	merged := maps.Clone(a)
+	if merged == nil {
+		merged = map[string]int64{}
+	}
+
+	for device, n := range b {
+		merged[device] = max(merged[device], n)
+	}
+
+	return merged
+}
+
+// decodeVersions decodes the revision vector stored in a jsonb column.
+// The column always contains an object, so malformed values are not expected
+// and are treated as empty.
+func decodeVersions(s string) map[string]int64 {
+	versions := map[string]int64{}
+	_ = json.Unmarshal([]byte(s), &versions)
+
+	return versions
+}
+
+// decodeMetadata decodes the metadata stored in a jsonb column.
+func decodeMetadata(s string) map[string]string {
+	metadata := map[string]string{}
+	_ = json.Unmarshal([]byte(s), &metadata)
+
+	return metadata
+}
+
+// encodeJSON encodes a map for a jsonb column, nil maps are stored as
+// empty objects.
+func encodeJSON[T any](m map[string]T) string {
+	if m == nil {
+		return "{}"
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return "{}"
+	}
+
+	return string(b)
+}
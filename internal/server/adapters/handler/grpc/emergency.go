@@ -0,0 +1,377 @@
+// Package handler contains gRPC handlers that implement the server-side logic for the application.
+package handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dedpnd/GophKeeper/internal/server/adapters/middleware"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+	"github.com/dedpnd/GophKeeper/internal/server/core/services"
+	"go.uber.org/zap"
+)
+
+var errorEmergencyManagement = "access tokens cannot manage emergency access"
+var errorEmergencyNotFound = "emergency contact not found"
+
+// AddEmergencyContact nominates another user, identified by login, as an
+// emergency contact of the caller. The contact gets read access to the
+// records of the caller after requesting it and waiting for `wait_hours`.
+// Nominating the same user again replaces the waiting period and cancels
+// a pending request.
+func (h UserHandler) AddEmergencyContact(ctx context.Context,
+	in *proto.AddEmergencyContactRequest) (*proto.AddEmergencyContactResponse, error) {
+	var res proto.AddEmergencyContactResponse
+
+	claims, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		h.Logger.Error(errorInvalidToken)
+		res.Error = errorInvalidToken
+		return &res, nil
+	}
+
+	if claims.IsAccessToken() {
+		res.Error = errorEmergencyManagement
+		return &res, nil
+	}
+
+	if in.WaitHours < 0 {
+		res.Error = "wait hours incorrect"
+		return &res, nil
+	}
+
+	user, errRes := h.findEmergencyUser(in.Login, claims.ID)
+	if errRes != "" {
+		res.Error = errRes
+		return &res, nil
+	}
+
+	contact, err := h.Svc.SaveEmergencyContact(domain.EmergencyContact{
+		Grantor:   claims.ID,
+		Grantee:   user,
+		WaitHours: int(in.WaitHours),
+		Status:    domain.EmergencyInvited,
+	})
+	if err != nil {
+		h.Logger.With(zap.Error(err)).Error("failed save emergency contact")
+		res.Error = "failed save emergency contact"
+		return &res, nil
+	}
+
+	res.Id = int32(contact.ID)
+
+	return &res, nil
+}
+
+// RemoveEmergencyContact removes the nomination of another user, identified
+// by login, as an emergency contact of the caller. Access granted to the
+// contact is revoked with it.
+func (h UserHandler) RemoveEmergencyContact(ctx context.Context,
+	in *proto.RemoveEmergencyContactRequest) (*proto.RemoveEmergencyContactResponse, error) {
+	var res proto.RemoveEmergencyContactResponse
+
+	claims, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		h.Logger.Error(errorInvalidToken)
+		res.Error = errorInvalidToken
+		return &res, nil
+	}
+
+	if claims.IsAccessToken() {
+		res.Error = errorEmergencyManagement
+		return &res, nil
+	}
+
+	user, errRes := h.findEmergencyUser(in.Login, claims.ID)
+	if errRes != "" {
+		res.Error = errRes
+		return &res, nil
+	}
+
+	err := h.Svc.DeleteEmergencyContact(claims.ID, user)
+	if err != nil {
+		h.Logger.With(zap.Error(err)).Error("failed delete emergency contact")
+		res.Error = "failed delete emergency contact"
+		return &res, nil
+	}
+
+	return &res, nil
+}
+
+// ListEmergencyContacts returns the emergency contacts nominated by the
+// caller and the users who nominated the caller, with their current status.
+func (h UserHandler) ListEmergencyContacts(ctx context.Context,
+	in *proto.ListEmergencyContactsRequest) (*proto.ListEmergencyContactsResponse, error) {
+	var res proto.ListEmergencyContactsResponse
+
+	claims, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		h.Logger.Error(errorInvalidToken)
+		res.Error = errorInvalidToken
+		return &res, nil
+	}
+
+	if claims.IsAccessToken() {
+		res.Error = errorEmergencyManagement
+		return &res, nil
+	}
+
+	contacts, err := h.Svc.ListEmergencyContacts(claims.ID)
+	if err != nil {
+		h.Logger.With(zap.Error(err)).Error("failed get emergency contacts")
+		res.Error = "failed get emergency contacts"
+		return &res, nil
+	}
+
+	for _, v := range contacts {
+		contact, err := refreshEmergencyContact(h.Svc, v)
+		if err != nil {
+			h.Logger.With(zap.Error(err)).Error("failed refresh emergency contact")
+			res.Error = "failed get emergency contacts"
+			return &res, nil
+		}
+
+		if contact.Grantor == claims.ID {
+			res.Contacts = append(res.Contacts, emergencyContactUnit(contact))
+		} else {
+			res.Grantors = append(res.Grantors, emergencyContactUnit(contact))
+		}
+	}
+
+	return &res, nil
+}
+
+// RequestEmergencyAccess starts the waiting period for the access of the
+// caller to the records of another user, identified by login, who nominated
+// the caller as an emergency contact. The time when the access will be
+// granted is returned, repeated requests do not restart the waiting period.
+func (h UserHandler) RequestEmergencyAccess(ctx context.Context,
+	in *proto.RequestEmergencyAccessRequest) (*proto.RequestEmergencyAccessResponse, error) {
+	var res proto.RequestEmergencyAccessResponse
+
+	claims, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		h.Logger.Error(errorInvalidToken)
+		res.Error = errorInvalidToken
+		return &res, nil
+	}
+
+	if claims.IsAccessToken() {
+		res.Error = errorEmergencyManagement
+		return &res, nil
+	}
+
+	grantor, errRes := h.findEmergencyUser(in.Login, claims.ID)
+	if errRes != "" {
+		res.Error = errRes
+		return &res, nil
+	}
+
+	contact, err := h.Svc.FindEmergencyContact(grantor, claims.ID)
+	if err != nil {
+		h.Logger.With(zap.Error(err)).Error("failed get emergency contact")
+		res.Error = "failed get emergency contact"
+		return &res, nil
+	}
+
+	if contact == nil {
+		res.Error = errorEmergencyNotFound
+		return &res, nil
+	}
+
+	if contact.Status == domain.EmergencyInvited || contact.Status == domain.EmergencyRejected {
+		from := contact.Status
+		now := time.Now()
+		contact.Status = domain.EmergencyRequested
+		contact.RequestedAt = &now
+
+		_, err = h.Svc.UpdateEmergencyStatus(*contact, from)
+		if err != nil {
+			h.Logger.With(zap.Error(err)).Error("failed request emergency access")
+			res.Error = "failed request emergency access"
+			return &res, nil
+		}
+	}
+
+	res.GrantedAt = emergencyGrantTime(contact).Unix()
+
+	return &res, nil
+}
+
+// RejectEmergencyAccess rejects the request of another user, identified by
+// login, for emergency access to the records of the caller. An access which
+// was already granted is revoked, the contact may request it again.
+func (h UserHandler) RejectEmergencyAccess(ctx context.Context,
+	in *proto.RejectEmergencyAccessRequest) (*proto.RejectEmergencyAccessResponse, error) {
+	var res proto.RejectEmergencyAccessResponse
+
+	claims, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		h.Logger.Error(errorInvalidToken)
+		res.Error = errorInvalidToken
+		return &res, nil
+	}
+
+	if claims.IsAccessToken() {
+		res.Error = errorEmergencyManagement
+		return &res, nil
+	}
+
+	grantee, errRes := h.findEmergencyUser(in.Login, claims.ID)
+	if errRes != "" {
+		res.Error = errRes
+		return &res, nil
+	}
+
+	contact, err := h.Svc.FindEmergencyContact(claims.ID, grantee)
+	if err != nil {
+		h.Logger.With(zap.Error(err)).Error("failed get emergency contact")
+		res.Error = "failed get emergency contact"
+		return &res, nil
+	}
+
+	if contact == nil {
+		res.Error = errorEmergencyNotFound
+		return &res, nil
+	}
+
+	if contact.Status != domain.EmergencyRequested && contact.Status != domain.EmergencyGranted {
+		res.Error = "emergency access is not requested"
+		return &res, nil
+	}
+
+	from := contact.Status
+	contact.Status = domain.EmergencyRejected
+	contact.RequestedAt = nil
+
+	ok, err = h.Svc.UpdateEmergencyStatus(*contact, from)
+	if err != nil {
+		h.Logger.With(zap.Error(err)).Error("failed reject emergency access")
+		res.Error = "failed reject emergency access"
+		return &res, nil
+	}
+
+	if !ok {
+		res.Error = "emergency access changed, try again"
+		return &res, nil
+	}
+
+	return &res, nil
+}
+
+/* UTILS. */
+
+// findEmergencyUser returns the ID of the user with the login. On failure
+// it returns the error message for the response.
+func (h UserHandler) findEmergencyUser(login string, caller int) (int, string) {
+	user, err := h.Svc.FindUserByLogin(login)
+	if err != nil {
+		h.Logger.With(zap.Error(err)).Error("failed get user")
+		return 0, "failed get user"
+	}
+
+	if user == nil {
+		return 0, "user not found"
+	}
+
+	if user.ID == caller {
+		return 0, "cannot be your own emergency contact"
+	}
+
+	return user.ID, ""
+}
+
+// emergencyGrantTime returns the time when the requested access is granted.
+func emergencyGrantTime(contact *domain.EmergencyContact) time.Time {
+	if contact.RequestedAt == nil {
+		return time.Time{}
+	}
+
+	return contact.RequestedAt.Add(time.Duration(contact.WaitHours) * time.Hour)
+}
+
+// refreshEmergencyContact grants the requested access if the waiting period
+// has passed and stores the new status.
+func refreshEmergencyContact(svc services.UserService,
+	contact *domain.EmergencyContact) (*domain.EmergencyContact, error) {
+	if contact.Status != domain.EmergencyRequested || time.Now().Before(emergencyGrantTime(contact)) {
+		return contact, nil
+	}
+
+	granted := *contact
+	granted.Status = domain.EmergencyGranted
+
+	ok, err := svc.UpdateEmergencyStatus(granted, domain.EmergencyRequested)
+	if err != nil {
+		return nil, fmt.Errorf("failed update emergency contact: %w", err)
+	}
+
+	// The request was rejected in the meantime
+	if !ok {
+		//nolint:wrapcheck // This legal return
+		return svc.FindEmergencyContact(contact.Grantor, contact.Grantee)
+	}
+
+	return &granted, nil
+}
+
+// emergencyContactUnit converts the emergency contact to the response unit.
+func emergencyContactUnit(contact *domain.EmergencyContact) *proto.EmergencyContactUnit {
+	unit := &proto.EmergencyContactUnit{
+		Id:           int32(contact.ID),
+		GrantorLogin: contact.GrantorLogin,
+		GranteeLogin: contact.GranteeLogin,
+		WaitHours:    int32(contact.WaitHours),
+		Status:       contact.Status,
+	}
+
+	if contact.RequestedAt != nil {
+		unit.RequestedAt = contact.RequestedAt.Unix()
+		unit.GrantedAt = emergencyGrantTime(contact).Unix()
+	}
+
+	return unit
+}
+
+// emergencyGrantor returns the ID of the user with the login if the caller
+// has been granted emergency access to the records of the user. On failure
+// it returns the error message for the response.
+func (s StorageHandler) emergencyGrantor(login string, token middleware.JWTclaims) (int, string) {
+	if token.IsAccessToken() {
+		return 0, errorAccessDenied
+	}
+
+	grantor, err := s.UserSvc.FindUserByLogin(login)
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed get user")
+		return 0, "failed get user"
+	}
+
+	if grantor == nil {
+		return 0, errorAccessDenied
+	}
+
+	contact, err := s.UserSvc.FindEmergencyContact(grantor.ID, token.ID)
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed get emergency contact")
+		return 0, "failed get emergency contact"
+	}
+
+	if contact == nil {
+		return 0, errorAccessDenied
+	}
+
+	contact, err = refreshEmergencyContact(s.UserSvc, contact)
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed refresh emergency contact")
+		return 0, "failed get emergency contact"
+	}
+
+	if contact == nil || contact.Status != domain.EmergencyGranted {
+		return 0, errorAccessDenied
+	}
+
+	return grantor.ID, ""
+}
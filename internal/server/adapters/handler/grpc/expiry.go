@@ -0,0 +1,170 @@
+// Package handler contains gRPC handlers that implement the server-side logic for the application.
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/dedpnd/GophKeeper/internal/server/adapters/middleware"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+	"go.uber.org/zap"
+)
+
+// maxRotateDays is the longest rotation interval of a record, ten years.
+var maxRotateDays = 3650
+
+// SetRecordExpiry replaces the expiry date and the rotation interval of a
+// record the caller can write. Zero values remove them.
+func (s StorageHandler) SetRecordExpiry(ctx context.Context,
+	in *proto.SetRecordExpiryRequest) (*proto.SetRecordExpiryResponse, error) {
+	var resp proto.SetRecordExpiryResponse
+
+	token, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		s.Logger.Error(errorInvalidToken)
+		resp.Error = errorInvalidToken
+		return &resp, nil
+	}
+
+	if !token.HasScope(domain.ScopeWrite) {
+		resp.Error = errorAccessDenied
+		return &resp, nil
+	}
+
+	if in.ExpiresAt < 0 || in.RotateDays < 0 || int(in.RotateDays) > maxRotateDays {
+		resp.Error = "invalid expiry date or rotation interval"
+		return &resp, nil
+	}
+
+	rec, err := s.readRecord(ctx, int(in.Id), token.ID)
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed read record")
+		resp.Error = "failed read record"
+		return &resp, nil
+	}
+
+	if rec == nil || !canWriteRecord(rec, token.ID) || !token.CanAccessRecord(rec.ID, rec.Name) {
+		resp.Error = errRecordNotWritable.Error()
+		return &resp, nil
+	}
+
+	var expiresAt *time.Time
+	if in.ExpiresAt != 0 {
+		t := time.Unix(in.ExpiresAt, 0)
+		expiresAt = &t
+	}
+
+	err = s.Svc.SetRecordExpiry(rec.ID, expiresAt, int(in.RotateDays))
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed set record expiry")
+		resp.Error = "failed set record expiry"
+		return &resp, nil
+	}
+
+	return &resp, nil
+}
+
+// ListExpiringRecords returns the records which expire or are due for
+// rotation within the number of days, expired records included. The records
+// are of the team vault the call is targeted at, or the personal and shared
+// records of the caller.
+func (s StorageHandler) ListExpiringRecords(ctx context.Context,
+	in *proto.ListExpiringRecordsRequest) (*proto.ListExpiringRecordsResponse, error) {
+	var resp proto.ListExpiringRecordsResponse
+
+	token, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		s.Logger.Error(errorInvalidToken)
+		resp.Error = errorInvalidToken
+		return &resp, nil
+	}
+
+	if !token.HasScope(domain.ScopeRead) {
+		resp.Error = errorAccessDenied
+		return &resp, nil
+	}
+
+	if in.WithinDays < 0 {
+		resp.Error = "invalid number of days"
+		return &resp, nil
+	}
+
+	days := int(in.WithinDays)
+	if days == 0 {
+		days = domain.ExpiringDays
+	}
+
+	now := time.Now()
+	before := now.AddDate(0, 0, days)
+
+	var docs []*domain.Storage
+	var err error
+	if vault, ok := middleware.GetVaultFromContext(ctx); ok {
+		docs, err = s.Svc.ListVaultDueRecords(vault.ID, before)
+	} else {
+		docs, err = s.Svc.ListDueRecords(token.ID, before)
+	}
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed get expiring records")
+		resp.Error = "failed get expiring records"
+		return &resp, nil
+	}
+
+	resp.Units = make([]*proto.StorageUnit, 0, len(docs))
+	for _, v := range docs {
+		if !token.CanAccessRecord(v.ID, v.Name) {
+			continue
+		}
+
+		unit := storageUnit(v, token.ID)
+		unit.ExpiryStatus = expiryStatus(v, now, before)
+		resp.Units = append(resp.Units, unit)
+	}
+
+	return &resp, nil
+}
+
+/* UTILS. */
+
+// recordDueAt returns the date the record is due, the earlier of its expiry
+// date and the date of its next rotation, nil if it has neither.
+func recordDueAt(v *domain.Storage) *time.Time {
+	due := v.ExpiresAt
+
+	if v.RotateDays > 0 {
+		rotate := valueUpdatedAt(v).AddDate(0, 0, v.RotateDays)
+		if due == nil || rotate.Before(*due) {
+			due = &rotate
+		}
+	}
+
+	return due
+}
+
+// valueUpdatedAt returns the time of the last write of the value of the
+// record, records written before the time was saved return their last change.
+func valueUpdatedAt(v *domain.Storage) time.Time {
+	if v.ValueUpdatedAt != nil {
+		return *v.ValueUpdatedAt
+	}
+
+	return v.UpdatedAt
+}
+
+// expiryStatus returns the expiry status of the record at the time `now`,
+// records due before `soon` are expiring.
+func expiryStatus(v *domain.Storage, now time.Time, soon time.Time) string {
+	due := recordDueAt(v)
+
+	switch {
+	case due == nil:
+		return ""
+	case !due.After(now):
+		return domain.ExpiryExpired
+	case !due.After(soon):
+		return domain.ExpiryExpiring
+	default:
+		return domain.ExpiryOK
+	}
+}
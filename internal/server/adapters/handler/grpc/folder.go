@@ -0,0 +1,313 @@
+// Package handler contains gRPC handlers that implement the server-side logic for the application.
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dedpnd/GophKeeper/internal/record"
+	"github.com/dedpnd/GophKeeper/internal/server/adapters/middleware"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+var (
+	errorFolderNotFound = "folder not found"
+	errorRecordNotFound = "record not found"
+)
+
+// CreateFolder creates a folder of the personal records of the caller in the
+// parent folder, zero parent is the root.
+func (s StorageHandler) CreateFolder(ctx context.Context, in *proto.CreateFolderRequest) (*proto.CreateFolderResponse, error) {
+	var resp proto.CreateFolderResponse
+
+	token, errResp := folderToken(ctx, domain.ScopeWrite)
+	if errResp != "" {
+		resp.Error = errResp
+		return &resp, nil
+	}
+
+	name, err := record.CleanFolderName(in.Name)
+	if err != nil {
+		resp.Error = err.Error()
+		return &resp, nil
+	}
+
+	folder, err := s.Svc.CreateFolder(domain.Folder{Owner: token.ID, Parent: int(in.Parent), Name: name})
+	if err != nil {
+		resp.Error = s.folderError(err, name, "failed create folder")
+		return &resp, nil
+	}
+
+	// The parent folder is not found
+	if folder == nil {
+		resp.Error = errorFolderNotFound
+		return &resp, nil
+	}
+
+	resp.Folder = folderUnit(folder)
+	return &resp, nil
+}
+
+// RenameFolder renames a folder of the caller.
+func (s StorageHandler) RenameFolder(ctx context.Context, in *proto.RenameFolderRequest) (*proto.RenameFolderResponse, error) {
+	var resp proto.RenameFolderResponse
+
+	token, errResp := folderToken(ctx, domain.ScopeWrite)
+	if errResp != "" {
+		resp.Error = errResp
+		return &resp, nil
+	}
+
+	name, err := record.CleanFolderName(in.Name)
+	if err != nil {
+		resp.Error = err.Error()
+		return &resp, nil
+	}
+
+	ok, err := s.Svc.RenameFolder(int(in.Id), token.ID, name)
+	if err != nil {
+		resp.Error = s.folderError(err, name, "failed rename folder")
+		return &resp, nil
+	}
+
+	if !ok {
+		resp.Error = errorFolderNotFound
+		return &resp, nil
+	}
+
+	return &resp, nil
+}
+
+// DeleteFolder deletes a folder of the caller with its subfolders. The records
+// of the deleted folders are moved to the parent of the folder, not deleted.
+func (s StorageHandler) DeleteFolder(ctx context.Context, in *proto.DeleteFolderRequest) (*proto.DeleteFolderResponse, error) {
+	var resp proto.DeleteFolderResponse
+
+	token, errResp := folderToken(ctx, domain.ScopeWrite)
+	if errResp != "" {
+		resp.Error = errResp
+		return &resp, nil
+	}
+
+	ok, err := s.Svc.DeleteFolder(int(in.Id), token.ID)
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed delete folder")
+		resp.Error = "failed delete folder"
+		return &resp, nil
+	}
+
+	if !ok {
+		resp.Error = errorFolderNotFound
+		return &resp, nil
+	}
+
+	return &resp, nil
+}
+
+// ListFolders returns all folders of the caller.
+func (s StorageHandler) ListFolders(ctx context.Context, _ *proto.ListFoldersRequest) (*proto.ListFoldersResponse, error) {
+	var resp proto.ListFoldersResponse
+
+	token, errResp := folderToken(ctx, domain.ScopeRead)
+	if errResp != "" {
+		resp.Error = errResp
+		return &resp, nil
+	}
+
+	folders, err := s.Svc.ListFolders(token.ID)
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed get folders")
+		resp.Error = "failed get folders"
+		return &resp, nil
+	}
+
+	resp.Folders = make([]*proto.Folder, 0, len(folders))
+	for _, v := range folders {
+		resp.Folders = append(resp.Folders, folderUnit(v))
+	}
+
+	return &resp, nil
+}
+
+// MoveRecord moves a personal record of the caller to a folder, zero folder
+// is the root.
+func (s StorageHandler) MoveRecord(ctx context.Context, in *proto.MoveRecordRequest) (*proto.MoveRecordResponse, error) {
+	var resp proto.MoveRecordResponse
+
+	token, errResp := folderToken(ctx, domain.ScopeWrite)
+	if errResp != "" {
+		resp.Error = errResp
+		return &resp, nil
+	}
+
+	errResp = s.checkOwnRecord(int(in.Id), token)
+	if errResp != "" {
+		resp.Error = errResp
+		return &resp, nil
+	}
+
+	ok, err := s.Svc.MoveRecord(int(in.Id), token.ID, int(in.Folder))
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed move record")
+		resp.Error = "failed move record"
+		return &resp, nil
+	}
+
+	// The record is checked above, so the folder is not found, unless the
+	// record is deleted meanwhile
+	if !ok {
+		resp.Error = errorFolderNotFound
+		if in.Folder == 0 {
+			resp.Error = errorRecordNotFound
+		}
+		return &resp, nil
+	}
+
+	return &resp, nil
+}
+
+// TagRecord labels a personal record of the caller with tags.
+func (s StorageHandler) TagRecord(ctx context.Context, in *proto.TagRecordRequest) (*proto.TagRecordResponse, error) {
+	var resp proto.TagRecordResponse
+
+	token, tags, errResp := s.tagRequest(ctx, int(in.Id), in.Tags)
+	if errResp != "" {
+		resp.Error = errResp
+		return &resp, nil
+	}
+
+	err := s.Svc.TagRecord(int(in.Id), token.ID, tags)
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed tag record")
+		resp.Error = "failed tag record"
+		return &resp, nil
+	}
+
+	return &resp, nil
+}
+
+// UntagRecord removes tags from a personal record of the caller.
+func (s StorageHandler) UntagRecord(ctx context.Context, in *proto.UntagRecordRequest) (*proto.UntagRecordResponse, error) {
+	var resp proto.UntagRecordResponse
+
+	token, tags, errResp := s.tagRequest(ctx, int(in.Id), in.Tags)
+	if errResp != "" {
+		resp.Error = errResp
+		return &resp, nil
+	}
+
+	err := s.Svc.UntagRecord(int(in.Id), token.ID, tags)
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed untag record")
+		resp.Error = "failed untag record"
+		return &resp, nil
+	}
+
+	return &resp, nil
+}
+
+/* UTILS. */
+
+// folderToken returns the token of the caller if it has the scope. Folders
+// and tags organize personal records only.
+func folderToken(ctx context.Context, scope string) (middleware.JWTclaims, string) {
+	token, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		return token, errorInvalidToken
+	}
+
+	if !token.HasScope(scope) {
+		return token, errorAccessDenied
+	}
+
+	return token, ""
+}
+
+// tagRequest checks the request to change the tags of a record and returns
+// the token of the caller with the canonical tags.
+func (s StorageHandler) tagRequest(ctx context.Context, id int, tags []string) (middleware.JWTclaims, []string, string) {
+	token, errResp := folderToken(ctx, domain.ScopeWrite)
+	if errResp != "" {
+		return token, nil, errResp
+	}
+
+	clean, err := record.CleanTags(tags)
+	if err != nil {
+		return token, nil, err.Error()
+	}
+
+	if len(clean) == 0 {
+		return token, nil, "tags are required"
+	}
+
+	return token, clean, s.checkOwnRecord(id, token)
+}
+
+// checkOwnRecord checks that the record is a personal record of the caller
+// available to the token.
+func (s StorageHandler) checkOwnRecord(id int, token middleware.JWTclaims) string {
+	rec, err := s.Svc.ReadRecord(id, token.ID)
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed read record")
+		return "failed read record"
+	}
+
+	if rec == nil || rec.Owner != token.ID || !token.CanAccessRecord(rec.ID, rec.Name) {
+		return errorRecordNotFound
+	}
+
+	return ""
+}
+
+// folderError returns the error of the response for a failed write of the
+// folder, the name may be taken by another folder of the parent.
+func (s StorageHandler) folderError(err error, name string, msg string) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		return fmt.Sprintf("folder %q already exists", name)
+	}
+
+	s.Logger.With(zap.Error(err)).Error(msg)
+	return msg
+}
+
+// folderByPath returns the ID of the folder with the path of names like
+// `work/aws`, the root has the path `/`.
+func folderByPath(folders []*domain.Folder, path string) (int, bool) {
+	id := 0
+	for _, name := range strings.Split(path, record.Separator) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		found := false
+		for _, v := range folders {
+			if v.Parent == id && v.Name == name {
+				id, found = v.ID, true
+				break
+			}
+		}
+
+		if !found {
+			return 0, false
+		}
+	}
+
+	return id, true
+}
+
+// folderUnit converts the folder to the response form.
+func folderUnit(v *domain.Folder) *proto.Folder {
+	return &proto.Folder{
+		Id:     int32(v.ID),
+		Parent: int32(v.Parent),
+		Name:   v.Name,
+	}
+}
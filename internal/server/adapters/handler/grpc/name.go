@@ -0,0 +1,117 @@
+// Package handler contains gRPC handlers that implement the server-side logic for the application.
+package handler
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dedpnd/GophKeeper/internal/record"
+	"github.com/dedpnd/GophKeeper/internal/server/adapters/middleware"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+	"go.uber.org/zap"
+)
+
+// FindRecordByName finds the records of the caller, or of the team vault the
+// call is targeted at, by the hierarchical name. With `prefix` the records
+// whose names start with the name are returned, so `work/` lists the folder.
+// Records are returned in pages sorted by names. Nothing found is not an
+// error, the list is empty.
+func (s StorageHandler) FindRecordByName(ctx context.Context,
+	in *proto.FindRecordByNameRequest) (*proto.FindRecordByNameResponse, error) {
+	var resp proto.FindRecordByNameResponse
+
+	// Get token from context
+	token, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		s.Logger.Error(errorInvalidToken)
+		resp.Error = errorInvalidToken
+		return &resp, nil
+	}
+
+	if !token.HasScope(domain.ScopeRead) {
+		resp.Error = errorAccessDenied
+		return &resp, nil
+	}
+
+	name, err := findName(in.Name, in.Prefix)
+	if err != nil {
+		resp.Error = err.Error()
+		return &resp, nil
+	}
+
+	// Records of the user who granted emergency access to the caller
+	caller := token.ID
+	if in.Grantor != "" {
+		grantor, errResp := s.emergencyGrantor(in.Grantor, token)
+		if errResp != "" {
+			resp.Error = errResp
+			return &resp, nil
+		}
+
+		// Emergency access covers personal records, not team vaults
+		ctx = middleware.SetVaultToContext(ctx, middleware.VaultAccess{})
+		caller = grantor
+	}
+
+	page, err := recordPage(domain.SortName, in.PageToken)
+	if err != nil {
+		resp.Error = err.Error()
+		return &resp, nil
+	}
+
+	// One more record tells whether there is the next page
+	size := pageSize(in.PageSize)
+	page.Limit = size + 1
+
+	var rec []*domain.Storage
+	if vault, ok := middleware.GetVaultFromContext(ctx); ok {
+		rec, err = s.Svc.FindVaultRecordByName(vault.ID, name, in.Prefix, page)
+	} else {
+		rec, err = s.Svc.FindRecordByName(caller, name, in.Prefix, page)
+	}
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed find records")
+		resp.Error = "failed find records"
+		return &resp, nil
+	}
+
+	if len(rec) > size {
+		rec = rec[:size]
+		resp.NextPageToken = encodePageToken(nextPageToken(page, rec[size-1]))
+	}
+
+	resp.Units = make([]*proto.StorageUnit, 0, len(rec))
+	for _, v := range rec {
+		// Skip records hidden from the access token
+		if !token.CanAccessRecord(v.ID, v.Name) {
+			continue
+		}
+
+		resp.Units = append(resp.Units, storageUnit(v, token.ID))
+	}
+
+	return &resp, nil
+}
+
+// findName returns the canonical name to find. An empty prefix matches all
+// records, a prefix ending with the separator keeps it, so `work/` does not
+// match `workshop`.
+func findName(name string, prefix bool) (string, error) {
+	name = strings.TrimSpace(name)
+	if prefix && name == "" {
+		return "", nil
+	}
+
+	clean, err := record.CleanName(name)
+	if err != nil {
+		//nolint:wrapcheck // This legal return
+		return "", err
+	}
+
+	if prefix && strings.HasSuffix(name, record.Separator) {
+		clean += record.Separator
+	}
+
+	return clean, nil
+}
@@ -0,0 +1,64 @@
+// Package handler contains gRPC handlers that implement the server-side logic for the application.
+package handler
+
+import (
+	"context"
+
+	"github.com/dedpnd/GophKeeper/internal/record"
+	"github.com/dedpnd/GophKeeper/internal/server/adapters/middleware"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+	"go.uber.org/zap"
+)
+
+// NextOTPCounter takes the next HOTP counter of an OTP record the caller can
+// write. The returned counter is the number of codes issued before, the code
+// is generated by the agent with the counter of the otpauth URI plus it.
+func (s StorageHandler) NextOTPCounter(ctx context.Context,
+	in *proto.NextOTPCounterRequest) (*proto.NextOTPCounterResponse, error) {
+	var resp proto.NextOTPCounterResponse
+
+	token, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		s.Logger.Error(errorInvalidToken)
+		resp.Error = errorInvalidToken
+		return &resp, nil
+	}
+
+	if !token.HasScope(domain.ScopeWrite) {
+		resp.Error = errorAccessDenied
+		return &resp, nil
+	}
+
+	rec, err := s.readRecord(ctx, int(in.Id), token.ID)
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed read record")
+		resp.Error = "failed read record"
+		return &resp, nil
+	}
+
+	if rec == nil || !canWriteRecord(rec, token.ID) || !token.CanAccessRecord(rec.ID, rec.Name) {
+		resp.Error = errRecordNotWritable.Error()
+		return &resp, nil
+	}
+
+	if rec.Type != record.OTP {
+		resp.Error = "record is not an otp"
+		return &resp, nil
+	}
+
+	counter, found, err := s.Svc.NextOTPCounter(rec.ID)
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed take otp counter")
+		resp.Error = "failed take otp counter"
+		return &resp, nil
+	}
+
+	if !found {
+		resp.Error = errorRecordNotFound
+		return &resp, nil
+	}
+
+	resp.Counter = counter
+	return &resp, nil
+}
@@ -0,0 +1,277 @@
+// Package handler contains gRPC handlers that implement the server-side logic for the application.
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dedpnd/GophKeeper/internal/record"
+	"github.com/dedpnd/GophKeeper/internal/server/adapters/middleware"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+	"go.uber.org/zap"
+)
+
+// Sizes of pages of records.
+var (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+var (
+	errInvalidPageToken = errors.New("invalid page token")
+	errInvalidSort      = errors.New("invalid sort order")
+)
+
+// pageToken is the position of the next page of records. It is sent to the
+// client as an opaque string. Lists sorted by names or times keep the sort
+// order and the key of the last record of the page.
+type pageToken struct {
+	ID   int    `json:"id"`
+	Sort string `json:"sort,omitempty"`
+	Name string `json:"name,omitempty"`
+	Time int64  `json:"time,omitempty"`
+}
+
+// SearchRecords returns a page of the records of the caller, or of the team
+// vault the call is targeted at, which match the filters of the request.
+// The next page is requested with the token of the response, the last page
+// has no token.
+func (s StorageHandler) SearchRecords(ctx context.Context, in *proto.SearchRecordsRequest) (*proto.SearchRecordsResponse, error) {
+	var resp proto.SearchRecordsResponse
+
+	// Get token from context
+	token, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		s.Logger.Error(errorInvalidToken)
+		resp.Error = errorInvalidToken
+		return &resp, nil
+	}
+
+	if !token.HasScope(domain.ScopeRead) {
+		resp.Error = errorAccessDenied
+		return &resp, nil
+	}
+
+	page, err := decodePageToken(in.PageToken)
+	if err != nil {
+		resp.Error = err.Error()
+		return &resp, nil
+	}
+
+	tags, err := record.CleanTags(in.Tags)
+	if err != nil {
+		resp.Error = err.Error()
+		return &resp, nil
+	}
+
+	size := pageSize(in.PageSize)
+	filter := domain.RecordFilter{
+		Name:          in.Name,
+		Type:          in.Type,
+		Metadata:      in.Metadata,
+		Tags:          tags,
+		CreatedAfter:  unixTime(in.CreatedAfter),
+		CreatedBefore: unixTime(in.CreatedBefore),
+		UpdatedAfter:  unixTime(in.UpdatedAfter),
+		UpdatedBefore: unixTime(in.UpdatedBefore),
+		AfterID:       page.ID,
+		// One more record tells whether there is the next page
+		Limit: size + 1,
+	}
+
+	// Records of the user who granted emergency access to the caller
+	caller := token.ID
+	if in.Grantor != "" {
+		grantor, errResp := s.emergencyGrantor(in.Grantor, token)
+		if errResp != "" {
+			resp.Error = errResp
+			return &resp, nil
+		}
+
+		// Emergency access covers personal records, not team vaults
+		ctx = middleware.SetVaultToContext(ctx, middleware.VaultAccess{})
+		caller = grantor
+	}
+
+	vault, inVault := middleware.GetVaultFromContext(ctx)
+
+	// Folders are of the user, the folder of the path is found among them
+	if in.Folder != "" {
+		if inVault {
+			resp.Error = "folders are not available for vaults"
+			return &resp, nil
+		}
+
+		folder, errResp := s.folderFilter(caller, in.Folder)
+		if errResp != "" {
+			resp.Error = errResp
+			return &resp, nil
+		}
+		filter.Folder = &folder
+	}
+
+	var rec []*domain.Storage
+	if inVault {
+		rec, err = s.Svc.SearchVaultRecord(vault.ID, filter)
+	} else {
+		rec, err = s.Svc.SearchRecord(caller, filter)
+	}
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed search records")
+		resp.Error = "failed search records"
+		return &resp, nil
+	}
+
+	if len(rec) > size {
+		rec = rec[:size]
+		resp.NextPageToken = encodePageToken(pageToken{ID: rec[size-1].ID})
+	}
+
+	resp.Units = make([]*proto.StorageUnit, 0, len(rec))
+	for _, v := range rec {
+		// Skip records hidden from the access token
+		if !token.CanAccessRecord(v.ID, v.Name) {
+			continue
+		}
+
+		// Emergency access covers only the records owned by the grantor
+		if in.Grantor != "" && v.Owner != caller {
+			continue
+		}
+
+		resp.Units = append(resp.Units, storageUnit(v, token.ID))
+	}
+
+	return &resp, nil
+}
+
+// recordPage returns the first page of the list in the sort order, or the
+// page of the token. The sort order is the key optionally prefixed with "-"
+// for the descending order, an empty order sorts by IDs.
+func recordPage(sort string, token string) (domain.RecordPage, error) {
+	page := domain.RecordPage{Sort: strings.TrimPrefix(sort, "-"), Desc: strings.HasPrefix(sort, "-")}
+	if page.Sort == "" {
+		page.Sort = domain.SortID
+	}
+
+	switch page.Sort {
+	case domain.SortID, domain.SortName, domain.SortCreated, domain.SortUpdated:
+	default:
+		return page, fmt.Errorf("%w: %q", errInvalidSort, sort)
+	}
+
+	next, err := decodePageToken(token)
+	if err != nil {
+		return page, err
+	}
+
+	// The token of a page is valid only in the sort order of the list
+	if token != "" && next.Sort != sortOrder(page) {
+		return page, fmt.Errorf("%w: the token is of another sort order", errInvalidPageToken)
+	}
+
+	page.AfterID = next.ID
+	page.AfterName = next.Name
+	if next.Time != 0 {
+		page.AfterTime = time.Unix(0, next.Time)
+	}
+
+	return page, nil
+}
+
+// nextPageToken returns the token of the page after the record.
+func nextPageToken(page domain.RecordPage, last *domain.Storage) pageToken {
+	next := pageToken{ID: last.ID, Sort: sortOrder(page)}
+
+	switch page.Sort {
+	case domain.SortName:
+		next.Name = last.Name
+	case domain.SortCreated:
+		next.Time = last.CreatedAt.UnixNano()
+	case domain.SortUpdated:
+		next.Time = last.UpdatedAt.UnixNano()
+	default:
+	}
+
+	return next
+}
+
+// sortOrder returns the sort order of the page as it is requested.
+func sortOrder(page domain.RecordPage) string {
+	if page.Desc {
+		return "-" + page.Sort
+	}
+
+	return page.Sort
+}
+
+// folderFilter returns the ID of the folder of the user with the path.
+func (s StorageHandler) folderFilter(owner int, path string) (int, string) {
+	folders, err := s.Svc.ListFolders(owner)
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed get folders")
+		return 0, "failed get folders"
+	}
+
+	folder, ok := folderByPath(folders, path)
+	if !ok {
+		return 0, errorFolderNotFound
+	}
+
+	return folder, ""
+}
+
+// pageSize returns the size of the page asked for, limited by the maximum size.
+func pageSize(size int32) int {
+	if size <= 0 {
+		return defaultPageSize
+	}
+
+	return min(int(size), maxPageSize)
+}
+
+// encodePageToken encodes the position of the next page.
+func encodePageToken(page pageToken) string {
+	data, err := json.Marshal(page)
+	if err != nil {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodePageToken decodes the position of the page, an empty token is the
+// first page.
+func decodePageToken(token string) (pageToken, error) {
+	var page pageToken
+	if token == "" {
+		return page, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return page, fmt.Errorf("%w: %w", errInvalidPageToken, err)
+	}
+
+	err = json.Unmarshal(data, &page)
+	if err != nil {
+		return page, fmt.Errorf("%w: %w", errInvalidPageToken, err)
+	}
+
+	return page, nil
+}
+
+// unixTime converts the Unix time of a request, zero is no time.
+func unixTime(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(sec, 0)
+}
@@ -0,0 +1,174 @@
+// Package handler contains gRPC handlers that implement the server-side logic for the application.
+package handler
+
+import (
+	"context"
+
+	"github.com/dedpnd/GophKeeper/internal/server/adapters/middleware"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+	"go.uber.org/zap"
+)
+
+var errorShareManagement = "access tokens cannot manage shares"
+
+// ShareRecord grants another user, identified by login, read or write
+// access to a record of the caller.
+func (s StorageHandler) ShareRecord(ctx context.Context, in *proto.ShareRecordRequest) (*proto.ShareRecordResponse, error) {
+	var resp proto.ShareRecordResponse
+
+	// Get token from context
+	token, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		s.Logger.Error(errorInvalidToken)
+		resp.Error = errorInvalidToken
+		return &resp, nil
+	}
+
+	if token.IsAccessToken() {
+		resp.Error = errorShareManagement
+		return &resp, nil
+	}
+
+	if in.Permission != domain.PermissionRead && in.Permission != domain.PermissionWrite {
+		resp.Error = "unknown permission: " + in.Permission
+		return &resp, nil
+	}
+
+	// Only the owner can share a record
+	rec, err := s.Svc.ReadRecord(int(in.Id), token.ID)
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed read record")
+		resp.Error = "failed read record"
+		return &resp, nil
+	}
+
+	if rec == nil || rec.Owner != token.ID {
+		resp.Error = "record not found"
+		return &resp, nil
+	}
+
+	grantee, errResp := s.findGrantee(in.Login, token.ID)
+	if errResp != "" {
+		resp.Error = errResp
+		return &resp, nil
+	}
+
+	err = s.Svc.ShareRecord(domain.Share{
+		RecordID:   rec.ID,
+		Grantee:    grantee,
+		Permission: in.Permission,
+	})
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed share record")
+		resp.Error = "failed share record"
+		return &resp, nil
+	}
+
+	return &resp, nil
+}
+
+// RevokeShare removes the access of another user, identified by login,
+// to a record of the caller.
+func (s StorageHandler) RevokeShare(ctx context.Context, in *proto.RevokeShareRequest) (*proto.RevokeShareResponse, error) {
+	var resp proto.RevokeShareResponse
+
+	// Get token from context
+	token, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		s.Logger.Error(errorInvalidToken)
+		resp.Error = errorInvalidToken
+		return &resp, nil
+	}
+
+	if token.IsAccessToken() {
+		resp.Error = errorShareManagement
+		return &resp, nil
+	}
+
+	grantee, errResp := s.findGrantee(in.Login, token.ID)
+	if errResp != "" {
+		resp.Error = errResp
+		return &resp, nil
+	}
+
+	err := s.Svc.RevokeShare(int(in.Id), grantee, token.ID)
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed revoke share")
+		resp.Error = "failed revoke share"
+		return &resp, nil
+	}
+
+	return &resp, nil
+}
+
+// ListSharedWithMe returns the records other users shared with the caller.
+func (s StorageHandler) ListSharedWithMe(ctx context.Context,
+	in *proto.ListSharedWithMeRequest) (*proto.ListSharedWithMeResponse, error) {
+	var resp proto.ListSharedWithMeResponse
+
+	// Get token from context
+	token, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		s.Logger.Error(errorInvalidToken)
+		resp.Error = errorInvalidToken
+		return &resp, nil
+	}
+
+	if !token.HasScope(domain.ScopeRead) {
+		resp.Error = errorAccessDenied
+		return &resp, nil
+	}
+
+	rec, err := s.Svc.ListSharedWithMe(token.ID)
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed get shared records")
+		resp.Error = "failed get shared records"
+		return &resp, nil
+	}
+
+	resp.Units = make([]*proto.StorageUnit, 0, len(rec))
+	for _, v := range rec {
+		if !token.CanAccessRecord(v.ID, v.Name) {
+			continue
+		}
+
+		resp.Units = append(resp.Units, &proto.StorageUnit{
+			Id:         int32(v.ID),
+			Name:       v.Name,
+			Type:       v.Type,
+			Owner:      int32(v.Owner),
+			OwnerLogin: v.OwnerLogin,
+			Shared:     true,
+			Permission: v.Permission,
+			CreatedAt:  v.CreatedAt.Unix(),
+			UpdatedAt:  v.UpdatedAt.Unix(),
+			Revision:   v.Revision,
+			Versions:   decodeVersions(v.Versions),
+			Metadata:   decodeMetadata(v.Metadata),
+			Conflict:   v.Conflict,
+		})
+	}
+
+	return &resp, nil
+}
+
+// findGrantee returns the ID of the user with the login. On failure it
+// returns the error message for the response.
+func (s StorageHandler) findGrantee(login string, caller int) (int, string) {
+	user, err := s.UserSvc.FindUserByLogin(login)
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed get user")
+		return 0, "failed get user"
+	}
+
+	if user == nil {
+		return 0, "user not found"
+	}
+
+	if user.ID == caller {
+		return 0, "cannot share a record with yourself"
+	}
+
+	return user.ID, ""
+}
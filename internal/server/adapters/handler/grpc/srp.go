@@ -0,0 +1,257 @@
+// Package handler contains gRPC handlers that implement the server-side logic for the application.
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dedpnd/GophKeeper/internal/server/adapters/middleware"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+	"github.com/dedpnd/GophKeeper/internal/srp"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+var sizeSRPSession = 16
+var srpSessionTTL = time.Minute
+var maxSRPSessions = 10000
+var errorLegacyAuthDisabled = "password login is disabled, use SRP"
+
+// errSRPSessionsFull is returned when too many SRP logins are in progress.
+var errSRPSessionsFull = errors.New("too many srp sessions")
+
+// SRPSessions keeps the server state of SRP logins between the start and
+// finish calls. Every session can be finished only once and expires after
+// `srpSessionTTL`. At most `maxSRPSessions` logins are kept at a time, so
+// that unfinished logins cannot exhaust the server memory.
+type SRPSessions struct {
+	sessions map[string]srpSession
+	mu       sync.Mutex
+}
+
+type srpSession struct {
+	expires time.Time
+	server  *srp.Server
+	login   string
+	userID  int
+}
+
+// NewSRPSessions creates an empty SRP session store.
+func NewSRPSessions() *SRPSessions {
+	return &SRPSessions{
+		sessions: make(map[string]srpSession),
+	}
+}
+
+// put saves the session and returns its random ID. Expired sessions are
+// removed on every call, if the store is still full `errSRPSessionsFull`
+// is returned.
+func (s *SRPSessions) put(sess srpSession) (string, error) {
+	b := make([]byte, sizeSRPSession)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed generate byte: %w", err)
+	}
+	id := hex.EncodeToString(b)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, v := range s.sessions {
+		if v.expires.Before(now) {
+			delete(s.sessions, k)
+		}
+	}
+
+	if len(s.sessions) >= maxSRPSessions {
+		return "", errSRPSessionsFull
+	}
+
+	sess.expires = now.Add(srpSessionTTL)
+	s.sessions[id] = sess
+
+	return id, nil
+}
+
+// take returns the session and removes it from the store.
+func (s *SRPSessions) take(id string) (srpSession, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[id]
+	delete(s.sessions, id)
+
+	if !ok || sess.expires.Before(time.Now()) {
+		return srpSession{}, false
+	}
+
+	return sess, true
+}
+
+// SRPRegister handles the registration of a new user with SRP. The agent
+// sends only the salt and the verifier, the password never reaches the server.
+func (h UserHandler) SRPRegister(ctx context.Context, in *proto.SRPRegisterRequest) (*proto.SRPRegisterResponse, error) {
+	var res proto.SRPRegisterResponse
+
+	if in.Login == "" || len(in.Salt) == 0 || len(in.Verifier) == 0 {
+		res.Error = "login or verifier incorrect"
+		return &res, nil
+	}
+
+	user, err := h.Svc.CreateUserWithVerifier(in.Login, in.Salt, in.Verifier)
+	if err != nil {
+		h.Logger.With(zap.Error(err)).Error("failed create user")
+
+		res.Error = "failed create user"
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			res.Error = "this user exists"
+		}
+
+		return &res, nil
+	}
+
+	token, err := getJWT(h.JWTkey, user.ID, user.Login)
+	if err != nil {
+		h.Logger.With(zap.Error(err)).Error("failed create jwt token")
+		res.Error = "failed create jwt token"
+		return &res, nil
+	}
+
+	res.Jwt = *token
+
+	return &res, nil
+}
+
+// SRPLoginStart handles the first step of the SRP login. It returns the
+// user's salt, the server public value and the ID of the login session.
+func (h UserHandler) SRPLoginStart(ctx context.Context, in *proto.SRPLoginStartRequest) (*proto.SRPLoginStartResponse, error) {
+	var res proto.SRPLoginStartResponse
+
+	if h.SRP == nil {
+		res.Error = "srp login is not available"
+		return &res, nil
+	}
+
+	user, err := h.Svc.FindUserByLogin(in.Login)
+	if err != nil {
+		h.Logger.With(zap.Error(err)).Error("failed get user")
+		res.Error = "failed get user"
+		return &res, nil
+	}
+
+	if user == nil {
+		res.Error = "user not found"
+		return &res, nil
+	}
+
+	if len(user.Verifier) == 0 {
+		res.Error = "srp is not set up for this user, sign in with password to migrate"
+		return &res, nil
+	}
+
+	server, err := srp.NewServer(user.Verifier, in.A)
+	if err != nil {
+		res.Error = "login or password incorrect"
+		//nolint:nilerr // This legal return
+		return &res, nil
+	}
+
+	session, err := h.SRP.put(srpSession{
+		server: server,
+		login:  user.Login,
+		userID: user.ID,
+	})
+	if errors.Is(err, errSRPSessionsFull) {
+		h.Logger.Warn("srp session limit reached")
+		res.Error = "too many login attempts, try again later"
+		return &res, nil
+	}
+
+	if err != nil {
+		h.Logger.With(zap.Error(err)).Error("failed create srp session")
+		res.Error = "internal server error"
+		return &res, nil
+	}
+
+	res.Session = session
+	res.Salt = user.Salt
+	res.B = server.Public()
+
+	return &res, nil
+}
+
+// SRPLoginFinish handles the second step of the SRP login. It verifies
+// the client proof and, if it is correct, returns the server proof and
+// a JWT token for the user.
+func (h UserHandler) SRPLoginFinish(ctx context.Context, in *proto.SRPLoginFinishRequest) (*proto.SRPLoginFinishResponse, error) {
+	var res proto.SRPLoginFinishResponse
+
+	if h.SRP == nil {
+		res.Error = "srp login is not available"
+		return &res, nil
+	}
+
+	sess, ok := h.SRP.take(in.Session)
+	if !ok {
+		res.Error = "srp session not found"
+		return &res, nil
+	}
+
+	m2, err := sess.server.Verify(in.M1)
+	if err != nil {
+		res.Error = "login or password incorrect"
+		//nolint:nilerr // This legal return
+		return &res, nil
+	}
+
+	token, err := getJWT(h.JWTkey, sess.userID, sess.login)
+	if err != nil {
+		h.Logger.With(zap.Error(err)).Error("failed create jwt token")
+		res.Error = "failed create jwt token"
+		return &res, nil
+	}
+
+	res.M2 = m2
+	res.Jwt = *token
+
+	return &res, nil
+}
+
+// SetVerifier handles the migration of a signed in user from the password
+// flow to SRP. The bcrypt hash of the user is replaced with the verifier.
+func (h UserHandler) SetVerifier(ctx context.Context, in *proto.SetVerifierRequest) (*proto.SetVerifierResponse, error) {
+	var res proto.SetVerifierResponse
+
+	claims, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		h.Logger.Error(errorInvalidToken)
+		res.Error = errorInvalidToken
+		return &res, nil
+	}
+
+	if claims.IsAccessToken() {
+		res.Error = "access tokens cannot change credentials"
+		return &res, nil
+	}
+
+	if len(in.Salt) == 0 || len(in.Verifier) == 0 {
+		res.Error = "verifier incorrect"
+		return &res, nil
+	}
+
+	err := h.Svc.SetVerifier(claims.ID, in.Salt, in.Verifier)
+	if err != nil {
+		h.Logger.With(zap.Error(err)).Error("failed set verifier")
+		res.Error = "failed set verifier"
+		return &res, nil
+	}
+
+	return &res, nil
+}
@@ -12,22 +12,33 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
+	"github.com/dedpnd/GophKeeper/internal/record"
 	"github.com/dedpnd/GophKeeper/internal/server/adapters/middleware"
 	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
 	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
 	"github.com/dedpnd/GophKeeper/internal/server/core/services"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
 	"go.uber.org/zap"
 )
 
+// StorageHandler is a gRPC handler that implements the `StorageServer` interface
+// defined in the `proto` package. It uses the `StorageService` for records, the
+// `UserService` (`UserSvc`) to resolve the logins of users records are shared with
+// and the `EventService` (`Events`) to notify the watchers about changes of records.
 type StorageHandler struct {
 	proto.UnimplementedStorageServer
 	Svc       services.StorageService
+	UserSvc   services.UserService
+	Events    *services.EventService
 	Logger    *zap.Logger
 	MasterKey string
 }
 
 var errorInvalidToken = "invalid token"
+var errorAccessDenied = "access denied"
 var errorCloseStream = "failed close stream: %w"
 
 // ReadAllRecord read all record from BD.
@@ -42,23 +53,63 @@ func (s StorageHandler) ReadAllRecord(ctx context.Context, in *proto.ReadAllReco
 		return &resp, nil
 	}
 
+	if !token.HasScope(domain.ScopeRead) {
+		resp.Error = errorAccessDenied
+		return &resp, nil
+	}
+
+	// Records of the user who granted emergency access to the caller
+	caller := token.ID
+	if in.Grantor != "" {
+		grantor, errResp := s.emergencyGrantor(in.Grantor, token)
+		if errResp != "" {
+			resp.Error = errResp
+			return &resp, nil
+		}
+
+		// Emergency access covers personal records, not team vaults
+		ctx = middleware.SetVaultToContext(ctx, middleware.VaultAccess{})
+		caller = grantor
+	}
+
+	page, err := recordPage(in.Sort, in.PageToken)
+	if err != nil {
+		resp.Error = err.Error()
+		return &resp, nil
+	}
+
+	// Without the size of the page the page has the default size. One more
+	// record tells whether there is the next page
+	size := pageSize(in.PageSize)
+	page.Limit = size + 1
+
 	// Get data from BD
-	rec, err := s.Svc.ReadAllRecord(token.ID)
+	rec, err := s.readAllRecord(ctx, caller, page)
 	if err != nil {
 		s.Logger.With(zap.Error(err)).Error("failed get all records")
 		resp.Error = "failed get all records"
 		return &resp, nil
 	}
 
+	if len(rec) > size {
+		rec = rec[:size]
+		resp.NextPageToken = encodePageToken(nextPageToken(page, rec[size-1]))
+	}
+
 	// Preparing response
 	respSlice := make([]*proto.StorageUnit, 0, len(rec))
 	for _, v := range rec {
-		respSlice = append(respSlice, &proto.StorageUnit{
-			Id:    int32(v.ID),
-			Name:  v.Name,
-			Type:  v.Type,
-			Owner: int32(v.Owner),
-		})
+		// Skip records hidden from the access token
+		if !token.CanAccessRecord(v.ID, v.Name) {
+			continue
+		}
+
+		// Emergency access covers only the records owned by the grantor
+		if in.Grantor != "" && v.Owner != caller {
+			continue
+		}
+
+		respSlice = append(respSlice, storageUnit(v, token.ID))
 	}
 
 	resp.Units = respSlice
@@ -77,15 +128,34 @@ func (s StorageHandler) ReadRecord(ctx context.Context, in *proto.ReadRecordRequ
 		return &resp, nil
 	}
 
+	if !token.HasScope(domain.ScopeRead) {
+		resp.Error = errorAccessDenied
+		return &resp, nil
+	}
+
+	// Record of the user who granted emergency access to the caller
+	caller := token.ID
+	if in.Grantor != "" {
+		grantor, errResp := s.emergencyGrantor(in.Grantor, token)
+		if errResp != "" {
+			resp.Error = errResp
+			return &resp, nil
+		}
+
+		// Emergency access covers personal records, not team vaults
+		ctx = middleware.SetVaultToContext(ctx, middleware.VaultAccess{})
+		caller = grantor
+	}
+
 	// Get record from BD
-	rec, err := s.Svc.ReadRecord(int(in.Id), token.ID)
+	rec, err := s.readRecord(ctx, int(in.Id), caller)
 	if err != nil {
 		s.Logger.With(zap.Error(err)).Error("failed read record")
 		resp.Error = "failed read record"
 		return &resp, nil
 	}
 
-	if rec == nil {
+	if rec == nil || !token.CanAccessRecord(rec.ID, rec.Name) || (in.Grantor != "" && rec.Owner != caller) {
 		resp.Error = "record not found"
 		return &resp, nil
 	}
@@ -101,6 +171,11 @@ func (s StorageHandler) ReadRecord(ctx context.Context, in *proto.ReadRecordRequ
 	resp.Name = rec.Name
 	resp.Type = rec.Type
 	resp.Data = data
+	resp.Owner = int32(rec.Owner)
+	resp.OwnerLogin = rec.OwnerLogin
+	resp.Versions = decodeVersions(rec.Versions)
+	resp.Metadata = decodeMetadata(rec.Metadata)
+	resp.Conflict = rec.Conflict
 
 	return &resp, nil
 }
@@ -110,6 +185,8 @@ func (s StorageHandler) WriteRecord(stream proto.Storage_WriteRecordServer) erro
 	var resp proto.WriteRecordResponse
 	var fileName string
 	var fileType string
+	var fileID int
+	var write recordWrite
 
 	// For chunk
 	buffer := &bytes.Buffer{}
@@ -150,6 +227,23 @@ func (s StorageHandler) WriteRecord(stream proto.Storage_WriteRecordServer) erro
 			fileType = chunk.GetType()
 		}
 
+		if fileID == 0 {
+			fileID = int(chunk.GetId())
+		}
+
+		// Revision vector and metadata are sent with the first chunk
+		if write.device == "" {
+			write.device = chunk.GetDevice()
+		}
+
+		if write.versions == nil {
+			write.versions = chunk.GetVersions()
+		}
+
+		if write.metadata == nil {
+			write.metadata = chunk.GetMetadata()
+		}
+
 		// Write the data to the buffer
 		if _, err := buffer.Write(chunk.GetData()); err != nil {
 			s.Logger.With(zap.Error(err)).Error("failed write chunk to buffer")
@@ -162,6 +256,52 @@ func (s StorageHandler) WriteRecord(stream proto.Storage_WriteRecordServer) erro
 		}
 	}
 
+	// Names are hierarchical paths, an update without a name keeps the name
+	if fileName != "" || fileID == 0 {
+		name, err := record.CleanName(fileName)
+		if err != nil {
+			resp.Error = err.Error()
+
+			err := stream.SendAndClose(&resp)
+			if err != nil {
+				return fmt.Errorf(errorCloseStream, err)
+			}
+
+			return nil
+		}
+
+		fileName = name
+	}
+
+	// Check token restrictions, new records are checked by name only
+	allowed := token.CanAccessRecord(fileID, fileName)
+	if fileID == 0 {
+		allowed = token.CanCreateRecord(fileName)
+	}
+
+	// Tokens restricted to record IDs update the records, but never create them
+	if fileID == 0 && token.HasScope(domain.ScopeWrite) && len(token.RecordIDs) > 0 {
+		resp.Error = errorTokenRecordsOnly
+
+		err := stream.SendAndClose(&resp)
+		if err != nil {
+			return fmt.Errorf(errorCloseStream, err)
+		}
+
+		return nil
+	}
+
+	if !token.HasScope(domain.ScopeWrite) || !allowed {
+		resp.Error = errorAccessDenied
+
+		err := stream.SendAndClose(&resp)
+		if err != nil {
+			return fmt.Errorf(errorCloseStream, err)
+		}
+
+		return nil
+	}
+
 	// Encription data
 	data, key, err := encryptionData(s.MasterKey, buffer.Bytes())
 	if err != nil {
@@ -176,15 +316,47 @@ func (s StorageHandler) WriteRecord(stream proto.Storage_WriteRecordServer) erro
 
 	// Prepare record for save
 	var unit = domain.Storage{
-		Name:  fileName,
-		Type:  fileType,
-		Value: data,
-		Key:   key,
-		Owner: token.ID,
+		Name:     fileName,
+		Type:     fileType,
+		Value:    data,
+		Key:      key,
+		Owner:    token.ID,
+		Versions: encodeJSON(nextVersions(nil, write.device)),
+		Metadata: encodeJSON(write.metadata),
+	}
+
+	// Record of a team vault
+	if vault, ok := middleware.GetVaultFromContext(stream.Context()); ok {
+		unit.Vault = vault.ID
+	}
+
+	// Write recorn in BD, an existing record is replaced
+	if fileID != 0 {
+		resp.Versions, resp.Conflict, err = s.updateRecord(stream.Context(), token.ID, fileID, unit, write)
+	} else {
+		resp.Versions = decodeVersions(unit.Versions)
+		err = s.Svc.WriteRecord(unit)
+	}
+
+	// Names are unique per owner and per team vault
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		resp.Error = fmt.Sprintf("record %q already exists", unit.Name)
+	}
+
+	if errors.Is(err, errRecordNotWritable) || errors.Is(err, errRecordValueOnly) {
+		resp.Error = err.Error()
+	}
+
+	if resp.Error != "" {
+		err := stream.SendAndClose(&resp)
+		if err != nil {
+			return fmt.Errorf(errorCloseStream, err)
+		}
+
+		return nil
 	}
 
-	// Write recorn in BD
-	err = s.Svc.WriteRecord(unit)
 	if err != nil {
 		s.Logger.With(zap.Error(err)).Error("failed write record")
 		resp.Error = "failed write record"
@@ -216,8 +388,33 @@ func (s StorageHandler) DeleteRecord(ctx context.Context, in *proto.DeleteRecord
 		return &resp, nil
 	}
 
+	if !token.HasScope(domain.ScopeDelete) {
+		resp.Error = errorAccessDenied
+		return &resp, nil
+	}
+
+	// Restricted tokens can delete only the records they can see
+	if token.IsAccessToken() {
+		rec, err := s.readRecord(ctx, int(in.Id), token.ID)
+		if err != nil {
+			s.Logger.With(zap.Error(err)).Error("failed read record")
+			resp.Error = "failed delete record"
+			return &resp, nil
+		}
+
+		if rec == nil || !token.CanAccessRecord(rec.ID, rec.Name) {
+			resp.Error = "record not found"
+			return &resp, nil
+		}
+	}
+
 	// Delete record
-	err := s.Svc.DeleteRecord(int(in.Id), token.ID)
+	var err error
+	if vault, ok := middleware.GetVaultFromContext(ctx); ok {
+		err = s.Svc.DeleteVaultRecord(int(in.Id), vault.ID)
+	} else {
+		err = s.Svc.DeleteRecord(int(in.Id), token.ID)
+	}
 	if err != nil {
 		s.Logger.With(zap.Error(err)).Error("failed delete record")
 		resp.Error = "failed delete record"
@@ -229,6 +426,167 @@ func (s StorageHandler) DeleteRecord(ctx context.Context, in *proto.DeleteRecord
 
 /* UTILS. */
 
+var errRecordNotWritable = errors.New("record not found or not writable")
+var errRecordValueOnly = errors.New("shared record can be updated by value only")
+
+// readAllRecord returns the page of the records of the team vault the call
+// is targeted at, or of the personal and shared records of the caller.
+func (s StorageHandler) readAllRecord(ctx context.Context, caller int, page domain.RecordPage) ([]*domain.Storage, error) {
+	if vault, ok := middleware.GetVaultFromContext(ctx); ok {
+		//nolint:wrapcheck // This legal return
+		return s.Svc.ReadAllVaultRecord(vault.ID, page)
+	}
+
+	//nolint:wrapcheck // This legal return
+	return s.Svc.ReadAllRecord(caller, page)
+}
+
+// readRecord returns the record of the team vault the call is targeted at,
+// or the personal or shared record of the caller.
+func (s StorageHandler) readRecord(ctx context.Context, id int, caller int) (*domain.Storage, error) {
+	if vault, ok := middleware.GetVaultFromContext(ctx); ok {
+		//nolint:wrapcheck // This legal return
+		return s.Svc.ReadVaultRecord(id, vault.ID)
+	}
+
+	//nolint:wrapcheck // This legal return
+	return s.Svc.ReadRecord(id, caller)
+}
+
+// updateRecord replaces the value of an existing record. The caller must be
+// the owner of the record or have the write permission of a share. For team
+// vaults the role of the caller is checked by the vault interceptor.
+// If the writer has not seen all the writes of the record, the value is kept
+// as a conflicting version and the record is not changed. It returns the
+// revision vector of the written value and whether it is a conflict.
+func (s StorageHandler) updateRecord(ctx context.Context, caller int, id int,
+	unit domain.Storage, write recordWrite) (map[string]int64, bool, error) {
+	rec, err := s.readRecord(ctx, id, caller)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed read record: %w", err)
+	}
+
+	if rec == nil || !canWriteRecord(rec, caller) {
+		return nil, false, errRecordNotWritable
+	}
+
+	// Grantees of a share update the value only, the name, type and
+	// metadata stay as the owner set them
+	if rec.Vault == 0 && rec.Owner != caller {
+		if (unit.Name != "" && unit.Name != rec.Name) || (unit.Type != "" && unit.Type != rec.Type) {
+			return nil, false, errRecordValueOnly
+		}
+
+		unit.Name = rec.Name
+		unit.Type = rec.Type
+		unit.Metadata = rec.Metadata
+	}
+
+	unit.ID = rec.ID
+	unit.Owner = rec.Owner
+	if unit.Name == "" {
+		unit.Name = rec.Name
+	}
+
+	if write.metadata == nil {
+		unit.Metadata = rec.Metadata
+	}
+
+	// Writes without a revision vector replace the record
+	current := decodeVersions(rec.Versions)
+	if len(write.versions) != 0 && !descends(write.versions, current) {
+		return s.createConflict(rec.ID, caller, unit, nextVersions(write.versions, write.device), write.device)
+	}
+
+	versions := nextVersions(current, write.device)
+	unit.Versions = encodeJSON(versions)
+
+	// The record is replaced only if no other write changed it since it was read
+	ok, err := s.Svc.UpdateRecord(unit, rec.Revision)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed update record: %w", err)
+	}
+
+	if !ok {
+		return s.createConflict(rec.ID, caller, unit, versions, write.device)
+	}
+
+	return versions, false, nil
+}
+
+// createConflict keeps the written value as a version conflicting with the
+// current value of the record. It returns the revision vector of the version.
+func (s StorageHandler) createConflict(id int, caller int, unit domain.Storage,
+	versions map[string]int64, device string) (map[string]int64, bool, error) {
+	err := s.Svc.CreateConflict(domain.Conflict{
+		RecordID: id,
+		Owner:    caller,
+		Device:   deviceName(device),
+		Name:     unit.Name,
+		Type:     unit.Type,
+		Value:    unit.Value,
+		Key:      unit.Key,
+		Versions: encodeJSON(versions),
+		Metadata: unit.Metadata,
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed create conflict: %w", err)
+	}
+
+	return versions, true, nil
+}
+
+// storageUnit converts the record to the unit of a list of records.
+func storageUnit(v *domain.Storage, caller int) *proto.StorageUnit {
+	unit := &proto.StorageUnit{
+		Id:         int32(v.ID),
+		Name:       v.Name,
+		Type:       v.Type,
+		Owner:      int32(v.Owner),
+		OwnerLogin: v.OwnerLogin,
+		Shared:     v.Vault == 0 && v.Owner != caller,
+		Permission: v.Permission,
+		CreatedAt:  v.CreatedAt.Unix(),
+		UpdatedAt:  v.UpdatedAt.Unix(),
+		Revision:   v.Revision,
+		Versions:   decodeVersions(v.Versions),
+		Metadata:   decodeMetadata(v.Metadata),
+		Conflict:   v.Conflict,
+		OtpCounter: v.OTPCounter,
+	}
+
+	// Folders are of the owner, other users see the record at the root
+	if v.Vault == 0 && v.Owner == caller {
+		unit.Folder = int32(v.Folder)
+	}
+
+	if v.Tags != "" {
+		unit.Tags = strings.Split(v.Tags, record.TagSeparator)
+	}
+
+	if v.DeletedAt != nil {
+		unit.DeletedAt = v.DeletedAt.Unix()
+	}
+
+	if v.ExpiresAt != nil {
+		unit.ExpiresAt = v.ExpiresAt.Unix()
+	}
+
+	unit.RotateDays = int32(v.RotateDays)
+	if due := recordDueAt(v); due != nil {
+		now := time.Now()
+		unit.DueAt = due.Unix()
+		unit.ExpiryStatus = expiryStatus(v, now, now.AddDate(0, 0, domain.ExpiringDays))
+	}
+
+	return unit
+}
+
+// canWriteRecord reports whether the caller can replace the value of the record.
+func canWriteRecord(rec *domain.Storage, caller int) bool {
+	return rec.Vault != 0 || rec.Owner == caller || rec.Permission == domain.PermissionWrite
+}
+
 var sizeRandomKey = 16
 
 func encryptionData(mk string, data []byte) (string, string, error) {
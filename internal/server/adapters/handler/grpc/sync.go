@@ -0,0 +1,75 @@
+// Package handler contains gRPC handlers that implement the server-side logic for the application.
+package handler
+
+import (
+	"context"
+
+	"github.com/dedpnd/GophKeeper/internal/server/adapters/middleware"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+	"go.uber.org/zap"
+)
+
+// Sync returns the personal records of the caller, or the records of the team
+// vault the call is targeted at, changed since the `cursor`, and the IDs of
+// the records deleted since then. The cursor is opaque to the client, it is
+// ordered by commits, so a change committed after the call is never skipped
+// by the next one. Zero returns all records. Record values are not returned,
+// they are read with `ReadRecord`.
+func (s StorageHandler) Sync(ctx context.Context, in *proto.SyncRequest) (*proto.SyncResponse, error) {
+	var resp proto.SyncResponse
+
+	// Get token from context
+	token, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		s.Logger.Error(errorInvalidToken)
+		resp.Error = errorInvalidToken
+		return &resp, nil
+	}
+
+	if !token.HasScope(domain.ScopeRead) {
+		resp.Error = errorAccessDenied
+		return &resp, nil
+	}
+
+	var vaultID int
+	if vault, ok := middleware.GetVaultFromContext(ctx); ok {
+		vaultID = vault.ID
+	}
+
+	rec, cursor, err := s.Svc.SyncRecord(token.ID, vaultID, in.Cursor)
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed sync records")
+		resp.Error = "failed sync records"
+		return &resp, nil
+	}
+
+	resp.Cursor = cursor
+	for _, v := range rec {
+		// Skip records hidden from the access token
+		if !token.CanAccessRecord(v.ID, v.Name) {
+			continue
+		}
+
+		if v.Deleted {
+			resp.Deleted = append(resp.Deleted, int32(v.ID))
+			continue
+		}
+
+		resp.Units = append(resp.Units, &proto.StorageUnit{
+			Id:         int32(v.ID),
+			Name:       v.Name,
+			Type:       v.Type,
+			Owner:      int32(v.Owner),
+			OwnerLogin: v.OwnerLogin,
+			CreatedAt:  v.CreatedAt.Unix(),
+			UpdatedAt:  v.UpdatedAt.Unix(),
+			Revision:   v.Revision,
+			Versions:   decodeVersions(v.Versions),
+			Metadata:   decodeMetadata(v.Metadata),
+			Conflict:   v.Conflict,
+		})
+	}
+
+	return &resp, nil
+}
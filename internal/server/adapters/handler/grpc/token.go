@@ -0,0 +1,181 @@
+// Package handler contains gRPC handlers that implement the server-side logic for the application.
+package handler
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dedpnd/GophKeeper/internal/server/adapters/middleware"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+	"go.uber.org/zap"
+)
+
+var (
+	errorTokenManagement  = "access tokens cannot manage access tokens"
+	errorTokenNotFound    = "access token not found"
+	errorTokenRecordsOnly = "access token is restricted to existing records"
+)
+
+var supportedScopes = []string{domain.ScopeRead, domain.ScopeWrite, domain.ScopeDelete}
+
+// CreateAccessToken handles the creation of a personal access token. The token
+// value is returned only once, the database keeps its hash. Tokens can be created
+// only from an interactive session, not with another access token.
+func (h UserHandler) CreateAccessToken(ctx context.Context,
+	in *proto.CreateAccessTokenRequest) (*proto.CreateAccessTokenResponse, error) {
+	var res proto.CreateAccessTokenResponse
+
+	claims, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		h.Logger.Error(errorInvalidToken)
+		res.Error = errorInvalidToken
+		return &res, nil
+	}
+
+	if claims.IsAccessToken() {
+		res.Error = errorTokenManagement
+		return &res, nil
+	}
+
+	if in.Name == "" || len(in.Scopes) == 0 {
+		res.Error = "name or scopes incorrect"
+		return &res, nil
+	}
+
+	for _, v := range in.Scopes {
+		if !slices.Contains(supportedScopes, v) {
+			res.Error = "unknown scope: " + v
+			return &res, nil
+		}
+	}
+
+	ids := make([]int, 0, len(in.RecordIds))
+	for _, v := range in.RecordIds {
+		ids = append(ids, int(v))
+	}
+
+	value, err := middleware.GenerateAccessToken()
+	if err != nil {
+		h.Logger.With(zap.Error(err)).Error("failed generate access token")
+		res.Error = "internal server error"
+		return &res, nil
+	}
+
+	token := domain.AccessToken{
+		Owner:      claims.ID,
+		Name:       in.Name,
+		Hash:       middleware.HashAccessToken(value),
+		Scopes:     strings.Join(in.Scopes, ","),
+		RecordIDs:  middleware.JoinIDs(ids),
+		NamePrefix: in.NamePrefix,
+	}
+
+	if in.TtlDays > 0 {
+		exp := time.Now().AddDate(0, 0, int(in.TtlDays))
+		token.ExpiresAt = &exp
+	}
+
+	rec, err := h.Svc.CreateAccessToken(token)
+	if err != nil {
+		h.Logger.With(zap.Error(err)).Error("failed create access token")
+		res.Error = "failed create access token"
+		return &res, nil
+	}
+
+	res.Id = int32(rec.ID)
+	res.Token = value
+
+	return &res, nil
+}
+
+// ListAccessTokens handles listing of the caller's personal access tokens.
+// Token values cannot be recovered, only their settings are returned.
+func (h UserHandler) ListAccessTokens(ctx context.Context,
+	in *proto.ListAccessTokensRequest) (*proto.ListAccessTokensResponse, error) {
+	var res proto.ListAccessTokensResponse
+
+	claims, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		h.Logger.Error(errorInvalidToken)
+		res.Error = errorInvalidToken
+		return &res, nil
+	}
+
+	if claims.IsAccessToken() {
+		res.Error = errorTokenManagement
+		return &res, nil
+	}
+
+	tokens, err := h.Svc.ListAccessTokens(claims.ID)
+	if err != nil {
+		h.Logger.With(zap.Error(err)).Error("failed get access tokens")
+		res.Error = "failed get access tokens"
+		return &res, nil
+	}
+
+	res.Tokens = make([]*proto.AccessTokenUnit, 0, len(tokens))
+	for _, v := range tokens {
+		ids, err := middleware.SplitIDs(v.RecordIDs)
+		if err != nil {
+			h.Logger.With(zap.Error(err)).Error("failed parse record ids")
+			res.Error = "failed get access tokens"
+			return &res, nil
+		}
+
+		unit := &proto.AccessTokenUnit{
+			Id:         int32(v.ID),
+			Name:       v.Name,
+			Scopes:     middleware.SplitList(v.Scopes),
+			NamePrefix: v.NamePrefix,
+			Revoked:    v.Revoked,
+		}
+
+		for _, id := range ids {
+			unit.RecordIds = append(unit.RecordIds, int32(id))
+		}
+
+		if v.ExpiresAt != nil {
+			unit.ExpiresAt = v.ExpiresAt.Unix()
+		}
+
+		res.Tokens = append(res.Tokens, unit)
+	}
+
+	return &res, nil
+}
+
+// RevokeAccessToken handles revocation of a personal access token of the caller.
+func (h UserHandler) RevokeAccessToken(ctx context.Context,
+	in *proto.RevokeAccessTokenRequest) (*proto.RevokeAccessTokenResponse, error) {
+	var res proto.RevokeAccessTokenResponse
+
+	claims, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		h.Logger.Error(errorInvalidToken)
+		res.Error = errorInvalidToken
+		return &res, nil
+	}
+
+	if claims.IsAccessToken() {
+		res.Error = errorTokenManagement
+		return &res, nil
+	}
+
+	ok, err := h.Svc.RevokeAccessToken(int(in.Id), claims.ID)
+	if err != nil {
+		h.Logger.With(zap.Error(err)).Error("failed revoke access token")
+		res.Error = "failed revoke access token"
+		return &res, nil
+	}
+
+	// Tokens of other users are not found either
+	if !ok {
+		res.Error = errorTokenNotFound
+		return &res, nil
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,172 @@
+// Package handler contains gRPC handlers that implement the server-side logic for the application.
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dedpnd/GophKeeper/internal/server/adapters/middleware"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+// ListTrash returns the deleted records of the team vault the call is
+// targeted at, or the deleted personal records of the caller.
+func (s StorageHandler) ListTrash(ctx context.Context, _ *proto.ListTrashRequest) (*proto.ListTrashResponse, error) {
+	var resp proto.ListTrashResponse
+
+	token, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		s.Logger.Error(errorInvalidToken)
+		resp.Error = errorInvalidToken
+		return &resp, nil
+	}
+
+	if !token.HasScope(domain.ScopeRead) {
+		resp.Error = errorAccessDenied
+		return &resp, nil
+	}
+
+	var docs []*domain.Storage
+	var err error
+	if vault, ok := middleware.GetVaultFromContext(ctx); ok {
+		docs, err = s.Svc.ListVaultTrash(vault.ID)
+	} else {
+		docs, err = s.Svc.ListTrash(token.ID)
+	}
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed get trash")
+		resp.Error = "failed get trash"
+		return &resp, nil
+	}
+
+	resp.Units = make([]*proto.StorageUnit, 0, len(docs))
+	for _, v := range docs {
+		if !token.CanAccessRecord(v.ID, v.Name) {
+			continue
+		}
+
+		resp.Units = append(resp.Units, storageUnit(v, token.ID))
+	}
+
+	return &resp, nil
+}
+
+// RestoreRecord moves a deleted record back from the trash. The record gets
+// its name back, unless another record took the name in the meantime.
+func (s StorageHandler) RestoreRecord(ctx context.Context, in *proto.RestoreRecordRequest) (*proto.RestoreRecordResponse, error) {
+	var resp proto.RestoreRecordResponse
+
+	token, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		s.Logger.Error(errorInvalidToken)
+		resp.Error = errorInvalidToken
+		return &resp, nil
+	}
+
+	if !token.HasScope(domain.ScopeWrite) {
+		resp.Error = errorAccessDenied
+		return &resp, nil
+	}
+
+	vault, inVault := middleware.GetVaultFromContext(ctx)
+
+	// Restricted tokens can restore only the records they can see
+	if token.IsAccessToken() {
+		var docs []*domain.Storage
+		var err error
+		if inVault {
+			docs, err = s.Svc.ListVaultTrash(vault.ID)
+		} else {
+			docs, err = s.Svc.ListTrash(token.ID)
+		}
+		if err != nil {
+			s.Logger.With(zap.Error(err)).Error("failed get trash")
+			resp.Error = "failed restore record"
+			return &resp, nil
+		}
+
+		if !trashAccess(docs, int(in.Id), token) {
+			resp.Error = errorRecordNotFound
+			return &resp, nil
+		}
+	}
+
+	var restored bool
+	var err error
+	if inVault {
+		restored, err = s.Svc.RestoreVaultRecord(int(in.Id), vault.ID)
+	} else {
+		restored, err = s.Svc.RestoreRecord(int(in.Id), token.ID)
+	}
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			resp.Error = "record with the same name already exists"
+			return &resp, nil
+		}
+
+		s.Logger.With(zap.Error(err)).Error("failed restore record")
+		resp.Error = "failed restore record"
+		return &resp, nil
+	}
+
+	if !restored {
+		resp.Error = errorRecordNotFound
+		return &resp, nil
+	}
+
+	return &resp, nil
+}
+
+// EmptyTrash permanently removes the deleted records of the team vault the
+// call is targeted at, or the deleted personal records of the caller.
+func (s StorageHandler) EmptyTrash(ctx context.Context, _ *proto.EmptyTrashRequest) (*proto.EmptyTrashResponse, error) {
+	var resp proto.EmptyTrashResponse
+
+	token, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		s.Logger.Error(errorInvalidToken)
+		resp.Error = errorInvalidToken
+		return &resp, nil
+	}
+
+	// Restricted tokens may see only a part of the trash
+	if !token.HasScope(domain.ScopeDelete) || token.IsAccessToken() {
+		resp.Error = errorAccessDenied
+		return &resp, nil
+	}
+
+	var count int64
+	var err error
+	if vault, ok := middleware.GetVaultFromContext(ctx); ok {
+		count, err = s.Svc.EmptyVaultTrash(vault.ID)
+	} else {
+		count, err = s.Svc.EmptyTrash(token.ID)
+	}
+	if err != nil {
+		s.Logger.With(zap.Error(err)).Error("failed empty trash")
+		resp.Error = "failed empty trash"
+		return &resp, nil
+	}
+
+	resp.Count = count
+	return &resp, nil
+}
+
+/* UTILS. */
+
+// trashAccess reports whether the record with the ID is in the trash and
+// available to the token.
+func trashAccess(docs []*domain.Storage, id int, token middleware.JWTclaims) bool {
+	for _, v := range docs {
+		if v.ID == id {
+			return token.CanAccessRecord(v.ID, v.Name)
+		}
+	}
+
+	return false
+}
@@ -22,12 +22,16 @@ import (
 // operations such as registration and login. The handler relies on the
 // `UserService` for the business logic and uses a `zap.Logger` for logging.
 // It also uses a JWT key (`JWTkey`) for creating JWT tokens during user
-// registration and login.
+// registration and login. The password based `Register` and `Login` calls
+// are served only when `LegacyAuth` is enabled, `SRP` keeps the state of
+// SRP logins.
 type UserHandler struct {
 	proto.UnimplementedUserServer
-	Svc    services.UserService
-	Logger *zap.Logger
-	JWTkey string
+	Svc        services.UserService
+	Logger     *zap.Logger
+	SRP        *SRPSessions
+	JWTkey     string
+	LegacyAuth bool
 }
 
 // Register handles the user registration gRPC call. It creates a new user
@@ -38,6 +42,11 @@ type UserHandler struct {
 func (h UserHandler) Register(ctx context.Context, in *proto.RegiserRequest) (*proto.RegisterResponse, error) {
 	var res proto.RegisterResponse
 
+	if !h.LegacyAuth {
+		res.Error = errorLegacyAuthDisabled
+		return &res, nil
+	}
+
 	if in.Login == "" || in.Password == "" {
 		res.Error = "login or password incorrect"
 		return &res, nil
@@ -81,6 +90,12 @@ func (h UserHandler) Register(ctx context.Context, in *proto.RegiserRequest) (*p
 // and returned as error responses.
 func (h UserHandler) Login(ctx context.Context, in *proto.LoginRequest) (*proto.LoginResponse, error) {
 	var res proto.LoginResponse
+
+	if !h.LegacyAuth {
+		res.Error = errorLegacyAuthDisabled
+		return &res, nil
+	}
+
 	user, err := h.Svc.FindUserByLogin(in.Login)
 	if err != nil {
 		h.Logger.With(zap.Error(err)).Error("failed get user")
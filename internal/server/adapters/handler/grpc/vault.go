@@ -0,0 +1,227 @@
+// Package handler contains gRPC handlers that implement the server-side logic for the application.
+package handler
+
+import (
+	"context"
+
+	"github.com/dedpnd/GophKeeper/internal/server/adapters/middleware"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+	"github.com/dedpnd/GophKeeper/internal/server/core/services"
+	"go.uber.org/zap"
+)
+
+var errorOrgManagement = "access tokens cannot manage organizations"
+var errorOrgNotFound = "organization not found"
+var errorLastOwner = "organization must keep at least one owner"
+
+// VaultHandler is a gRPC handler that implements the `VaultServer` interface
+// defined in the `proto` package. It manages organizations, their vaults and
+// members. Role permissions of the caller are checked by the vault interceptor
+// before the handler is called.
+type VaultHandler struct {
+	proto.UnimplementedVaultServer
+	Svc     services.VaultService
+	UserSvc services.UserService
+	Logger  *zap.Logger
+}
+
+// CreateOrganization creates a new organization, the caller becomes its owner.
+func (h VaultHandler) CreateOrganization(ctx context.Context,
+	in *proto.CreateOrganizationRequest) (*proto.CreateOrganizationResponse, error) {
+	var res proto.CreateOrganizationResponse
+
+	claims, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		h.Logger.Error(errorInvalidToken)
+		res.Error = errorInvalidToken
+		return &res, nil
+	}
+
+	if claims.IsAccessToken() {
+		res.Error = errorOrgManagement
+		return &res, nil
+	}
+
+	if in.Name == "" {
+		res.Error = "name incorrect"
+		return &res, nil
+	}
+
+	org, err := h.Svc.CreateOrganization(in.Name, claims.ID)
+	if err != nil {
+		h.Logger.With(zap.Error(err)).Error("failed create organization")
+		res.Error = "failed create organization"
+		return &res, nil
+	}
+
+	res.Id = int32(org.ID)
+
+	return &res, nil
+}
+
+// CreateVault adds a new vault to the organization.
+func (h VaultHandler) CreateVault(ctx context.Context, in *proto.CreateVaultRequest) (*proto.CreateVaultResponse, error) {
+	var res proto.CreateVaultResponse
+
+	access, _ := middleware.GetVaultFromContext(ctx)
+	if access.OrgID == 0 {
+		res.Error = errorOrgNotFound
+		return &res, nil
+	}
+
+	if in.Name == "" {
+		res.Error = "name incorrect"
+		return &res, nil
+	}
+
+	vault, err := h.Svc.CreateVault(domain.Vault{
+		OrgID: access.OrgID,
+		Name:  in.Name,
+	})
+	if err != nil {
+		h.Logger.With(zap.Error(err)).Error("failed create vault")
+		res.Error = "failed create vault"
+		return &res, nil
+	}
+
+	res.Id = int32(vault.ID)
+
+	return &res, nil
+}
+
+// AddMember invites a user, identified by login, to the organization with
+// the role, or changes the role of a member. Only owners can grant the owner role.
+func (h VaultHandler) AddMember(ctx context.Context, in *proto.AddMemberRequest) (*proto.AddMemberResponse, error) {
+	var res proto.AddMemberResponse
+
+	access, _ := middleware.GetVaultFromContext(ctx)
+	if access.OrgID == 0 {
+		res.Error = errorOrgNotFound
+		return &res, nil
+	}
+
+	if _, ok := domain.RolePermissions[in.Role]; !ok {
+		res.Error = "unknown role: " + in.Role
+		return &res, nil
+	}
+
+	if in.Role == domain.RoleOwner && !services.RoleAllows(access.Role, domain.ActionOwnOrg) {
+		res.Error = "only owners can add owners"
+		return &res, nil
+	}
+
+	user, errResp := h.findMemberUser(access.OrgID, in.Login, access.Role)
+	if errResp != "" {
+		res.Error = errResp
+		return &res, nil
+	}
+
+	ok, err := h.Svc.AddMember(domain.Member{
+		OrgID:  access.OrgID,
+		UserID: user.ID,
+		Role:   in.Role,
+	})
+	if err != nil {
+		h.Logger.With(zap.Error(err)).Error("failed add member")
+		res.Error = "failed add member"
+		return &res, nil
+	}
+
+	if !ok {
+		res.Error = errorLastOwner
+		return &res, nil
+	}
+
+	return &res, nil
+}
+
+// RemoveMember removes a user, identified by login, from the organization.
+func (h VaultHandler) RemoveMember(ctx context.Context, in *proto.RemoveMemberRequest) (*proto.RemoveMemberResponse, error) {
+	var res proto.RemoveMemberResponse
+
+	access, _ := middleware.GetVaultFromContext(ctx)
+	if access.OrgID == 0 {
+		res.Error = errorOrgNotFound
+		return &res, nil
+	}
+
+	user, errResp := h.findMemberUser(access.OrgID, in.Login, access.Role)
+	if errResp != "" {
+		res.Error = errResp
+		return &res, nil
+	}
+
+	ok, err := h.Svc.RemoveMember(access.OrgID, user.ID)
+	if err != nil {
+		h.Logger.With(zap.Error(err)).Error("failed remove member")
+		res.Error = "failed remove member"
+		return &res, nil
+	}
+
+	if !ok {
+		res.Error = errorLastOwner
+		return &res, nil
+	}
+
+	return &res, nil
+}
+
+// ListVaults returns the vaults of all organizations the caller is a member of.
+func (h VaultHandler) ListVaults(ctx context.Context, in *proto.ListVaultsRequest) (*proto.ListVaultsResponse, error) {
+	var res proto.ListVaultsResponse
+
+	claims, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		h.Logger.Error(errorInvalidToken)
+		res.Error = errorInvalidToken
+		return &res, nil
+	}
+
+	vaults, err := h.Svc.ListVaults(claims.ID)
+	if err != nil {
+		h.Logger.With(zap.Error(err)).Error("failed get vaults")
+		res.Error = "failed get vaults"
+		return &res, nil
+	}
+
+	res.Vaults = make([]*proto.VaultUnit, 0, len(vaults))
+	for _, v := range vaults {
+		res.Vaults = append(res.Vaults, &proto.VaultUnit{
+			Id:      int32(v.ID),
+			Name:    v.Name,
+			OrgId:   int32(v.OrgID),
+			OrgName: v.OrgName,
+			Role:    v.Role,
+		})
+	}
+
+	return &res, nil
+}
+
+// findMemberUser returns the user with the login. Owners of the organization
+// can be changed only by other owners. On failure it returns the error
+// message for the response.
+func (h VaultHandler) findMemberUser(orgID int, login string, callerRole string) (*domain.User, string) {
+	user, err := h.UserSvc.FindUserByLogin(login)
+	if err != nil {
+		h.Logger.With(zap.Error(err)).Error("failed get user")
+		return nil, "failed get user"
+	}
+
+	if user == nil {
+		return nil, "user not found"
+	}
+
+	member, err := h.Svc.FindMember(orgID, user.ID)
+	if err != nil {
+		h.Logger.With(zap.Error(err)).Error("failed get member")
+		return nil, "failed get member"
+	}
+
+	if member != nil && member.Role == domain.RoleOwner && !services.RoleAllows(callerRole, domain.ActionOwnOrg) {
+		return nil, "only owners can change owners"
+	}
+
+	return user, ""
+}
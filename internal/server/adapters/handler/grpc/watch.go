@@ -0,0 +1,106 @@
+// Package handler contains gRPC handlers that implement the server-side logic for the application.
+package handler
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/dedpnd/GophKeeper/internal/server/adapters/middleware"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+	"google.golang.org/grpc/metadata"
+)
+
+var errorWatchStream = "failed send event: %w"
+
+// Watch streams the events about records of the caller being created, changed
+// and deleted, including the records shared with the caller. Calls targeted at
+// a team vault receive the events of the vault. Headers are sent once the
+// caller is subscribed, so changes made after they are received are never
+// missed. If the caller does not keep up with the events, the stream ends
+// with an error and the caller should catch up with `Sync`.
+func (s StorageHandler) Watch(_ *proto.WatchRequest, stream proto.Storage_WatchServer) error {
+	ctx := stream.Context()
+
+	// Get token from context
+	token, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		s.Logger.Error(errorInvalidToken)
+		return sendWatchError(stream, errorInvalidToken)
+	}
+
+	if !token.HasScope(domain.ScopeRead) {
+		return sendWatchError(stream, errorAccessDenied)
+	}
+
+	if s.Events == nil {
+		return sendWatchError(stream, "watch is not supported")
+	}
+
+	var vaultID int
+	if vault, ok := middleware.GetVaultFromContext(ctx); ok {
+		vaultID = vault.ID
+	}
+
+	id, events := s.Events.Subscribe()
+	defer s.Events.Unsubscribe(id)
+
+	err := stream.SendHeader(metadata.MD{})
+	if err != nil {
+		return fmt.Errorf(errorWatchStream, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return sendWatchError(stream, "too many events, sync and watch again")
+			}
+
+			if !canWatchEvent(event, token.ID, vaultID) || !token.CanAccessRecord(event.ID, event.Name) {
+				continue
+			}
+
+			err := stream.Send(&proto.WatchEvent{
+				Kind:     event.Kind,
+				Id:       int32(event.ID),
+				Name:     event.Name,
+				Type:     event.Type,
+				Owner:    int32(event.Owner),
+				Revision: event.Revision,
+			})
+			if err != nil {
+				return fmt.Errorf(errorWatchStream, err)
+			}
+		}
+	}
+}
+
+/* UTILS. */
+
+// canWatchEvent reports whether the event is about a record of the team vault
+// the watcher is targeted at, or about a personal record the user owns or
+// the record is shared with. Events about a share go to its grantee only.
+func canWatchEvent(event domain.RecordEvent, user int, vault int) bool {
+	if event.Grantee != 0 {
+		return vault == 0 && event.Grantee == user
+	}
+
+	if vault != 0 {
+		return event.Vault == vault
+	}
+
+	return event.Vault == 0 && (event.Owner == user || slices.Contains(event.Grantees, user))
+}
+
+// sendWatchError sends the error as the last event of the stream.
+func sendWatchError(stream proto.Storage_WatchServer, msg string) error {
+	err := stream.Send(&proto.WatchEvent{Error: msg})
+	if err != nil {
+		return fmt.Errorf(errorWatchStream, err)
+	}
+
+	return nil
+}
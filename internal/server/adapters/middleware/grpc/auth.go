@@ -5,9 +5,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/dedpnd/GophKeeper/internal/server/adapters/middleware"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
 	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+	"github.com/dedpnd/GophKeeper/internal/server/core/services"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
@@ -15,19 +19,25 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-// GetAuthenticator returns a function for authenticating gRPC requests using JWT tokens.
-// It uses the `AuthFromMD` function to extract the token from the metadata and verifies
-// the token using `verifyJWTandGetPayload`. If the token is valid, it sets the token's
-// claims in the context and returns the enhanced context. If an error occurs, it returns
-// an unauthenticated error.
-func GetAuthenticator(jwtKey string) func(ctx context.Context) (context.Context, error) {
+// GetAuthenticator returns a function for authenticating gRPC requests using JWT tokens
+// or personal access tokens. It uses the `AuthFromMD` function to extract the token from
+// the metadata. Personal access tokens are looked up by their hash using the `UserService`,
+// JWT tokens are verified using `verifyJWTandGetPayload`. If the token is valid, it sets
+// the token's claims in the context and returns the enhanced context. If an error occurs,
+// it returns an unauthenticated error.
+func GetAuthenticator(jwtKey string, svc *services.UserService) func(ctx context.Context) (context.Context, error) {
 	return func(ctx context.Context) (context.Context, error) {
 		token, err := auth.AuthFromMD(ctx, "bearer")
 		if err != nil {
 			return nil, fmt.Errorf("AuthFromMD has error: %w", err)
 		}
 
-		pl, err := verifyJWTandGetPayload(jwtKey, token)
+		var pl middleware.JWTclaims
+		if strings.HasPrefix(token, domain.AccessTokenPrefix) {
+			pl, err = verifyAccessTokenAndGetPayload(svc, token)
+		} else {
+			pl, err = verifyJWTandGetPayload(jwtKey, token)
+		}
 		if err != nil {
 			//nolint:wrapcheck // This legal return
 			return nil, status.Error(codes.Unauthenticated, err.Error())
@@ -39,11 +49,60 @@ func GetAuthenticator(jwtKey string) func(ctx context.Context) (context.Context,
 	}
 }
 
+// authUserMethods lists the methods of the `User` service that
+// require authentication, all other methods of it are public.
+var authUserMethods = map[string]bool{
+	"CreateAccessToken": true,
+	"ListAccessTokens":  true,
+	"RevokeAccessToken": true,
+	"SetVerifier":       true,
+
+	"AddEmergencyContact":    true,
+	"RemoveEmergencyContact": true,
+	"ListEmergencyContacts":  true,
+	"RequestEmergencyAccess": true,
+	"RejectEmergencyAccess":  true,
+}
+
 // AuthMatcher is a function that determines whether a given gRPC call should
 // require authentication. It returns `true` if the service name does not match
-// the `User_ServiceDesc.ServiceName`, indicating that authentication is required.
+// the `User_ServiceDesc.ServiceName` or the method is listed in `authUserMethods`,
+// indicating that authentication is required.
 func AuthMatcher(ctx context.Context, callMeta interceptors.CallMeta) bool {
-	return proto.User_ServiceDesc.ServiceName != callMeta.Service
+	return proto.User_ServiceDesc.ServiceName != callMeta.Service || authUserMethods[callMeta.Method]
+}
+
+// verifyAccessTokenAndGetPayload looks up a personal access token by its hash and
+// returns the claims built from it. Unknown, revoked and expired tokens are rejected.
+func verifyAccessTokenAndGetPayload(svc *services.UserService, token string) (middleware.JWTclaims, error) {
+	pat, err := svc.FindAccessTokenByHash(middleware.HashAccessToken(token))
+	if err != nil {
+		return middleware.JWTclaims{}, fmt.Errorf("failed get access token: %w", err)
+	}
+
+	if pat == nil || pat.Revoked {
+		return middleware.JWTclaims{}, errors.New("invalid access token")
+	}
+
+	if pat.ExpiresAt != nil && pat.ExpiresAt.Before(time.Now()) {
+		return middleware.JWTclaims{}, errors.New("access token expired")
+	}
+
+	user, err := svc.FindUserByID(pat.Owner)
+	if err != nil {
+		return middleware.JWTclaims{}, fmt.Errorf("failed get user: %w", err)
+	}
+
+	if user == nil {
+		return middleware.JWTclaims{}, errors.New("access token owner not found")
+	}
+
+	claims, err := middleware.AccessTokenClaims(pat, user.Login)
+	if err != nil {
+		return middleware.JWTclaims{}, fmt.Errorf("invalid access token: %w", err)
+	}
+
+	return claims, nil
 }
 
 // verifyJWTandGetPayload verifies a JWT token and returns its claims as `JWTclaims`.
@@ -0,0 +1,19 @@
+// Package middleware provides various middlewares for the server.
+package middleware
+
+import (
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/recovery"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RecoveryHandler returns a `recovery.RecoveryHandlerFunc` which logs the
+// panic of a handler and returns an internal error to the client instead of
+// crashing the server.
+func RecoveryHandler(l *zap.Logger) recovery.RecoveryHandlerFunc {
+	return func(p any) error {
+		l.With(zap.Any("panic", p), zap.Stack("stack")).Error("recovered from panic in grpc handler")
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
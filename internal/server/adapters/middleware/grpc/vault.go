@@ -0,0 +1,169 @@
+// Package middleware provides various middlewares for the server.
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dedpnd/GophKeeper/internal/server/adapters/middleware"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain/proto"
+	"github.com/dedpnd/GophKeeper/internal/server/core/services"
+	grpcmiddleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// methodActions maps the RPCs which work with organizations to the action
+// the role of the caller must allow.
+var methodActions = map[string]string{
+	proto.Storage_ReadRecord_FullMethodName:          domain.ActionRead,
+	proto.Storage_ReadAllRecord_FullMethodName:       domain.ActionRead,
+	proto.Storage_FindRecordByName_FullMethodName:    domain.ActionRead,
+	proto.Storage_SearchRecords_FullMethodName:       domain.ActionRead,
+	proto.Storage_WriteRecord_FullMethodName:         domain.ActionWrite,
+	proto.Storage_DeleteRecord_FullMethodName:        domain.ActionDelete,
+	proto.Storage_ListTrash_FullMethodName:           domain.ActionRead,
+	proto.Storage_RestoreRecord_FullMethodName:       domain.ActionWrite,
+	proto.Storage_SetRecordExpiry_FullMethodName:     domain.ActionWrite,
+	proto.Storage_ListExpiringRecords_FullMethodName: domain.ActionRead,
+	proto.Storage_NextOTPCounter_FullMethodName:      domain.ActionWrite,
+	proto.Storage_EmptyTrash_FullMethodName:          domain.ActionDelete,
+	proto.Storage_Sync_FullMethodName:                domain.ActionRead,
+	proto.Storage_ListConflicts_FullMethodName:       domain.ActionRead,
+	proto.Storage_ResolveConflict_FullMethodName:     domain.ActionWrite,
+	proto.Storage_Watch_FullMethodName:               domain.ActionRead,
+	proto.Vault_CreateVault_FullMethodName:           domain.ActionManage,
+	proto.Vault_AddMember_FullMethodName:             domain.ActionManage,
+	proto.Vault_RemoveMember_FullMethodName:          domain.ActionManage,
+}
+
+// orgRequest is implemented by requests which target an organization directly.
+type orgRequest interface {
+	GetOrgId() int32
+}
+
+// VaultUnaryInterceptor returns a unary interceptor which checks the role
+// of the caller for calls targeted at an organization or a team vault.
+func VaultUnaryInterceptor(svc *services.VaultService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		enCtx, err := authorizeVault(ctx, svc, info.FullMethod, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(enCtx, req)
+	}
+}
+
+// VaultStreamInterceptor returns a stream interceptor which checks the role
+// of the caller for streams targeted at a team vault.
+func VaultStreamInterceptor(svc *services.VaultService) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		enCtx, err := authorizeVault(ss.Context(), svc, info.FullMethod, nil)
+		if err != nil {
+			return err
+		}
+
+		wrapped := grpcmiddleware.WrapServerStream(ss)
+		wrapped.WrappedContext = enCtx
+
+		return handler(srv, wrapped)
+	}
+}
+
+// authorizeVault finds the organization the call is targeted at, either by the
+// request or by the vault header, and checks that the role of the caller allows
+// the action of the method, access tokens are not allowed to manage it. The
+// vault and the role are saved in the context. Calls without an organization
+// are passed through unchanged.
+func authorizeVault(ctx context.Context, svc *services.VaultService, method string, req any) (context.Context, error) {
+	claims, ok := middleware.GetTokenFromContext(ctx)
+	if !ok {
+		return ctx, nil
+	}
+
+	vaultID, err := vaultFromMD(ctx)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	action, supported := methodActions[method]
+	if !supported {
+		// Other storage methods work only with personal records
+		if vaultID != 0 && strings.HasPrefix(method, "/"+proto.Storage_ServiceDesc.ServiceName+"/") {
+			return nil, status.Error(codes.InvalidArgument, "method is not available for vaults")
+		}
+
+		return ctx, nil
+	}
+
+	access := middleware.VaultAccess{ID: vaultID}
+	if r, ok := req.(orgRequest); ok && r.GetOrgId() != 0 {
+		access.OrgID = int(r.GetOrgId())
+	} else if vaultID != 0 {
+		vault, err := svc.FindVault(vaultID)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "failed get vault")
+		}
+
+		if vault == nil {
+			return nil, status.Error(codes.NotFound, "vault not found")
+		}
+
+		access.OrgID = vault.OrgID
+	}
+
+	if access.OrgID == 0 {
+		return ctx, nil
+	}
+
+	// Scopes of access tokens cover records only, organizations are managed
+	// in interactive sessions
+	if action == domain.ActionManage && claims.IsAccessToken() {
+		return nil, status.Error(codes.PermissionDenied, "access tokens cannot manage organizations")
+	}
+
+	member, err := svc.FindMember(access.OrgID, claims.ID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed get member")
+	}
+
+	if member == nil {
+		return nil, status.Error(codes.PermissionDenied, "not a member of the organization")
+	}
+
+	if !services.RoleAllows(member.Role, action) {
+		return nil, status.Error(codes.PermissionDenied,
+			fmt.Sprintf("role %s is not allowed to %s", member.Role, action))
+	}
+
+	access.Role = member.Role
+
+	return middleware.SetVaultToContext(ctx, access), nil
+}
+
+// vaultFromMD returns the vault ID from the metadata of the call, zero if
+// the call is not targeted at a vault.
+func vaultFromMD(ctx context.Context) (int, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return 0, nil
+	}
+
+	values := md.Get(middleware.VaultHeader)
+	if len(values) == 0 || strings.TrimSpace(values[0]) == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.Atoi(values[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid vault id: %w", err)
+	}
+
+	return id, nil
+}
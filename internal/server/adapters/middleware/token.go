@@ -0,0 +1,88 @@
+// Package middleware provides various middlewares for the server.
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+)
+
+var sizeAccessToken = 32
+
+// GenerateAccessToken returns a new random personal access token value.
+func GenerateAccessToken() (string, error) {
+	b := make([]byte, sizeAccessToken)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("failed generate byte: %w", err)
+	}
+
+	return domain.AccessTokenPrefix + hex.EncodeToString(b), nil
+}
+
+// HashAccessToken returns the hex encoded SHA-256 hash of a personal access
+// token. Only this hash is stored in the database.
+func HashAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// AccessTokenClaims converts a stored personal access token into claims,
+// so that handlers can treat tokens and JWT sessions the same way.
+func AccessTokenClaims(token *domain.AccessToken, login string) (JWTclaims, error) {
+	ids, err := SplitIDs(token.RecordIDs)
+	if err != nil {
+		return JWTclaims{}, fmt.Errorf("failed parse record ids: %w", err)
+	}
+
+	return JWTclaims{
+		ID:         token.Owner,
+		Login:      login,
+		TokenID:    token.ID,
+		Scopes:     SplitList(token.Scopes),
+		RecordIDs:  ids,
+		NamePrefix: token.NamePrefix,
+	}, nil
+}
+
+// SplitList splits a comma separated list, empty elements are skipped.
+func SplitList(s string) []string {
+	res := []string{}
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+
+	return res
+}
+
+// SplitIDs splits a comma separated list of IDs.
+func SplitIDs(s string) ([]int, error) {
+	list := SplitList(s)
+	res := make([]int, 0, len(list))
+	for _, v := range list {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed parse int: %w", err)
+		}
+		res = append(res, id)
+	}
+
+	return res, nil
+}
+
+// JoinIDs joins IDs into a comma separated list.
+func JoinIDs(ids []int) string {
+	list := make([]string, 0, len(ids))
+	for _, v := range ids {
+		list = append(list, strconv.Itoa(v))
+	}
+
+	return strings.Join(list, ",")
+}
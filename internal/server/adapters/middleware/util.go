@@ -3,6 +3,8 @@ package middleware
 
 import (
 	"context"
+	"slices"
+	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -12,18 +14,86 @@ import (
 type contextKey int
 
 // JWTclaims represents the claims from a JWT token, including the user ID,
-// login, and standard JWT registered claims.
+// login, and standard JWT registered claims. When the caller is authenticated
+// with a personal access token, the claims also carry the token restrictions,
+// these fields are never encoded into a JWT.
 type JWTclaims struct {
-	ID    int    `json:"id"`
-	Login string `json:"login"`
+	ID         int      `json:"id"`
+	Login      string   `json:"login"`
+	TokenID    int      `json:"-"`
+	Scopes     []string `json:"-"`
+	RecordIDs  []int    `json:"-"`
+	NamePrefix string   `json:"-"`
 	jwt.RegisteredClaims
 }
 
+// IsAccessToken reports whether the claims belong to a personal access token.
+func (c JWTclaims) IsAccessToken() bool {
+	return c.TokenID != 0
+}
+
+// HasScope reports whether the caller is allowed to perform operations of
+// the given scope. Interactive sessions have all scopes.
+func (c JWTclaims) HasScope(scope string) bool {
+	if !c.IsAccessToken() {
+		return true
+	}
+
+	return slices.Contains(c.Scopes, scope)
+}
+
+// CanAccessRecord reports whether the caller is allowed to access the record
+// with the given ID and name. A zero ID means a record that does not exist yet,
+// tokens restricted to record IDs never create records, see `CanCreateRecord`.
+func (c JWTclaims) CanAccessRecord(id int, name string) bool {
+	if !c.IsAccessToken() {
+		return true
+	}
+
+	if len(c.RecordIDs) > 0 && !slices.Contains(c.RecordIDs, id) {
+		return false
+	}
+
+	if c.NamePrefix != "" && !strings.HasPrefix(name, c.NamePrefix) {
+		return false
+	}
+
+	return true
+}
+
+// CanCreateRecord reports whether the caller is allowed to create a record
+// with the given name. The ID of a new record is not known when the token is
+// created, so tokens restricted to record IDs cannot create records, tokens
+// restricted to a name prefix create records with the prefix.
+func (c JWTclaims) CanCreateRecord(name string) bool {
+	if !c.IsAccessToken() {
+		return true
+	}
+
+	if len(c.RecordIDs) > 0 {
+		return false
+	}
+
+	return c.NamePrefix == "" || strings.HasPrefix(name, c.NamePrefix)
+}
+
 // Enumeration of context keys used for storing values in context.
 const (
 	ContextKeyToken contextKey = iota
+	ContextKeyVault
 )
 
+// VaultHeader is the metadata key the agent uses to target a team vault.
+const VaultHeader = "x-vault-id"
+
+// VaultAccess describes the team vault a call is targeted at and the role
+// of the caller in the organization which owns the vault.
+type VaultAccess struct {
+	Role  string
+	ID    int
+	OrgID int
+}
+
 // GetTokenFromContext retrieves JWT claims from the given context.
 // It returns the JWT claims and a boolean indicating whether the claims
 // were successfully retrieved. If the claims are not found in the context,
@@ -38,3 +108,15 @@ func GetTokenFromContext(ctx context.Context) (JWTclaims, bool) {
 func SetTokenToContext(ctx context.Context, pl JWTclaims) context.Context {
 	return context.WithValue(ctx, ContextKeyToken, pl)
 }
+
+// GetVaultFromContext retrieves the team vault of the call from the given
+// context. It returns false if the call is not targeted at a vault.
+func GetVaultFromContext(ctx context.Context) (VaultAccess, bool) {
+	vault, ok := ctx.Value(ContextKeyVault).(VaultAccess)
+	return vault, ok && vault.ID != 0
+}
+
+// SetVaultToContext adds the team vault of the call to the given context.
+func SetVaultToContext(ctx context.Context, vault VaultAccess) context.Context {
+	return context.WithValue(ctx, ContextKeyVault, vault)
+}
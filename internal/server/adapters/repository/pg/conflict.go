@@ -0,0 +1,68 @@
+// Package repository contains the data access layer for the application,
+// providing functions to interact with the database and perform operations
+// related to the domain entities such as `User` and `Storage`. This package
+// serves as an interface between the application services and the database,
+// utilizing an ORM (such as GORM) to execute queries and manage transactions.
+package repository
+
+import (
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"gorm.io/gorm"
+)
+
+// CreateConflict stores a version of the record written concurrently with
+// the current one.
+func (s *DB) CreateConflict(conflict domain.Conflict) error {
+	req := s.db.Create(&conflict)
+	if req.Error != nil {
+		return req.Error
+	}
+
+	return nil
+}
+
+// ListConflicts retrieves the conflicting versions of the record ordered
+// by the time they were written.
+func (s *DB) ListConflicts(recordID int) ([]*domain.Conflict, error) {
+	conflicts := []*domain.Conflict{}
+
+	req := s.db.Order("id").Find(&conflicts, "record_id = ?", recordID)
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return conflicts, nil
+}
+
+// ResolveConflict replaces the record with the version picked by the user
+// and removes the resolved conflicting versions in one transaction. Versions
+// written after the user listed them are kept. The record is changed only if
+// it still has the `revision` the user has seen, false means it was changed
+// by another write and nothing is resolved.
+func (s *DB) ResolveConflict(doc domain.Storage, revision int64, conflicts []int) (bool, error) {
+	resolved := false
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		req := tx.Model(&domain.Storage{}).
+			Where("id = ? AND revision = ? AND NOT deleted", doc.ID, revision).
+			Updates(recordChanges(doc))
+		if req.Error != nil {
+			return req.Error
+		}
+
+		if req.RowsAffected == 0 {
+			return nil
+		}
+
+		req = tx.Delete(&domain.Conflict{}, "record_id = ? AND id IN ?", doc.ID, conflicts)
+		if req.Error != nil {
+			return req.Error
+		}
+
+		resolved = true
+		return nil
+	})
+
+	//nolint:wrapcheck // This legal return
+	return resolved, err
+}
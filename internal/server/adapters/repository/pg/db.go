@@ -17,14 +17,18 @@ import (
 )
 
 type DB struct {
-	db *gorm.DB
+	db  *gorm.DB
+	dsn string
 }
 
 // NewDB initializes a new database session using the given DSN (Data Source Name).
 // It connects to the PostgreSQL database using GORM and configures the logger to operate in silent mode.
-// If the connection is successful, it proceeds to migrate the schema using
-// AutoMigrate for the `User` and `Storage` domain models. If an error occurs during
-// initialization or migration, an error is returned along with a partially initialized `DB` instance.
+// If the connection is successful, it creates the sequence of storage revisions and
+// proceeds to migrate the schema using AutoMigrate for the `User`, `Storage`, `AccessToken`,
+// `Share`, `Conflict`, organization, emergency contact, folder and tag domain models. The unique indexes of record
+// names, the indexes of the search, the trigger which saves the transaction of changes for the sync and
+// the trigger which notifies about changes of storage records are created after the migration. If an error occurs during initialization or migration, an error
+// is returned along with a partially initialized `DB` instance.
 func NewDB(ctx context.Context, lg *zap.Logger, dsn string) (*DB, error) {
 	db, err := gorm.Open(postgres.New(postgres.Config{
 		DSN: dsn,
@@ -35,16 +39,45 @@ func NewDB(ctx context.Context, lg *zap.Logger, dsn string) (*DB, error) {
 		return &DB{}, fmt.Errorf("failed init db session: %w", err)
 	}
 
+	// Storage revisions are taken from the sequence by default
+	err = db.Exec("CREATE SEQUENCE IF NOT EXISTS " + domain.StorageRevisionSeq).Error
+	if err != nil {
+		return &DB{}, fmt.Errorf("failed create revision sequence: %w", err)
+	}
+
 	// Migrate the schema
-	err = db.AutoMigrate(&domain.User{}, &domain.Storage{})
+	err = db.AutoMigrate(&domain.User{}, &domain.Storage{}, &domain.AccessToken{}, &domain.Share{},
+		&domain.Organization{}, &domain.Vault{}, &domain.Member{}, &domain.EmergencyContact{}, &domain.Conflict{},
+		&domain.Folder{}, &domain.Tag{}, &domain.RecordTag{})
 	if err != nil {
 		return &DB{}, fmt.Errorf("failed migrate models: %w", err)
 	}
 
+	err = createNameIndexes(lg, db)
+	if err != nil {
+		return &DB{}, fmt.Errorf("failed create name indexes: %w", err)
+	}
+
+	err = createSearchIndexes(lg, db)
+	if err != nil {
+		return &DB{}, fmt.Errorf("failed create search indexes: %w", err)
+	}
+
+	err = createTxTrigger(db)
+	if err != nil {
+		return &DB{}, fmt.Errorf("failed create sync trigger: %w", err)
+	}
+
+	err = createEventTrigger(db)
+	if err != nil {
+		return &DB{}, fmt.Errorf("failed create event trigger: %w", err)
+	}
+
 	lg.Info(("Connection to postgre: success"))
 
 	return &DB{
-		db: db,
+		db:  db,
+		dsn: dsn,
 	}, nil
 }
 
@@ -0,0 +1,99 @@
+// Package repository contains the data access layer for the application,
+// providing functions to interact with the database and perform operations
+// related to the domain entities such as `User` and `Storage`. This package
+// serves as an interface between the application services and the database,
+// utilizing an ORM (such as GORM) to execute queries and manage transactions.
+package repository
+
+import (
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"gorm.io/gorm/clause"
+)
+
+// emergencyColumns are the columns selected for emergency contacts,
+// including the logins of the grantor and the grantee.
+var emergencyColumns = "emergency_contacts.*, grantors.login AS grantor_login, grantees.login AS grantee_login"
+
+// SaveEmergencyContact nominates the grantee as an emergency contact of the
+// grantor. If the grantee is already nominated, the waiting period and the
+// status are replaced, so a pending request is cancelled.
+func (s *DB) SaveEmergencyContact(contact domain.EmergencyContact) (*domain.EmergencyContact, error) {
+	req := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "grantor"}, {Name: "grantee"}},
+		DoUpdates: clause.AssignmentColumns([]string{"wait_hours", "status", "requested_at"}),
+	}).Create(&contact)
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return &contact, nil
+}
+
+// FindEmergencyContact retrieves the nomination of the grantee by the grantor.
+// If the grantee is not nominated, it returns nil for both the contact and the error.
+func (s *DB) FindEmergencyContact(grantor int, grantee int) (*domain.EmergencyContact, error) {
+	contact := domain.EmergencyContact{}
+
+	req := s.db.Model(&domain.EmergencyContact{}).
+		Select(emergencyColumns).
+		Joins("JOIN users AS grantors ON grantors.id = emergency_contacts.grantor").
+		Joins("JOIN users AS grantees ON grantees.id = emergency_contacts.grantee").
+		Where("emergency_contacts.grantor = ? AND emergency_contacts.grantee = ?", grantor, grantee).
+		Take(&contact)
+	if req.RowsAffected == 0 {
+		//nolint:nilnil // This legal return
+		return nil, nil
+	}
+
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return &contact, nil
+}
+
+// ListEmergencyContacts retrieves the emergency contacts where the user is
+// either the grantor or the grantee, with the logins of both users.
+func (s *DB) ListEmergencyContacts(user int) ([]*domain.EmergencyContact, error) {
+	contacts := []*domain.EmergencyContact{}
+
+	req := s.db.Model(&domain.EmergencyContact{}).
+		Select(emergencyColumns).
+		Joins("JOIN users AS grantors ON grantors.id = emergency_contacts.grantor").
+		Joins("JOIN users AS grantees ON grantees.id = emergency_contacts.grantee").
+		Where("emergency_contacts.grantor = ? OR emergency_contacts.grantee = ?", user, user).
+		Order("emergency_contacts.id").
+		Find(&contacts)
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return contacts, nil
+}
+
+// UpdateEmergencyStatus moves the emergency contact to a new status. Only
+// a contact in the expected status is changed, so concurrent transitions
+// do not overwrite each other. It returns whether the contact was changed.
+func (s *DB) UpdateEmergencyStatus(contact domain.EmergencyContact, from string) (bool, error) {
+	req := s.db.Model(&domain.EmergencyContact{}).
+		Where("id = ? AND status = ?", contact.ID, from).
+		Updates(map[string]interface{}{
+			"status":       contact.Status,
+			"requested_at": contact.RequestedAt,
+		})
+	if req.Error != nil {
+		return false, req.Error
+	}
+
+	return req.RowsAffected != 0, nil
+}
+
+// DeleteEmergencyContact removes the nomination of the grantee by the grantor.
+func (s *DB) DeleteEmergencyContact(grantor int, grantee int) error {
+	req := s.db.Delete(&domain.EmergencyContact{}, "grantor = ? AND grantee = ?", grantor, grantee)
+	if req.Error != nil {
+		return req.Error
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+// Package repository contains the data access layer for the application,
+// providing functions to interact with the database and perform operations
+// related to the domain entities such as `User` and `Storage`. This package
+// serves as an interface between the application services and the database,
+// utilizing an ORM (such as GORM) to execute queries and manage transactions.
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"github.com/dedpnd/GophKeeper/internal/server/core/ports"
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+// eventFunction notifies about every change of a storage record. A record moved
+// to the trash keeps its shares, so its users are notified about the deletion,
+// and a record restored from the trash is created again for them. Notifications
+// are limited in size, so only the IDs are sent and the name, type and shares
+// are read by the receiver. Record values are never part of the notification.
+var eventFunction = `CREATE OR REPLACE FUNCTION notify_storage_event() RETURNS trigger AS $$
+DECLARE
+	kind text := 'update';
+BEGIN
+	IF TG_OP = 'INSERT' THEN
+		kind := 'create';
+	ELSIF NEW.deleted AND NOT OLD.deleted THEN
+		kind := 'delete';
+	ELSIF OLD.deleted AND NOT NEW.deleted THEN
+		kind := 'create';
+	END IF;
+
+	PERFORM pg_notify('` + domain.RecordEventsChannel + `', json_build_object(
+		'kind', kind,
+		'id', NEW.id,
+		'owner', NEW.owner,
+		'vault', NEW.vault,
+		'revision', NEW.revision
+	)::text);
+
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql`
+
+// eventTrigger runs `eventFunction` after records are created and changed.
+var eventTrigger = "CREATE OR REPLACE TRIGGER storage_events AFTER INSERT OR UPDATE ON storages " +
+	"FOR EACH ROW EXECUTE FUNCTION notify_storage_event()"
+
+// shareEventFunction notifies the grantee when a record is shared with them
+// or the share is revoked: the record is created or deleted for the grantee.
+// Shares of records in the trash are removed with the records silently, their
+// grantees were notified about the deletion already.
+var shareEventFunction = `CREATE OR REPLACE FUNCTION notify_share_event() RETURNS trigger AS $$
+DECLARE
+	changed shares;
+	kind text := 'update';
+	rec storages;
+BEGIN
+	IF TG_OP = 'DELETE' THEN
+		changed := OLD;
+		kind := 'delete';
+	ELSE
+		changed := NEW;
+		IF TG_OP = 'INSERT' THEN
+			kind := 'create';
+		END IF;
+	END IF;
+
+	SELECT * INTO rec FROM storages WHERE id = changed.record_id AND NOT deleted;
+	IF NOT FOUND THEN
+		RETURN NULL;
+	END IF;
+
+	PERFORM pg_notify('` + domain.RecordEventsChannel + `', json_build_object(
+		'kind', kind,
+		'id', rec.id,
+		'owner', rec.owner,
+		'vault', rec.vault,
+		'revision', rec.revision,
+		'grantee', changed.grantee
+	)::text);
+
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql`
+
+// shareEventTrigger runs `shareEventFunction` after records are shared and
+// shares are changed or revoked.
+var shareEventTrigger = "CREATE OR REPLACE TRIGGER share_events AFTER INSERT OR UPDATE OR DELETE ON shares " +
+	"FOR EACH ROW EXECUTE FUNCTION notify_share_event()"
+
+// createEventTrigger creates the triggers which notify about changes
+// of storage records and their shares.
+func createEventTrigger(db *gorm.DB) error {
+	for _, v := range []string{eventFunction, eventTrigger, shareEventFunction, shareEventTrigger} {
+		err := db.Exec(v).Error
+		if err != nil {
+			return fmt.Errorf("failed create event trigger: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ResolveRecordEvent fills the name and type of the record and the users it
+// is shared with into the event. If the record no longer exists, the event is
+// returned as it is.
+func (s *DB) ResolveRecordEvent(event domain.RecordEvent) (domain.RecordEvent, error) {
+	doc := domain.Storage{}
+
+	req := s.db.Select("name, type").Take(&doc, "id = ?", event.ID)
+	if req.RowsAffected == 0 {
+		return event, nil
+	}
+
+	if req.Error != nil {
+		return event, req.Error
+	}
+
+	event.Name = doc.Name
+	event.Type = doc.Type
+
+	req = s.db.Model(&domain.Share{}).Where("record_id = ?", event.ID).Pluck("grantee", &event.Grantees)
+	if req.Error != nil {
+		return event, req.Error
+	}
+
+	return event, nil
+}
+
+// eventListener receives the notifications about changes of storage records
+// on a dedicated connection, the connections of the pool are not blocked.
+type eventListener struct {
+	conn *pgx.Conn
+}
+
+// ListenRecordEvents opens a dedicated connection and starts listening to the
+// record events. The notifications are delivered by the database, so changes
+// made through every server instance are received.
+func (s *DB) ListenRecordEvents(ctx context.Context) (ports.RecordEventListener, error) {
+	conn, err := pgx.Connect(ctx, s.dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed connect listener: %w", err)
+	}
+
+	_, err = conn.Exec(ctx, "LISTEN "+domain.RecordEventsChannel)
+	if err != nil {
+		_ = conn.Close(ctx)
+		return nil, fmt.Errorf("failed listen events: %w", err)
+	}
+
+	return &eventListener{conn: conn}, nil
+}
+
+// WaitRecordEvent blocks until the next record event is received.
+func (l *eventListener) WaitRecordEvent(ctx context.Context) (*domain.RecordEvent, error) {
+	n, err := l.conn.WaitForNotification(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed wait notification: %w", err)
+	}
+
+	var event domain.RecordEvent
+	err = json.Unmarshal([]byte(n.Payload), &event)
+	if err != nil {
+		return nil, fmt.Errorf("failed decode event: %w", err)
+	}
+
+	return &event, nil
+}
+
+// Close closes the connection of the listener.
+func (l *eventListener) Close(ctx context.Context) error {
+	err := l.conn.Close(ctx)
+	if err != nil {
+		return fmt.Errorf("failed close listener: %w", err)
+	}
+
+	return nil
+}
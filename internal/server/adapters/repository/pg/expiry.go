@@ -0,0 +1,82 @@
+// Package repository contains the data access layer for the application,
+// providing functions to interact with the database and perform operations
+// related to the domain entities such as `User` and `Storage`. This package
+// serves as an interface between the application services and the database,
+// utilizing an ORM (such as GORM) to execute queries and manage transactions.
+package repository
+
+import (
+	"time"
+
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"gorm.io/gorm"
+)
+
+// dueColumn is the date a record is due, the earlier of its expiry date and
+// the date of its next rotation. It is NULL for records without both.
+// Rotation is counted from the last write of the value, records written
+// before the time was saved count it from their last change.
+var dueColumn = "LEAST(storages.expires_at, CASE WHEN storages.rotate_days > 0 " +
+	"THEN COALESCE(storages.value_updated_at, storages.updated_at) + make_interval(days => storages.rotate_days) END)"
+
+// ListDueRecords retrieves the personal records of the owner and the records
+// shared with the owner which are due before the time, the earliest first.
+func (s *DB) ListDueRecords(owner int, before time.Time) ([]*domain.Storage, error) {
+	docs := []*domain.Storage{}
+
+	req := s.db.Model(&domain.Storage{}).
+		Select(sharedColumns).
+		Joins("JOIN users ON users.id = storages.owner").
+		Joins("LEFT JOIN shares ON shares.record_id = storages.id AND shares.grantee = ?", owner).
+		Where("storages.vault = 0 AND NOT storages.deleted AND (storages.owner = ? OR shares.grantee = ?)",
+			owner, owner)
+
+	req = whereDue(req, before).Find(&docs)
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return docs, nil
+}
+
+// ListVaultDueRecords retrieves the records of the team vault which are due
+// before the time, the earliest first.
+func (s *DB) ListVaultDueRecords(vault int, before time.Time) ([]*domain.Storage, error) {
+	docs := []*domain.Storage{}
+
+	req := s.db.Model(&domain.Storage{}).
+		Select(vaultColumns).
+		Joins("JOIN users ON users.id = storages.owner").
+		Where("storages.vault = ? AND NOT storages.deleted", vault)
+
+	req = whereDue(req, before).Find(&docs)
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return docs, nil
+}
+
+// SetRecordExpiry replaces the expiry date and the rotation interval of the
+// record, a nil date and a zero interval remove them. The record takes the
+// next revision, the time of the last write of its value is kept.
+func (s *DB) SetRecordExpiry(id int, expiresAt *time.Time, rotateDays int) error {
+	req := s.db.Model(&domain.Storage{}).
+		Where("id = ? AND NOT deleted", id).
+		Updates(map[string]interface{}{
+			"expires_at":  expiresAt,
+			"rotate_days": rotateDays,
+			"revision":    nextRevision,
+		})
+	if req.Error != nil {
+		return req.Error
+	}
+
+	return nil
+}
+
+// whereDue filters the records due before the time and orders them by the
+// date they are due.
+func whereDue(req *gorm.DB, before time.Time) *gorm.DB {
+	return req.Where(dueColumn+" <= ?", before).Order(dueColumn + ", storages.id")
+}
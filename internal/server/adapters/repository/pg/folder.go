@@ -0,0 +1,209 @@
+// Package repository contains the data access layer for the application,
+// providing functions to interact with the database and perform operations
+// related to the domain entities such as `User` and `Storage`. This package
+// serves as an interface between the application services and the database,
+// utilizing an ORM (such as GORM) to execute queries and manage transactions.
+package repository
+
+import (
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// folderTree selects the IDs of the folder and of all its subfolders.
+var folderTree = `WITH RECURSIVE tree AS (
+  SELECT id FROM folders WHERE id = ? AND owner = ?
+  UNION
+  SELECT folders.id FROM folders JOIN tree ON folders.parent = tree.id
+)
+SELECT id FROM tree`
+
+// CreateFolder creates the folder and returns it with its ID. The parent is
+// locked, so it is not deleted at the same time. If the parent folder of the
+// owner does not exist, it returns nil for both the folder and the error.
+// Names are unique in the parent by the index of folders.
+func (s *DB) CreateFolder(folder domain.Folder) (*domain.Folder, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if folder.Parent != 0 {
+			ok, err := lockFolders(tx, "id = ? AND owner = ?", clause.LockingStrengthShare, folder.Parent, folder.Owner)
+			if err != nil || !ok {
+				return err
+			}
+		}
+
+		return tx.Create(&folder).Error
+	})
+	if err != nil {
+		//nolint:wrapcheck // This legal return
+		return nil, err
+	}
+
+	if folder.ID == 0 {
+		return nil, nil
+	}
+
+	return &folder, nil
+}
+
+// ListFolders retrieves all folders of the owner ordered by name.
+func (s *DB) ListFolders(owner int) ([]*domain.Folder, error) {
+	folders := []*domain.Folder{}
+
+	req := s.db.Where("owner = ?", owner).Order("name, id").Find(&folders)
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return folders, nil
+}
+
+// RenameFolder renames the folder of the owner. It returns false if there is
+// no such folder, names are unique in the parent by the index of folders.
+func (s *DB) RenameFolder(id int, owner int, name string) (bool, error) {
+	req := s.db.Model(&domain.Folder{}).Where("id = ? AND owner = ?", id, owner).Update("name", name)
+	if req.Error != nil {
+		return false, req.Error
+	}
+
+	return req.RowsAffected != 0, nil
+}
+
+// DeleteFolder deletes the folder of the owner with all its subfolders. The
+// records of the deleted folders are moved to the parent of the folder. The
+// folders are locked first, so records are not moved and folders are not
+// created in them at the same time. It returns false if there is no such folder.
+func (s *DB) DeleteFolder(id int, owner int) (bool, error) {
+	deleted := false
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		ok, err := lockFolders(tx, "id IN ("+folderTree+")", clause.LockingStrengthUpdate, id, owner)
+		if err != nil || !ok {
+			return err
+		}
+
+		folder := domain.Folder{}
+		err = tx.Where("id = ? AND owner = ?", id, owner).Take(&folder).Error
+		if err != nil {
+			return err
+		}
+
+		req := tx.Model(&domain.Storage{}).
+			Where("owner = ? AND vault = 0 AND folder IN ("+folderTree+")", owner, id, owner).
+			Updates(map[string]interface{}{"folder": folder.Parent, "revision": nextRevision})
+		if req.Error != nil {
+			return req.Error
+		}
+
+		deleted = true
+		return tx.Where("id IN ("+folderTree+")", id, owner).Delete(&domain.Folder{}).Error
+	})
+	if err != nil {
+		//nolint:wrapcheck // This legal return
+		return false, err
+	}
+
+	return deleted, nil
+}
+
+// MoveRecord moves the personal record of the owner to the folder, zero is
+// the root. The folder is locked, so it is not deleted at the same time. The
+// record takes the next revision. It returns false if there is no such
+// folder or record.
+func (s *DB) MoveRecord(id int, owner int, folder int) (bool, error) {
+	moved := false
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if folder != 0 {
+			ok, err := lockFolders(tx, "id = ? AND owner = ?", clause.LockingStrengthShare, folder, owner)
+			if err != nil || !ok {
+				return err
+			}
+		}
+
+		req := tx.Model(&domain.Storage{}).
+			Where("id = ? AND owner = ? AND vault = 0 AND NOT deleted", id, owner).
+			Updates(map[string]interface{}{"folder": folder, "revision": nextRevision})
+		moved = req.RowsAffected != 0
+
+		return req.Error
+	})
+	if err != nil {
+		//nolint:wrapcheck // This legal return
+		return false, err
+	}
+
+	return moved, nil
+}
+
+// lockFolders locks the folders matching the condition with the strength and
+// tells whether there are such folders.
+func lockFolders(tx *gorm.DB, where string, strength string, args ...interface{}) (bool, error) {
+	var ids []int
+
+	err := tx.Model(&domain.Folder{}).Clauses(clause.Locking{Strength: strength}).
+		Where(where, args...).Pluck("id", &ids).Error
+	if err != nil {
+		return false, err
+	}
+
+	return len(ids) != 0, nil
+}
+
+// TagRecord labels the personal record of the owner with the tags, missing
+// tags of the owner are created. The record takes the next revision.
+func (s *DB) TagRecord(id int, owner int, tags []string) error {
+	//nolint:wrapcheck // This legal return
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, v := range tags {
+			req := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&domain.Tag{Owner: owner, Name: v})
+			if req.Error != nil {
+				return req.Error
+			}
+		}
+
+		req := tx.Exec(`INSERT INTO record_tags (record_id, tag_id)
+SELECT ?, id FROM tags WHERE owner = ? AND name IN ?
+ON CONFLICT DO NOTHING`, id, owner, tags)
+		if req.Error != nil {
+			return req.Error
+		}
+
+		return touchRecord(tx, id, owner)
+	})
+}
+
+// UntagRecord removes the tags from the personal record of the owner. The
+// record takes the next revision.
+func (s *DB) UntagRecord(id int, owner int, tags []string) error {
+	//nolint:wrapcheck // This legal return
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		owned := tx.Model(&domain.Tag{}).Select("id").Where("owner = ? AND name IN ?", owner, tags)
+
+		req := tx.Where("record_id = ? AND tag_id IN (?)", id, owned).Delete(&domain.RecordTag{})
+		if req.Error != nil {
+			return req.Error
+		}
+
+		return touchRecord(tx, id, owner)
+	})
+}
+
+// touchRecord gives the personal record of the owner the next revision, so
+// devices synchronize the changes of its folder or tags.
+func touchRecord(tx *gorm.DB, id int, owner int) error {
+	return tx.Model(&domain.Storage{}).
+		Where("id = ? AND owner = ? AND vault = 0 AND NOT deleted", id, owner).
+		Update("revision", nextRevision).Error
+}
+
+// whereTags filters the records by the tags, a record must have all of them.
+func whereTags(req *gorm.DB, tags []string) *gorm.DB {
+	if len(tags) == 0 {
+		return req
+	}
+
+	return req.Where(`(SELECT COUNT(DISTINCT tags.name) FROM record_tags
+JOIN tags ON tags.id = record_tags.tag_id AND tags.owner = storages.owner
+WHERE record_tags.record_id = storages.id AND tags.name IN ?) = ?`, tags, len(tags))
+}
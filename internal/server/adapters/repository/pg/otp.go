@@ -0,0 +1,25 @@
+// Package repository contains the data access layer for the application,
+// providing functions to interact with the database and perform operations
+// related to the domain entities such as `User` and `Storage`. This package
+// serves as an interface between the application services and the database,
+// utilizing an ORM (such as GORM) to execute queries and manage transactions.
+package repository
+
+// NextOTPCounter takes the next HOTP counter of the record and returns the
+// number of codes issued before, the increment is atomic, so concurrent
+// devices get different counters. It reports false if there is no record.
+func (s *DB) NextOTPCounter(id int) (int64, bool, error) {
+	var counters []int64
+
+	req := s.db.Raw("UPDATE storages SET otp_counter = otp_counter + 1 WHERE id = ? AND NOT deleted "+
+		"RETURNING otp_counter - 1", id).Scan(&counters)
+	if req.Error != nil {
+		return 0, false, req.Error
+	}
+
+	if len(counters) == 0 {
+		return 0, false, nil
+	}
+
+	return counters[0], true, nil
+}
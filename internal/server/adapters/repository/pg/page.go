@@ -0,0 +1,56 @@
+// Package repository contains the data access layer for the application,
+// providing functions to interact with the database and perform operations
+// related to the domain entities such as `User` and `Storage`. This package
+// serves as an interface between the application services and the database,
+// utilizing an ORM (such as GORM) to execute queries and manage transactions.
+package repository
+
+import (
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"gorm.io/gorm"
+)
+
+// sortColumns are the columns of the sort orders.
+var sortColumns = map[string]string{
+	domain.SortID:      "storages.id",
+	domain.SortName:    "storages.name",
+	domain.SortCreated: "storages.created_at",
+	domain.SortUpdated: "storages.updated_at",
+}
+
+// wherePage applies the sort order and the page to the query. Pages are
+// taken by the keyset of the sort key and the ID, so a page does not skip
+// or repeat records when the records before it are changed.
+func wherePage(req *gorm.DB, page domain.RecordPage) *gorm.DB {
+	column, ok := sortColumns[page.Sort]
+	if !ok {
+		column = sortColumns[domain.SortID]
+	}
+
+	dir, op := " ASC", ">"
+	if page.Desc {
+		dir, op = " DESC", "<"
+	}
+
+	if page.AfterID != 0 {
+		switch page.Sort {
+		case domain.SortName:
+			req = req.Where("("+column+", storages.id) "+op+" (?, ?)", page.AfterName, page.AfterID)
+		case domain.SortCreated, domain.SortUpdated:
+			req = req.Where("("+column+", storages.id) "+op+" (?, ?)", page.AfterTime, page.AfterID)
+		default:
+			req = req.Where("storages.id "+op+" ?", page.AfterID)
+		}
+	}
+
+	req = req.Order(column + dir)
+	if column != sortColumns[domain.SortID] {
+		req = req.Order("storages.id" + dir)
+	}
+
+	if page.Limit > 0 {
+		req = req.Limit(page.Limit)
+	}
+
+	return req
+}
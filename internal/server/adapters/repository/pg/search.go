@@ -0,0 +1,174 @@
+// Package repository contains the data access layer for the application,
+// providing functions to interact with the database and perform operations
+// related to the domain entities such as `User` and `Storage`. This package
+// serves as an interface between the application services and the database,
+// utilizing an ORM (such as GORM) to execute queries and manage transactions.
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// searchIndexes are the indexes of the filters of the search and of the
+// sort orders of lists. Names are matched by substrings with the trigram
+// index created separately.
+var searchIndexes = []string{
+	"CREATE INDEX IF NOT EXISTS idx_storage_owner_type ON storages (owner, type) WHERE NOT deleted",
+	"CREATE INDEX IF NOT EXISTS idx_storage_owner_created ON storages (owner, created_at) WHERE NOT deleted",
+	"CREATE INDEX IF NOT EXISTS idx_storage_owner_updated ON storages (owner, updated_at) WHERE NOT deleted",
+	"CREATE INDEX IF NOT EXISTS idx_storage_vault_created ON storages (vault, created_at) WHERE NOT deleted",
+	"CREATE INDEX IF NOT EXISTS idx_storage_vault_updated ON storages (vault, updated_at) WHERE NOT deleted",
+	"CREATE INDEX IF NOT EXISTS idx_storage_metadata ON storages USING GIN (metadata jsonb_path_ops)",
+}
+
+// SearchRecord retrieves the records of the owner, including the records
+// shared with the owner, which match the filter. Records are ordered by ID,
+// the page starts after the ID `filter.AfterID`. Shared records are not in
+// folders of the owner, so the folder filter leaves only the own records.
+func (s *DB) SearchRecord(owner int, filter domain.RecordFilter) ([]*domain.Storage, error) {
+	docs := []*domain.Storage{}
+
+	req := s.db.Model(&domain.Storage{}).
+		Select(sharedColumns).
+		Joins("JOIN users ON users.id = storages.owner").
+		Joins("LEFT JOIN shares ON shares.record_id = storages.id AND shares.grantee = ?", owner).
+		Where("storages.vault = 0 AND NOT storages.deleted AND (storages.owner = ? OR shares.grantee = ?)",
+			owner, owner)
+
+	if filter.Folder != nil {
+		req = req.Where("storages.owner = ? AND storages.folder = ?", owner, *filter.Folder)
+	}
+
+	req, err := whereFilter(req, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Find(&docs)
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return docs, nil
+}
+
+// SearchVaultRecord retrieves the records of the team vault which match the
+// filter. Records are ordered by ID, the page starts after the ID `filter.AfterID`.
+func (s *DB) SearchVaultRecord(vault int, filter domain.RecordFilter) ([]*domain.Storage, error) {
+	docs := []*domain.Storage{}
+
+	req := s.db.Model(&domain.Storage{}).
+		Select(vaultColumns).
+		Joins("JOIN users ON users.id = storages.owner").
+		Where("storages.vault = ? AND NOT storages.deleted", vault)
+
+	req, err := whereFilter(req, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Find(&docs)
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return docs, nil
+}
+
+// whereFilter applies the filter and the page to the query.
+func whereFilter(req *gorm.DB, filter domain.RecordFilter) (*gorm.DB, error) {
+	if filter.Name != "" {
+		req = req.Where(`storages.name ILIKE ? ESCAPE '\'`, "%"+escapeLike(filter.Name)+"%")
+	}
+
+	if filter.Type != "" {
+		req = req.Where("storages.type = ?", filter.Type)
+	}
+
+	req = whereTags(req, filter.Tags)
+
+	// Pairs are matched by the containment, keys without values by existence
+	pairs := map[string]string{}
+	for k, v := range filter.Metadata {
+		if v == "" {
+			req = req.Where("jsonb_exists(storages.metadata, ?)", k)
+			continue
+		}
+		pairs[k] = v
+	}
+
+	if len(pairs) != 0 {
+		data, err := json.Marshal(pairs)
+		if err != nil {
+			return nil, fmt.Errorf("failed encode metadata: %w", err)
+		}
+
+		req = req.Where("storages.metadata @> CAST(? AS jsonb)", string(data))
+	}
+
+	if !filter.CreatedAfter.IsZero() {
+		req = req.Where("storages.created_at >= ?", filter.CreatedAfter)
+	}
+
+	if !filter.CreatedBefore.IsZero() {
+		req = req.Where("storages.created_at <= ?", filter.CreatedBefore)
+	}
+
+	if !filter.UpdatedAfter.IsZero() {
+		req = req.Where("storages.updated_at >= ?", filter.UpdatedAfter)
+	}
+
+	if !filter.UpdatedBefore.IsZero() {
+		req = req.Where("storages.updated_at <= ?", filter.UpdatedBefore)
+	}
+
+	req = req.Where("storages.id > ?", filter.AfterID).Order("storages.id")
+	if filter.Limit > 0 {
+		req = req.Limit(filter.Limit)
+	}
+
+	return req, nil
+}
+
+// createSearchIndexes creates the indexes of the search. The trigram index of
+// names requires the `pg_trgm` extension. When the server of the database
+// does not have the extension, a warning is logged and names are matched by
+// a sequential scan of the records of the owner. Other failures of the
+// extension are returned.
+func createSearchIndexes(lg *zap.Logger, db *gorm.DB) error {
+	for _, v := range searchIndexes {
+		err := db.Exec(v).Error
+		if err != nil {
+			return fmt.Errorf("failed create search index: %w", err)
+		}
+	}
+
+	var available bool
+	err := db.Raw("SELECT EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'pg_trgm')").
+		Scan(&available).Error
+	if err != nil {
+		return fmt.Errorf("failed check pg_trgm extension: %w", err)
+	}
+
+	if !available {
+		lg.Warn("Extension pg_trgm is not available, names are searched without the index")
+		return nil
+	}
+
+	err = db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error
+	if err != nil {
+		return fmt.Errorf("failed create pg_trgm extension: %w", err)
+	}
+
+	err = db.Exec("CREATE INDEX IF NOT EXISTS idx_storage_name_trgm ON storages USING GIN (name gin_trgm_ops)").Error
+	if err != nil {
+		return fmt.Errorf("failed create name index: %w", err)
+	}
+
+	return nil
+}
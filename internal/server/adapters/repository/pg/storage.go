@@ -6,18 +6,60 @@
 package repository
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// ReadAllRecord retrieves all storage records for a specific owner.
+// conflictColumn tells whether the record has unresolved conflicting versions.
+var conflictColumn = "EXISTS (SELECT 1 FROM conflicts WHERE conflicts.record_id = storages.id) AS conflict"
+
+// tagsColumn lists the names of the tags of the record separated by commas.
+var tagsColumn = "COALESCE((SELECT string_agg(tags.name, ',' ORDER BY tags.name) FROM record_tags " +
+	"JOIN tags ON tags.id = record_tags.tag_id WHERE record_tags.record_id = storages.id), '') AS tags"
+
+// sharedColumns are the columns selected for lists of records,
+// including the owner login and the permission of a share.
+var sharedColumns = "storages.id, storages.name, storages.type, storages.owner, storages.folder, " +
+	"storages.created_at, storages.updated_at, storages.revision, storages.versions, storages.metadata, " +
+	"storages.expires_at, storages.rotate_days, storages.value_updated_at, storages.otp_counter, " +
+	"users.login AS owner_login, shares.permission, " + conflictColumn + ", " + tagsColumn
+
+// vaultColumns are the columns selected for lists of records of a team vault.
+var vaultColumns = "storages.id, storages.name, storages.type, storages.owner, storages.vault, storages.folder, " +
+	"storages.created_at, storages.updated_at, storages.revision, storages.versions, storages.metadata, " +
+	"storages.expires_at, storages.rotate_days, storages.value_updated_at, storages.otp_counter, " +
+	"users.login AS owner_login, " + conflictColumn + ", " + tagsColumn
+
+// maxRenamedLen is the length of the name kept when a duplicate is renamed,
+// the suffix fits into the size of the column.
+const maxRenamedLen = 220
+
+// nextRevision takes the next revision of a changed record.
+var nextRevision = gorm.Expr("nextval('" + domain.StorageRevisionSeq + "')")
+
+// ReadAllRecord retrieves a page of storage records for a specific owner,
+// including the records shared with the owner by other users.
 // It uses the `Find` method to query the database for storage records
 // that match the specified owner. If no records are found, it returns
 // nil for both the slice of records and the error. If an error occurs
 // during the query, it returns the error.
-func (s *DB) ReadAllRecord(owner int) ([]*domain.Storage, error) {
+func (s *DB) ReadAllRecord(owner int, page domain.RecordPage) ([]*domain.Storage, error) {
 	docs := []*domain.Storage{}
 
-	req := s.db.Select("id", "name", "owner").Find(&docs, "owner = ?", owner)
+	req := s.db.Model(&domain.Storage{}).
+		Select(sharedColumns).
+		Joins("JOIN users ON users.id = storages.owner").
+		Joins("LEFT JOIN shares ON shares.record_id = storages.id AND shares.grantee = ?", owner).
+		Where("storages.vault = 0 AND NOT storages.deleted AND (storages.owner = ? OR shares.grantee = ?)",
+			owner, owner)
+
+	req = wherePage(req, page).Find(&docs)
 	if req.RowsAffected == 0 {
 		return nil, nil
 	}
@@ -29,15 +71,59 @@ func (s *DB) ReadAllRecord(owner int) ([]*domain.Storage, error) {
 	return docs, nil
 }
 
+// FindRecordByName retrieves a page of the personal records of the owner with
+// the name, or with names starting with it if `prefix` is set. Records shared
+// with the owner are not searched, names are unique only per owner.
+func (s *DB) FindRecordByName(owner int, name string, prefix bool, page domain.RecordPage) ([]*domain.Storage, error) {
+	docs := []*domain.Storage{}
+
+	req := s.db.Model(&domain.Storage{}).
+		Select(vaultColumns).
+		Joins("JOIN users ON users.id = storages.owner").
+		Where("storages.vault = 0 AND NOT storages.deleted AND storages.owner = ?", owner)
+
+	req = wherePage(whereName(req, name, prefix), page).Find(&docs)
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return docs, nil
+}
+
+// FindVaultRecordByName retrieves a page of the records of the team vault with
+// the name, or with names starting with it if `prefix` is set.
+func (s *DB) FindVaultRecordByName(vault int, name string, prefix bool, page domain.RecordPage) ([]*domain.Storage, error) {
+	docs := []*domain.Storage{}
+
+	req := s.db.Model(&domain.Storage{}).
+		Select(vaultColumns).
+		Joins("JOIN users ON users.id = storages.owner").
+		Where("storages.vault = ? AND NOT storages.deleted", vault)
+
+	req = wherePage(whereName(req, name, prefix), page).Find(&docs)
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return docs, nil
+}
+
 // ReadRecord retrieves a specific storage record by its ID and owner.
-// It uses the `First` method to query the database for a storage record
-// that matches the specified ID and owner. If no record is found, it returns
-// nil for both the record and the error. If an error occurs during the query,
-// it returns the error.
+// Records shared with the owner are returned too, with the permission
+// of the share. It uses the `Take` method to query the database for
+// a storage record that matches the specified ID and owner. If no record
+// is found, it returns nil for both the record and the error. If an error
+// occurs during the query, it returns the error.
 func (s *DB) ReadRecord(id int, owner int) (*domain.Storage, error) {
 	doc := domain.Storage{}
 
-	req := s.db.First(&doc, "id = ? AND owner = ?", id, owner)
+	req := s.db.Model(&domain.Storage{}).
+		Select("storages.*, users.login AS owner_login, shares.permission, "+conflictColumn).
+		Joins("JOIN users ON users.id = storages.owner").
+		Joins("LEFT JOIN shares ON shares.record_id = storages.id AND shares.grantee = ?", owner).
+		Where("storages.id = ? AND storages.vault = 0 AND NOT storages.deleted AND (storages.owner = ? OR shares.grantee = ?)",
+			id, owner, owner).
+		Take(&doc)
 	if req.RowsAffected == 0 {
 		//nolint:nilnil // This legal return
 		return nil, nil
@@ -54,6 +140,9 @@ func (s *DB) ReadRecord(id int, owner int) (*domain.Storage, error) {
 // It uses the `Create` method to insert the record. If an error occurs
 // during the insertion, it returns the error.
 func (s *DB) WriteRecord(doc domain.Storage) error {
+	now := time.Now()
+	doc.ValueUpdatedAt = &now
+
 	req := s.db.Create(&doc)
 	if req.Error != nil {
 		return req.Error
@@ -62,16 +151,375 @@ func (s *DB) WriteRecord(doc domain.Storage) error {
 	return nil
 }
 
-// DeleteRecord removes a storage record from the database by its ID and owner.
-// It uses the `Delete` method to remove the record. If an error occurs during
-// the deletion, it returns the error.
+// UpdateRecord replaces the name, type, encrypted value, revision vector and
+// metadata of an existing storage record and takes the next revision. The
+// owner of the record is never changed. Access checks are the responsibility
+// of the caller. The record is changed only if it still has the `revision`
+// the change was made to, false means it was changed by another write.
+func (s *DB) UpdateRecord(doc domain.Storage, revision int64) (bool, error) {
+	req := s.db.Model(&domain.Storage{}).
+		Where("id = ? AND revision = ? AND NOT deleted", doc.ID, revision).
+		Updates(recordChanges(doc))
+	if req.Error != nil {
+		return false, req.Error
+	}
+
+	return req.RowsAffected != 0, nil
+}
+
+// DeleteRecord moves a storage record of the owner to the trash by its ID.
+// The record keeps its value and shares, so it can be restored, and its
+// conflicting versions are removed in one transaction. If an error occurs
+// during the deletion, it returns the error.
 func (s *DB) DeleteRecord(id int, owner int) error {
-	doc := domain.Storage{}
+	//nolint:wrapcheck // This legal return
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		req := tx.Model(&domain.Storage{}).
+			Where("id = ? AND owner = ? AND vault = 0 AND NOT deleted", id, owner).
+			Updates(trashed())
+		if req.Error != nil {
+			return req.Error
+		}
+
+		if req.RowsAffected == 0 {
+			return nil
+		}
+
+		req = tx.Delete(&domain.Conflict{}, "record_id = ?", id)
+		if req.Error != nil {
+			return req.Error
+		}
 
-	req := s.db.Delete(&doc, "id = ? AND owner = ?", id, owner)
+		return nil
+	})
+}
+
+// ShareRecord grants the grantee access to the record. If the record is
+// already shared with the grantee, the permission is replaced.
+func (s *DB) ShareRecord(share domain.Share) error {
+	req := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "record_id"}, {Name: "grantee"}},
+		DoUpdates: clause.AssignmentColumns([]string{"permission"}),
+	}).Create(&share)
+	if req.Error != nil {
+		return req.Error
+	}
+
+	return nil
+}
+
+// RevokeShare removes the grant of the record for the grantee. Only shares
+// of records which belong to the owner are removed.
+func (s *DB) RevokeShare(id int, grantee int, owner int) error {
+	owned := s.db.Model(&domain.Storage{}).Select("id").Where("owner = ? AND vault = 0 AND NOT deleted", owner)
+
+	req := s.db.Where("record_id = ? AND grantee = ? AND record_id IN (?)", id, grantee, owned).
+		Delete(&domain.Share{})
 	if req.Error != nil {
 		return req.Error
 	}
 
 	return nil
 }
+
+// ListSharedWithMe retrieves the records shared with the grantee by other
+// users, with the owner login and the permission of every share.
+func (s *DB) ListSharedWithMe(grantee int) ([]*domain.Storage, error) {
+	docs := []*domain.Storage{}
+
+	req := s.db.Model(&domain.Storage{}).
+		Select(sharedColumns).
+		Joins("JOIN users ON users.id = storages.owner").
+		Joins("JOIN shares ON shares.record_id = storages.id").
+		Where("shares.grantee = ? AND NOT storages.deleted", grantee).
+		Order("storages.id").
+		Find(&docs)
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return docs, nil
+}
+
+// ReadAllVaultRecord retrieves a page of storage records of a team vault
+// with the login of the member who created every record.
+func (s *DB) ReadAllVaultRecord(vault int, page domain.RecordPage) ([]*domain.Storage, error) {
+	docs := []*domain.Storage{}
+
+	req := s.db.Model(&domain.Storage{}).
+		Select(vaultColumns).
+		Joins("JOIN users ON users.id = storages.owner").
+		Where("storages.vault = ? AND NOT storages.deleted", vault)
+
+	req = wherePage(req, page).Find(&docs)
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return docs, nil
+}
+
+// ReadVaultRecord retrieves a specific storage record of a team vault. If no
+// record is found, it returns nil for both the record and the error.
+func (s *DB) ReadVaultRecord(id int, vault int) (*domain.Storage, error) {
+	doc := domain.Storage{}
+
+	req := s.db.Model(&domain.Storage{}).
+		Select("storages.*, users.login AS owner_login, "+conflictColumn).
+		Joins("JOIN users ON users.id = storages.owner").
+		Where("storages.id = ? AND storages.vault = ? AND NOT storages.deleted", id, vault).
+		Take(&doc)
+	if req.RowsAffected == 0 {
+		//nolint:nilnil // This legal return
+		return nil, nil
+	}
+
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return &doc, nil
+}
+
+// DeleteVaultRecord moves a storage record of a team vault to the trash of
+// the vault.
+func (s *DB) DeleteVaultRecord(id int, vault int) error {
+	//nolint:wrapcheck // This legal return
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		req := tx.Model(&domain.Storage{}).
+			Where("id = ? AND vault = ? AND NOT deleted", id, vault).
+			Updates(trashed())
+		if req.Error != nil {
+			return req.Error
+		}
+
+		if req.RowsAffected == 0 {
+			return nil
+		}
+
+		req = tx.Delete(&domain.Conflict{}, "record_id = ?", id)
+		if req.Error != nil {
+			return req.Error
+		}
+
+		return nil
+	})
+}
+
+// SyncRecord retrieves the personal records of the owner, or the records of
+// the team vault if `vault` is not zero, changed by the transactions which
+// were in progress or not started at the `cursor`. Deleted records are
+// included as tombstones, their values are not selected. Records shared with
+// the owner are not part of the synchronization. It returns the cursor of the
+// next call: the oldest transaction in progress before the records are read,
+// so changes committed later are never skipped, some may be returned twice.
+func (s *DB) SyncRecord(owner int, vault int, cursor int64) ([]*domain.Storage, int64, error) {
+	docs := []*domain.Storage{}
+
+	// Every transaction before the horizon is finished and visible to the next read
+	var next int64
+	req := s.db.Raw("SELECT pg_snapshot_xmin(pg_current_snapshot())::text::bigint").Scan(&next)
+	if req.Error != nil {
+		return nil, 0, req.Error
+	}
+
+	req = s.db.Model(&domain.Storage{}).
+		Select(vaultColumns+", storages.deleted").
+		Joins("JOIN users ON users.id = storages.owner").
+		Where("storages.tx_id >= ?", cursor)
+
+	if vault != 0 {
+		req = req.Where("storages.vault = ?", vault)
+	} else {
+		req = req.Where("storages.vault = 0 AND storages.owner = ?", owner)
+	}
+
+	req = req.Order("storages.revision").Find(&docs)
+	if req.Error != nil {
+		return nil, 0, req.Error
+	}
+
+	return docs, next, nil
+}
+
+// txFunction saves the ID of the transaction which changes a storage record,
+// sync cursors are compared with it.
+var txFunction = `CREATE OR REPLACE FUNCTION set_storage_tx_id() RETURNS trigger AS $$
+BEGIN
+	NEW.tx_id := pg_current_xact_id()::text::bigint;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql`
+
+// txTrigger runs `txFunction` before records are created and changed.
+var txTrigger = "CREATE OR REPLACE TRIGGER storage_tx_id BEFORE INSERT OR UPDATE ON storages " +
+	"FOR EACH ROW EXECUTE FUNCTION set_storage_tx_id()"
+
+// createTxTrigger creates the trigger which saves the transaction of every
+// change of storage records.
+func createTxTrigger(db *gorm.DB) error {
+	err := db.Exec(txFunction).Error
+	if err != nil {
+		return fmt.Errorf("failed create transaction function: %w", err)
+	}
+
+	err = db.Exec(txTrigger).Error
+	if err != nil {
+		return fmt.Errorf("failed create transaction trigger: %w", err)
+	}
+
+	return nil
+}
+
+// nameIndexes are the unique indexes of the names of records.
+var nameIndexes = []string{"idx_storage_owner_name", "idx_storage_vault_name"}
+
+// createNameIndexes makes the names of records unique per owner for personal
+// records and per vault for records of team vaults, tombstones are not
+// counted. It is a one-off migration: while the indexes do not exist,
+// duplicate names written before them are renamed, the oldest record keeps
+// its name. The table is locked, so records are not written and other servers
+// do not migrate at the same time.
+func createNameIndexes(lg *zap.Logger, db *gorm.DB) error {
+	var count int64
+	err := db.Raw("SELECT count(*) FROM pg_indexes WHERE tablename = 'storages' AND indexname IN ?", nameIndexes).
+		Scan(&count).Error
+	if err != nil {
+		return fmt.Errorf("failed check name indexes: %w", err)
+	}
+
+	if count == int64(len(nameIndexes)) {
+		return nil
+	}
+
+	//nolint:wrapcheck // This legal return
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("LOCK TABLE storages IN SHARE ROW EXCLUSIVE MODE").Error
+		if err != nil {
+			return err
+		}
+
+		err = renameDuplicates(lg, tx)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_storage_owner_name ON storages (owner, name)
+WHERE vault = 0 AND NOT deleted`).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_storage_vault_name ON storages (vault, name)
+WHERE vault <> 0 AND NOT deleted`).Error
+	})
+}
+
+// renameDuplicates gives a free name to every record whose name is used by an
+// older record. The renamed records take the next revision, so devices get
+// the new names.
+func renameDuplicates(lg *zap.Logger, tx *gorm.DB) error {
+	var docs []domain.Storage
+	err := tx.Raw(`SELECT id, owner, vault, name FROM storages
+WHERE NOT deleted AND EXISTS (
+  SELECT 1 FROM storages o
+  WHERE NOT o.deleted AND o.vault = storages.vault AND o.name = storages.name AND o.id < storages.id
+    AND (storages.vault <> 0 OR o.owner = storages.owner)
+)
+ORDER BY id`).Scan(&docs).Error
+	if err != nil {
+		return fmt.Errorf("failed find duplicate names: %w", err)
+	}
+
+	for _, v := range docs {
+		name, err := freeName(tx, v)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&domain.Storage{}).Where("id = ?", v.ID).
+			Updates(map[string]interface{}{"name": name, "revision": nextRevision}).Error
+		if err != nil {
+			return fmt.Errorf("failed rename record: %w", err)
+		}
+
+		lg.Warn("Record renamed, the name is used by an older record",
+			zap.Int("id", v.ID), zap.String("name", v.Name), zap.String("new_name", name))
+	}
+
+	return nil
+}
+
+// freeName returns the name of the record with its ID as a suffix, a counter
+// is added while the name is used by another record of the same owner or vault.
+func freeName(tx *gorm.DB, doc domain.Storage) (string, error) {
+	base := []rune(doc.Name)
+	if len(base) > maxRenamedLen {
+		base = base[:maxRenamedLen]
+	}
+
+	for i := 1; ; i++ {
+		name := fmt.Sprintf("%s (%v)", string(base), doc.ID)
+		if i > 1 {
+			name = fmt.Sprintf("%s (%v-%v)", string(base), doc.ID, i)
+		}
+
+		var count int64
+		err := tx.Model(&domain.Storage{}).
+			Where("NOT deleted AND vault = ? AND name = ? AND (vault <> 0 OR owner = ?)", doc.Vault, name, doc.Owner).
+			Count(&count).Error
+		if err != nil {
+			return "", fmt.Errorf("failed check name: %w", err)
+		}
+
+		if count == 0 {
+			return name, nil
+		}
+	}
+}
+
+// whereName filters the records by the name, or by the prefix of the name.
+func whereName(req *gorm.DB, name string, prefix bool) *gorm.DB {
+	if !prefix {
+		return req.Where("storages.name = ?", name)
+	}
+
+	return req.Where(`storages.name LIKE ? ESCAPE '\'`, escapeLike(name)+"%")
+}
+
+// escapeLike escapes the wildcards of LIKE, so they are matched literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// recordChanges returns the columns of a changed record. The value is
+// written, so the rotation of the record starts again. The record takes the
+// next revision.
+func recordChanges(doc domain.Storage) map[string]interface{} {
+	now := time.Now()
+
+	return map[string]interface{}{
+		"name":             doc.Name,
+		"type":             doc.Type,
+		"value":            doc.Value,
+		"key":              doc.Key,
+		"versions":         doc.Versions,
+		"metadata":         doc.Metadata,
+		"updated_at":       now,
+		"value_updated_at": now,
+		"revision":         nextRevision,
+	}
+}
+
+// trashed returns the columns of a record moved to the trash. The record
+// takes the next revision, so devices remove it.
+func trashed() map[string]interface{} {
+	now := time.Now()
+
+	return map[string]interface{}{
+		"deleted":    true,
+		"deleted_at": now,
+		"updated_at": now,
+		"revision":   nextRevision,
+	}
+}
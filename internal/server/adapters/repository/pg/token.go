@@ -0,0 +1,66 @@
+// Package repository contains the data access layer for the application,
+// providing functions to interact with the database and perform operations
+// related to the domain entities such as `User` and `Storage`. This package
+// serves as an interface between the application services and the database,
+// utilizing an ORM (such as GORM) to execute queries and manage transactions.
+package repository
+
+import (
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+)
+
+// CreateAccessToken adds a new personal access token to the database.
+// It returns the created token with the ID assigned by the database.
+func (s *DB) CreateAccessToken(token domain.AccessToken) (*domain.AccessToken, error) {
+	req := s.db.Create(&token)
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return &token, nil
+}
+
+// FindAccessTokenByHash retrieves a personal access token by the hash of its value.
+// If the token is not found, it returns nil for both the token and the error.
+func (s *DB) FindAccessTokenByHash(hash string) (*domain.AccessToken, error) {
+	token := domain.AccessToken{}
+
+	req := s.db.First(&token, "hash = ?", hash)
+	if req.RowsAffected == 0 {
+		//nolint:nilnil // This legal return
+		return nil, nil
+	}
+
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return &token, nil
+}
+
+// ListAccessTokens retrieves all personal access tokens of a specific owner,
+// including revoked ones. The token hashes are not selected.
+func (s *DB) ListAccessTokens(owner int) ([]*domain.AccessToken, error) {
+	tokens := []*domain.AccessToken{}
+
+	req := s.db.Omit("hash").Order("id").Find(&tokens, "owner = ?", owner)
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return tokens, nil
+}
+
+// RevokeAccessToken marks a personal access token of the owner as revoked.
+// Revoked tokens are kept in the database, but are no longer accepted.
+// It returns false if the owner has no token with the ID.
+func (s *DB) RevokeAccessToken(id int, owner int) (bool, error) {
+	req := s.db.Model(&domain.AccessToken{}).
+		Where("id = ? AND owner = ?", id, owner).
+		Update("revoked", true)
+	if req.Error != nil {
+		return false, req.Error
+	}
+
+	return req.RowsAffected != 0, nil
+}
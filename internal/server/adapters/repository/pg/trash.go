@@ -0,0 +1,167 @@
+// Package repository contains the data access layer for the application,
+// providing functions to interact with the database and perform operations
+// related to the domain entities such as `User` and `Storage`. This package
+// serves as an interface between the application services and the database,
+// utilizing an ORM (such as GORM) to execute queries and manage transactions.
+package repository
+
+import (
+	"time"
+
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"gorm.io/gorm"
+)
+
+// trashColumns are the columns selected for the trash, tombstones deleted
+// before the trash existed have no deletion time and are not in the trash.
+var trashColumns = vaultColumns + ", storages.deleted_at"
+
+// ListTrash retrieves the personal records of the owner in the trash, the
+// latest deleted first.
+func (s *DB) ListTrash(owner int) ([]*domain.Storage, error) {
+	docs := []*domain.Storage{}
+
+	req := s.db.Model(&domain.Storage{}).
+		Select(trashColumns).
+		Joins("JOIN users ON users.id = storages.owner").
+		Where("storages.vault = 0 AND storages.owner = ? AND storages.deleted AND storages.deleted_at IS NOT NULL", owner).
+		Order("storages.deleted_at DESC, storages.id").
+		Find(&docs)
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return docs, nil
+}
+
+// ListVaultTrash retrieves the records in the trash of the team vault, the
+// latest deleted first.
+func (s *DB) ListVaultTrash(vault int) ([]*domain.Storage, error) {
+	docs := []*domain.Storage{}
+
+	req := s.db.Model(&domain.Storage{}).
+		Select(trashColumns).
+		Joins("JOIN users ON users.id = storages.owner").
+		Where("storages.vault = ? AND storages.deleted AND storages.deleted_at IS NOT NULL", vault).
+		Order("storages.deleted_at DESC, storages.id").
+		Find(&docs)
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return docs, nil
+}
+
+// RestoreRecord moves the personal record of the owner back from the trash.
+// A record whose folder was deleted is restored to the root. The unique
+// index of names fails the restore if another record took the name. It
+// reports whether the record was in the trash.
+func (s *DB) RestoreRecord(id int, owner int) (bool, error) {
+	req := s.db.Model(&domain.Storage{}).
+		Where("id = ? AND owner = ? AND vault = 0 AND deleted AND deleted_at IS NOT NULL", id, owner).
+		Updates(restored())
+	if req.Error != nil {
+		return false, req.Error
+	}
+
+	return req.RowsAffected != 0, nil
+}
+
+// RestoreVaultRecord moves the record of the team vault back from the trash.
+// It reports whether the record was in the trash.
+func (s *DB) RestoreVaultRecord(id int, vault int) (bool, error) {
+	req := s.db.Model(&domain.Storage{}).
+		Where("id = ? AND vault = ? AND deleted AND deleted_at IS NOT NULL", id, vault).
+		Updates(restored())
+	if req.Error != nil {
+		return false, req.Error
+	}
+
+	return req.RowsAffected != 0, nil
+}
+
+// EmptyTrash permanently removes the values of the personal records of the
+// owner in the trash and returns their number.
+func (s *DB) EmptyTrash(owner int) (int64, error) {
+	return s.purgeRecords("vault = 0 AND owner = ? AND deleted AND deleted_at IS NOT NULL", owner)
+}
+
+// EmptyVaultTrash permanently removes the values of the records in the trash
+// of the team vault and returns their number.
+func (s *DB) EmptyVaultTrash(vault int) (int64, error) {
+	return s.purgeRecords("vault = ? AND deleted AND deleted_at IS NOT NULL", vault)
+}
+
+// PurgeTrash permanently removes the values of the records of all users and
+// vaults deleted before the time and returns their number.
+func (s *DB) PurgeTrash(before time.Time) (int64, error) {
+	return s.purgeRecords("deleted AND deleted_at < ?", before)
+}
+
+// purgeRecords permanently removes the values of the deleted records matching
+// the condition with their shares, conflicting versions and tags in one
+// transaction. The records are not removed: they stay as tombstones with an
+// empty value and no deletion time, like the tombstones deleted before the
+// trash existed, so devices syncing from any cursor remove them.
+func (s *DB) purgeRecords(query string, args ...interface{}) (int64, error) {
+	var count int64
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var purged []int
+
+		req := tx.Model(&domain.Storage{}).Where(query, args...).Pluck("id", &purged)
+		if req.Error != nil {
+			return req.Error
+		}
+
+		if len(purged) == 0 {
+			return nil
+		}
+
+		for _, v := range []interface{}{&domain.Share{}, &domain.Conflict{}, &domain.RecordTag{}} {
+			req = tx.Where("record_id IN ?", purged).Delete(v)
+			if req.Error != nil {
+				return req.Error
+			}
+		}
+
+		req = tx.Model(&domain.Storage{}).Where("id IN ? AND deleted", purged).Updates(tombstone())
+		if req.Error != nil {
+			return req.Error
+		}
+
+		count = req.RowsAffected
+		return nil
+	})
+
+	//nolint:wrapcheck // This legal return
+	return count, err
+}
+
+// tombstone returns the columns of a record purged from the trash. Only the
+// ID, the name and the type are kept, the record takes the next revision.
+func tombstone() map[string]interface{} {
+	return map[string]interface{}{
+		"value":       "",
+		"key":         "",
+		"metadata":    "{}",
+		"versions":    "{}",
+		"expires_at":  nil,
+		"rotate_days": 0,
+		"deleted_at":  nil,
+		"revision":    nextRevision,
+	}
+}
+
+// restored returns the columns of a record moved back from the trash. The
+// record takes the next revision, so devices get it again.
+func restored() map[string]interface{} {
+	return map[string]interface{}{
+		"deleted":    false,
+		"deleted_at": nil,
+		"updated_at": time.Now(),
+		"revision":   nextRevision,
+		"folder": gorm.Expr("CASE WHEN EXISTS (SELECT 1 FROM folders WHERE folders.id = storages.folder " +
+			"AND folders.owner = storages.owner) THEN storages.folder ELSE 0 END"),
+	}
+}
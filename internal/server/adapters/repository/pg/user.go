@@ -29,6 +29,24 @@ func (s *DB) FindUserByLogin(login string) (*domain.User, error) {
 	return &user, nil
 }
 
+// FindUserByID retrieves a user by their ID. If the user is not found,
+// it returns `nil` for both the user and error.
+func (s *DB) FindUserByID(id int) (*domain.User, error) {
+	user := domain.User{}
+
+	req := s.db.First(&user, "id = ?", id)
+	if req.RowsAffected == 0 {
+		//nolint:nilnil // This legal return
+		return nil, nil
+	}
+
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return &user, nil
+}
+
 // CreateUser creates a new user with the given login and hashed password.
 // It uses the ORM `Create` method to add the new user to the database.
 // If an error occurs during the database operation, it returns `nil` for
@@ -46,3 +64,36 @@ func (s *DB) CreateUser(login, hash string) (*domain.User, error) {
 
 	return &user, nil
 }
+
+// CreateUserWithVerifier creates a new user registered with SRP. The user
+// has no password hash, only the salt and the verifier.
+func (s *DB) CreateUserWithVerifier(login string, salt, verifier []byte) (*domain.User, error) {
+	user := domain.User{
+		Login:    login,
+		Salt:     salt,
+		Verifier: verifier,
+	}
+
+	req := s.db.Create(&user)
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return &user, nil
+}
+
+// SetVerifier replaces the credentials of the user with the SRP salt and
+// verifier. The legacy password hash is removed, so the user can no longer
+// sign in with the password flow.
+func (s *DB) SetVerifier(id int, salt, verifier []byte) error {
+	req := s.db.Model(&domain.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"salt":     salt,
+		"verifier": verifier,
+		"hash":     "",
+	})
+	if req.Error != nil {
+		return req.Error
+	}
+
+	return nil
+}
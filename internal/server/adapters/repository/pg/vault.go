@@ -0,0 +1,178 @@
+// Package repository contains the data access layer for the application,
+// providing functions to interact with the database and perform operations
+// related to the domain entities such as `User` and `Storage`. This package
+// serves as an interface between the application services and the database,
+// utilizing an ORM (such as GORM) to execute queries and manage transactions.
+package repository
+
+import (
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateOrganization creates a new organization and makes the user its owner
+// in one transaction.
+func (s *DB) CreateOrganization(name string, owner int) (*domain.Organization, error) {
+	org := domain.Organization{Name: name}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		req := tx.Create(&org)
+		if req.Error != nil {
+			return req.Error
+		}
+
+		req = tx.Create(&domain.Member{
+			OrgID:  org.ID,
+			UserID: owner,
+			Role:   domain.RoleOwner,
+		})
+		if req.Error != nil {
+			return req.Error
+		}
+
+		return nil
+	})
+	if err != nil {
+		//nolint:wrapcheck // This legal return
+		return nil, err
+	}
+
+	return &org, nil
+}
+
+// CreateVault adds a new vault to an organization.
+func (s *DB) CreateVault(vault domain.Vault) (*domain.Vault, error) {
+	req := s.db.Create(&vault)
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return &vault, nil
+}
+
+// FindVault retrieves a vault by its ID. If the vault is not found,
+// it returns nil for both the vault and the error.
+func (s *DB) FindVault(id int) (*domain.Vault, error) {
+	vault := domain.Vault{}
+
+	req := s.db.First(&vault, "id = ?", id)
+	if req.RowsAffected == 0 {
+		//nolint:nilnil // This legal return
+		return nil, nil
+	}
+
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return &vault, nil
+}
+
+// FindMember retrieves the membership of the user in the organization.
+// If the user is not a member, it returns nil for both the member and the error.
+func (s *DB) FindMember(orgID int, userID int) (*domain.Member, error) {
+	member := domain.Member{}
+
+	req := s.db.First(&member, "org_id = ? AND user_id = ?", orgID, userID)
+	if req.RowsAffected == 0 {
+		//nolint:nilnil // This legal return
+		return nil, nil
+	}
+
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return &member, nil
+}
+
+// AddMember adds the user to the organization. If the user is already
+// a member, the role is replaced. It returns false if the change would
+// demote the last owner of the organization.
+func (s *DB) AddMember(member domain.Member) (bool, error) {
+	changed := false
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if member.Role != domain.RoleOwner {
+			last, err := lastOwner(tx, member.OrgID, member.UserID)
+			if err != nil || last {
+				return err
+			}
+		}
+
+		req := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "org_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"role"}),
+		}).Create(&member)
+		if req.Error != nil {
+			return req.Error
+		}
+
+		changed = true
+		return nil
+	})
+
+	//nolint:wrapcheck // This legal return
+	return changed, err
+}
+
+// RemoveMember removes the user from the organization. It returns false if
+// the user is the last owner of the organization.
+func (s *DB) RemoveMember(orgID int, userID int) (bool, error) {
+	removed := false
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		last, err := lastOwner(tx, orgID, userID)
+		if err != nil || last {
+			return err
+		}
+
+		req := tx.Delete(&domain.Member{}, "org_id = ? AND user_id = ?", orgID, userID)
+		if req.Error != nil {
+			return req.Error
+		}
+
+		removed = true
+		return nil
+	})
+
+	//nolint:wrapcheck // This legal return
+	return removed, err
+}
+
+// lastOwner reports whether the user is the only owner of the organization.
+// The owners are locked until the end of the transaction, so that two owners
+// cannot demote each other at the same time.
+func lastOwner(tx *gorm.DB, orgID int, userID int) (bool, error) {
+	owners := []int{}
+
+	req := tx.Model(&domain.Member{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("org_id = ? AND role = ?", orgID, domain.RoleOwner).
+		Pluck("user_id", &owners)
+	if req.Error != nil {
+		return false, req.Error
+	}
+
+	return len(owners) == 1 && owners[0] == userID, nil
+}
+
+// ListVaults retrieves all vaults of the organizations the user is a member
+// of, with the organization name and the role of the user.
+func (s *DB) ListVaults(userID int) ([]*domain.Vault, error) {
+	vaults := []*domain.Vault{}
+
+	req := s.db.Model(&domain.Vault{}).
+		Select("vaults.id, vaults.org_id, vaults.name, organizations.name AS org_name, members.role").
+		Joins("JOIN organizations ON organizations.id = vaults.org_id").
+		Joins("JOIN members ON members.org_id = vaults.org_id").
+		Where("members.user_id = ?", userID).
+		Order("vaults.id").
+		Find(&vaults)
+	if req.Error != nil {
+		return nil, req.Error
+	}
+
+	return vaults, nil
+}
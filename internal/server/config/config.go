@@ -18,8 +18,14 @@ type ConfigENV struct {
 	CertificatePath    string `json:"certificate"`
 	CertificateKeyPath string `json:"certificate_key"`
 	MasterKey          string
+	LegacyAuth         bool `json:"legacy_auth" env:"LEGACY_AUTH"`
+	TrashRetentionDays int  `json:"trash_retention_days" env:"TRASH_RETENTION_DAYS"`
 }
 
+// defaultTrashRetentionDays is the number of days deleted records stay in
+// the trash if the retention period is not configured.
+var defaultTrashRetentionDays = 30
+
 // GetConfig get app settings.
 func GetConfig() (*ConfigENV, error) {
 	var eCfg ConfigENV
@@ -47,5 +53,9 @@ func GetConfig() (*ConfigENV, error) {
 		return nil, fmt.Errorf("failed parsing environment variables: %w", err)
 	}
 
+	if eCfg.TrashRetentionDays <= 0 {
+		eCfg.TrashRetentionDays = defaultTrashRetentionDays
+	}
+
 	return &eCfg, nil
 }
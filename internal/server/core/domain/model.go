@@ -5,15 +5,21 @@
 // using an ORM (such as GORM).
 package domain
 
+import "time"
+
 // User represents a user in the system. It includes an ID,
 // login, hashed password, and additional data for working with
 // the database. The `Password` field has the tag `gorm:"-:all"`
 // to exclude it from all ORM operations (create, read, etc.).
+// Users registered with SRP have the `Salt` and `Verifier` fields
+// instead of the bcrypt `Hash`, which is kept only for the legacy flow.
 type User struct {
 	ID       int    `json:"id"    gorm:"type:serial;autoIncrement;primaryKey;unique;not null"`
 	Login    string `json:"login" gorm:"type:string;size:256;unique;not null"`
 	Password string `json:"password" gorm:"-:all"`
-	Hash     string `gorm:"type:string;size:1000;not null"`
+	Hash     string `gorm:"type:string;size:1000"`
+	Salt     []byte `gorm:"type:bytes"`
+	Verifier []byte `gorm:"type:bytes"`
 }
 
 // Storage represents a data storage entry in the system.
@@ -21,6 +27,8 @@ type User struct {
 // This structure is used to represent various types of data stored
 // in the system. All fields have corresponding tags for JSON and
 // ORM GORM, ensuring proper data storage and serialization.
+// Fields tagged `->;-:migration` are read-only, they are filled by the
+// queries which list records.
 type Storage struct {
 	ID    int    `json:"id"    gorm:"type:serial;autoIncrement;primaryKey;unique;not null"`
 	Name  string `json:"name"  gorm:"type:string;size:256;not null"`
@@ -28,4 +36,294 @@ type Storage struct {
 	Value string `json:"text"  gorm:"type:string;not null"`
 	Key   string `gorm:"type:string;size:1000;not null"`
 	Owner int    `json:"owner" gorm:"type:int;not null"`
+	// Records of a team vault have a non-zero `Vault`, access to them is
+	// granted by the membership in the organization, `Owner` is the member
+	// who created the record.
+	Vault     int       `json:"vault"      gorm:"type:int;not null;default:0;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"type:timestamptz;not null;default:now()"`
+	// `UpdatedAt` changes with every change of the record, including the
+	// folder, tags, expiry and the trash.
+	UpdatedAt time.Time `json:"updated_at" gorm:"type:timestamptz;not null;default:now()"`
+	// `ValueUpdatedAt` is the time of the last write of the value, records
+	// written before the field existed have it nil.
+	ValueUpdatedAt *time.Time `json:"value_updated_at" gorm:"type:timestamptz"`
+	// Every change takes the next value of the `StorageRevisionSeq` sequence
+	// as its `Revision`. The revision is taken when the change is made, not
+	// when it is committed, so devices ask for changes by `TxID`, the ID of
+	// the transaction which made the last change: a sync cursor is the oldest
+	// transaction in progress when the records were read.
+	Revision int64 `json:"revision" gorm:"type:bigint;not null;default:nextval('storage_revision');index"`
+	TxID     int64 `json:"-"        gorm:"type:bigint;not null;default:0;index"`
+	// Deleted records are moved to the trash with `Deleted` and the time
+	// `DeletedAt`, they keep their values until the trash is emptied or
+	// purged. Purged records stay as tombstones with an empty value and no
+	// `DeletedAt`, so devices remove them on sync.
+	Deleted   bool       `json:"deleted"    gorm:"type:bool;not null;default:false"`
+	DeletedAt *time.Time `json:"deleted_at" gorm:"type:timestamptz;index"`
+	// `Versions` is the revision vector of the record, a JSON object with the
+	// number of writes made by every device, used to detect concurrent writes.
+	Versions string `json:"versions" gorm:"type:jsonb;not null;default:'{}'"`
+	// `Metadata` is a JSON object of user defined string attributes.
+	Metadata string `json:"metadata" gorm:"type:jsonb;not null;default:'{}'"`
+	// `Folder` is the folder of a personal record, zero is the root.
+	Folder int `json:"folder" gorm:"type:int;not null;default:0"`
+	// `ExpiresAt` is the optional expiry date of the secret and `RotateDays`
+	// the optional interval of its rotation in days, counted from
+	// `ValueUpdatedAt`.
+	ExpiresAt  *time.Time `json:"expires_at"  gorm:"type:timestamptz"`
+	RotateDays int        `json:"rotate_days" gorm:"type:int;not null;default:0"`
+	// `OTPCounter` is the number of HOTP codes issued for an OTP record, kept
+	// apart from the encrypted value so devices never reuse a counter.
+	OTPCounter int64 `json:"otp_counter" gorm:"type:bigint;not null;default:0"`
+	// `OwnerLogin` and `Permission` are filled for records shared with the user.
+	OwnerLogin string `json:"owner_login" gorm:"->;-:migration"`
+	Permission string `json:"permission"  gorm:"->;-:migration"`
+	// `Conflict` is set when the record has unresolved conflicting versions.
+	Conflict bool `json:"conflict" gorm:"->;-:migration"`
+	// `Tags` are the names of the tags of the record separated by commas.
+	Tags string `json:"tags" gorm:"->;-:migration"`
+}
+
+// Folder represents a folder of the personal records of a user. Folders form
+// a tree, `Parent` is zero for the folders at the root. Names of folders are
+// unique among the folders of the parent.
+type Folder struct {
+	ID     int    `json:"id"     gorm:"type:serial;autoIncrement;primaryKey;unique;not null"`
+	Owner  int    `json:"owner"  gorm:"type:int;not null;uniqueIndex:idx_folder_owner_parent_name"`
+	Parent int    `json:"parent" gorm:"type:int;not null;default:0;uniqueIndex:idx_folder_owner_parent_name"`
+	Name   string `json:"name"   gorm:"type:string;size:256;not null;uniqueIndex:idx_folder_owner_parent_name"`
+}
+
+// Tag represents a label of the records of a user. A record may have many
+// tags and a tag may label many records, they are linked by `RecordTag`.
+type Tag struct {
+	ID    int    `json:"id"    gorm:"type:serial;autoIncrement;primaryKey;unique;not null"`
+	Owner int    `json:"owner" gorm:"type:int;not null;uniqueIndex:idx_tag_owner_name"`
+	Name  string `json:"name"  gorm:"type:string;size:64;not null;uniqueIndex:idx_tag_owner_name"`
+}
+
+// RecordTag links a storage record with a tag.
+type RecordTag struct {
+	RecordID int `json:"record_id" gorm:"type:int;not null;primaryKey"`
+	TagID    int `json:"tag_id"    gorm:"type:int;not null;primaryKey;index"`
+}
+
+// RecordFilter represents the filters of a search over storage records.
+// Empty filters are not applied: `Name` matches a substring of the name,
+// every pair of `Metadata` must be an attribute of the record, a pair with
+// an empty value only requires the key. Time ranges include their bounds.
+// A record must have every tag of `Tags`. `Folder` limits the search to
+// the personal records of the folder, nil searches all folders.
+// Records are returned in the order of IDs after the ID `AfterID`, at most
+// `Limit` of them.
+type RecordFilter struct {
+	Name          string
+	Type          string
+	Metadata      map[string]string
+	Tags          []string
+	Folder        *int
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedAfter  time.Time
+	UpdatedBefore time.Time
+	AfterID       int
+	Limit         int
+}
+
+// Sort orders of lists of storage records.
+const (
+	SortID      = "id"
+	SortName    = "name"
+	SortCreated = "created"
+	SortUpdated = "updated"
+)
+
+// RecordPage represents a page of a list of storage records sorted by `Sort`,
+// descending if `Desc` is set. Records with equal keys are sorted by ID. The
+// page starts after the record with the ID `AfterID` and the key `AfterName`
+// or `AfterTime`, the first page has a zero `AfterID`. A page has at most
+// `Limit` records, a zero `Limit` returns all of them.
+type RecordPage struct {
+	Sort      string
+	Desc      bool
+	AfterID   int
+	AfterName string
+	AfterTime time.Time
+	Limit     int
+}
+
+// Expiry statuses of storage records. A record is expiring when its expiry
+// date or the date of its next rotation is within the warning period, and
+// expired when the date has passed. Records without both have no status.
+const (
+	ExpiryOK       = "ok"
+	ExpiryExpiring = "expiring"
+	ExpiryExpired  = "expired"
+)
+
+// ExpiringDays is the default warning period of expiring records in days.
+const ExpiringDays = 14
+
+// StorageRevisionSeq is the database sequence of storage record revisions.
+const StorageRevisionSeq = "storage_revision"
+
+// Kinds of record events.
+const (
+	EventCreate = "create"
+	EventUpdate = "update"
+	EventDelete = "delete"
+)
+
+// RecordEventsChannel is the database notification channel of record events.
+const RecordEventsChannel = "storage_events"
+
+// RecordEvent represents a change of a storage record sent to the watchers
+// of the record. The database notification carries only the IDs, `Name`,
+// `Type` and `Grantees`, the users the record is shared with, are read when
+// the event is received. A non-zero `Grantee` marks an event about a share
+// of the record, it is sent to this grantee only.
+type RecordEvent struct {
+	Kind     string `json:"kind"`
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Owner    int    `json:"owner"`
+	Vault    int    `json:"vault"`
+	Revision int64  `json:"revision"`
+	Grantee  int    `json:"grantee"`
+	Grantees []int  `json:"grantees"`
+}
+
+// Conflict represents a version of a storage record written concurrently
+// with the current one. The version is kept until the user resolves the
+// conflict by picking one of the versions. `Owner` is the user who wrote
+// the version from the device `Device`.
+type Conflict struct {
+	ID        int       `json:"id"         gorm:"type:serial;autoIncrement;primaryKey;unique;not null"`
+	RecordID  int       `json:"record_id"  gorm:"type:int;not null;index"`
+	Owner     int       `json:"owner"      gorm:"type:int;not null"`
+	Device    string    `json:"device"     gorm:"type:string;size:64;not null"`
+	Name      string    `json:"name"       gorm:"type:string;size:256;not null"`
+	Type      string    `json:"type"       gorm:"type:string;size:256;not null"`
+	Value     string    `json:"text"       gorm:"type:string;not null"`
+	Key       string    `gorm:"type:string;size:1000;not null"`
+	Versions  string    `json:"versions"   gorm:"type:jsonb;not null;default:'{}'"`
+	Metadata  string    `json:"metadata"   gorm:"type:jsonb;not null;default:'{}'"`
+	CreatedAt time.Time `json:"created_at" gorm:"type:timestamptz;not null;default:now()"`
+}
+
+// Permissions of shared records.
+const (
+	PermissionRead  = "read"
+	PermissionWrite = "write"
+)
+
+// Share represents a grant of access to a single storage record for
+// another user (grantee). The permission is either read or write, write
+// allows the grantee to replace the record value, but not to delete it.
+type Share struct {
+	ID         int    `json:"id"         gorm:"type:serial;autoIncrement;primaryKey;unique;not null"`
+	RecordID   int    `json:"record_id"  gorm:"type:int;not null;uniqueIndex:idx_share_record_grantee"`
+	Grantee    int    `json:"grantee"    gorm:"type:int;not null;uniqueIndex:idx_share_record_grantee;index"`
+	Permission string `json:"permission" gorm:"type:string;size:16;not null"`
+}
+
+// Scopes of personal access tokens. A token may be granted any
+// combination of them, an interactive session has all of them.
+const (
+	ScopeRead   = "read"
+	ScopeWrite  = "write"
+	ScopeDelete = "delete"
+)
+
+// AccessTokenPrefix marks personal access tokens, so that the auth layer
+// can tell them apart from JWT sessions without parsing.
+const AccessTokenPrefix = "gkp_"
+
+// AccessToken represents a long-lived personal access token used for
+// automation. Only the SHA-256 hash of the token is stored. Scopes, record
+// IDs and name prefix are kept as comma separated lists, empty restrictions
+// mean that the token is not limited by them.
+type AccessToken struct {
+	ID         int        `json:"id"          gorm:"type:serial;autoIncrement;primaryKey;unique;not null"`
+	Owner      int        `json:"owner"       gorm:"type:int;not null;index"`
+	Name       string     `json:"name"        gorm:"type:string;size:256;not null"`
+	Hash       string     `gorm:"type:string;size:64;uniqueIndex;not null"`
+	Scopes     string     `json:"scopes"      gorm:"type:string;size:256;not null"`
+	RecordIDs  string     `json:"record_ids"  gorm:"type:string;size:1000"`
+	NamePrefix string     `json:"name_prefix" gorm:"type:string;size:256"`
+	ExpiresAt  *time.Time `json:"expires_at"  gorm:"type:timestamptz"`
+	Revoked    bool       `json:"revoked"     gorm:"type:bool;not null;default:false"`
+}
+
+// Roles of organization members.
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+// Permissions granted to organization roles.
+const (
+	ActionRead   = "read"
+	ActionWrite  = "write"
+	ActionDelete = "delete"
+	ActionManage = "manage"
+	ActionOwnOrg = "own"
+)
+
+// RolePermissions maps every organization role to the actions it allows.
+var RolePermissions = map[string][]string{
+	RoleOwner:  {ActionRead, ActionWrite, ActionDelete, ActionManage, ActionOwnOrg},
+	RoleAdmin:  {ActionRead, ActionWrite, ActionDelete, ActionManage},
+	RoleEditor: {ActionRead, ActionWrite, ActionDelete},
+	RoleViewer: {ActionRead},
+}
+
+// Organization represents a team. Users are members of an organization
+// with a role, the organization owns vaults with shared records.
+type Organization struct {
+	ID   int    `json:"id"   gorm:"type:serial;autoIncrement;primaryKey;unique;not null"`
+	Name string `json:"name" gorm:"type:string;size:256;not null"`
+}
+
+// Vault represents a collection of records owned by an organization.
+type Vault struct {
+	ID      int    `json:"id"     gorm:"type:serial;autoIncrement;primaryKey;unique;not null"`
+	OrgID   int    `json:"org_id" gorm:"type:int;not null;index"`
+	Name    string `json:"name"   gorm:"type:string;size:256;not null"`
+	OrgName string `json:"org_name" gorm:"->;-:migration"`
+	Role    string `json:"role"     gorm:"->;-:migration"`
+}
+
+// Member represents the membership of a user in an organization with a role.
+type Member struct {
+	ID     int    `json:"id"      gorm:"type:serial;autoIncrement;primaryKey;unique;not null"`
+	OrgID  int    `json:"org_id"  gorm:"type:int;not null;uniqueIndex:idx_member_org_user"`
+	UserID int    `json:"user_id" gorm:"type:int;not null;uniqueIndex:idx_member_org_user;index"`
+	Role   string `json:"role"    gorm:"type:string;size:16;not null"`
+}
+
+// Statuses of emergency contacts.
+const (
+	EmergencyInvited   = "invited"
+	EmergencyRequested = "requested"
+	EmergencyRejected  = "rejected"
+	EmergencyGranted   = "granted"
+)
+
+// EmergencyContact represents the nomination of a user (grantee) who may get
+// read access to the records of another user (grantor) in an emergency.
+// The grantee requests access, the grantor may reject the request while
+// the waiting period has not passed, after it the access is granted.
+type EmergencyContact struct {
+	ID           int        `json:"id"           gorm:"type:serial;autoIncrement;primaryKey;unique;not null"`
+	Grantor      int        `json:"grantor"      gorm:"type:int;not null;uniqueIndex:idx_emergency_grantor_grantee"`
+	Grantee      int        `json:"grantee"      gorm:"type:int;not null;uniqueIndex:idx_emergency_grantor_grantee;index"`
+	WaitHours    int        `json:"wait_hours"   gorm:"type:int;not null"`
+	Status       string     `json:"status"       gorm:"type:string;size:16;not null"`
+	RequestedAt  *time.Time `json:"requested_at" gorm:"type:timestamptz"`
+	GrantorLogin string     `json:"grantor_login" gorm:"->;-:migration"`
+	GranteeLogin string     `json:"grantee_login" gorm:"->;-:migration"`
 }
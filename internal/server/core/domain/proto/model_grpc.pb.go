@@ -21,8 +21,20 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	User_Register_FullMethodName = "/proto.User/Register"
-	User_Login_FullMethodName    = "/proto.User/Login"
+	User_Register_FullMethodName               = "/proto.User/Register"
+	User_Login_FullMethodName                  = "/proto.User/Login"
+	User_SRPRegister_FullMethodName            = "/proto.User/SRPRegister"
+	User_SRPLoginStart_FullMethodName          = "/proto.User/SRPLoginStart"
+	User_SRPLoginFinish_FullMethodName         = "/proto.User/SRPLoginFinish"
+	User_SetVerifier_FullMethodName            = "/proto.User/SetVerifier"
+	User_CreateAccessToken_FullMethodName      = "/proto.User/CreateAccessToken"
+	User_ListAccessTokens_FullMethodName       = "/proto.User/ListAccessTokens"
+	User_RevokeAccessToken_FullMethodName      = "/proto.User/RevokeAccessToken"
+	User_AddEmergencyContact_FullMethodName    = "/proto.User/AddEmergencyContact"
+	User_RemoveEmergencyContact_FullMethodName = "/proto.User/RemoveEmergencyContact"
+	User_ListEmergencyContacts_FullMethodName  = "/proto.User/ListEmergencyContacts"
+	User_RequestEmergencyAccess_FullMethodName = "/proto.User/RequestEmergencyAccess"
+	User_RejectEmergencyAccess_FullMethodName  = "/proto.User/RejectEmergencyAccess"
 )
 
 // UserClient is the client API for User service.
@@ -31,6 +43,18 @@ const (
 type UserClient interface {
 	Register(ctx context.Context, in *RegiserRequest, opts ...grpc.CallOption) (*RegisterResponse, error)
 	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
+	SRPRegister(ctx context.Context, in *SRPRegisterRequest, opts ...grpc.CallOption) (*SRPRegisterResponse, error)
+	SRPLoginStart(ctx context.Context, in *SRPLoginStartRequest, opts ...grpc.CallOption) (*SRPLoginStartResponse, error)
+	SRPLoginFinish(ctx context.Context, in *SRPLoginFinishRequest, opts ...grpc.CallOption) (*SRPLoginFinishResponse, error)
+	SetVerifier(ctx context.Context, in *SetVerifierRequest, opts ...grpc.CallOption) (*SetVerifierResponse, error)
+	CreateAccessToken(ctx context.Context, in *CreateAccessTokenRequest, opts ...grpc.CallOption) (*CreateAccessTokenResponse, error)
+	ListAccessTokens(ctx context.Context, in *ListAccessTokensRequest, opts ...grpc.CallOption) (*ListAccessTokensResponse, error)
+	RevokeAccessToken(ctx context.Context, in *RevokeAccessTokenRequest, opts ...grpc.CallOption) (*RevokeAccessTokenResponse, error)
+	AddEmergencyContact(ctx context.Context, in *AddEmergencyContactRequest, opts ...grpc.CallOption) (*AddEmergencyContactResponse, error)
+	RemoveEmergencyContact(ctx context.Context, in *RemoveEmergencyContactRequest, opts ...grpc.CallOption) (*RemoveEmergencyContactResponse, error)
+	ListEmergencyContacts(ctx context.Context, in *ListEmergencyContactsRequest, opts ...grpc.CallOption) (*ListEmergencyContactsResponse, error)
+	RequestEmergencyAccess(ctx context.Context, in *RequestEmergencyAccessRequest, opts ...grpc.CallOption) (*RequestEmergencyAccessResponse, error)
+	RejectEmergencyAccess(ctx context.Context, in *RejectEmergencyAccessRequest, opts ...grpc.CallOption) (*RejectEmergencyAccessResponse, error)
 }
 
 type userClient struct {
@@ -59,12 +83,132 @@ func (c *userClient) Login(ctx context.Context, in *LoginRequest, opts ...grpc.C
 	return out, nil
 }
 
+func (c *userClient) SRPRegister(ctx context.Context, in *SRPRegisterRequest, opts ...grpc.CallOption) (*SRPRegisterResponse, error) {
+	out := new(SRPRegisterResponse)
+	err := c.cc.Invoke(ctx, User_SRPRegister_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) SRPLoginStart(ctx context.Context, in *SRPLoginStartRequest, opts ...grpc.CallOption) (*SRPLoginStartResponse, error) {
+	out := new(SRPLoginStartResponse)
+	err := c.cc.Invoke(ctx, User_SRPLoginStart_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) SRPLoginFinish(ctx context.Context, in *SRPLoginFinishRequest, opts ...grpc.CallOption) (*SRPLoginFinishResponse, error) {
+	out := new(SRPLoginFinishResponse)
+	err := c.cc.Invoke(ctx, User_SRPLoginFinish_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) SetVerifier(ctx context.Context, in *SetVerifierRequest, opts ...grpc.CallOption) (*SetVerifierResponse, error) {
+	out := new(SetVerifierResponse)
+	err := c.cc.Invoke(ctx, User_SetVerifier_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) CreateAccessToken(ctx context.Context, in *CreateAccessTokenRequest, opts ...grpc.CallOption) (*CreateAccessTokenResponse, error) {
+	out := new(CreateAccessTokenResponse)
+	err := c.cc.Invoke(ctx, User_CreateAccessToken_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) ListAccessTokens(ctx context.Context, in *ListAccessTokensRequest, opts ...grpc.CallOption) (*ListAccessTokensResponse, error) {
+	out := new(ListAccessTokensResponse)
+	err := c.cc.Invoke(ctx, User_ListAccessTokens_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) RevokeAccessToken(ctx context.Context, in *RevokeAccessTokenRequest, opts ...grpc.CallOption) (*RevokeAccessTokenResponse, error) {
+	out := new(RevokeAccessTokenResponse)
+	err := c.cc.Invoke(ctx, User_RevokeAccessToken_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) AddEmergencyContact(ctx context.Context, in *AddEmergencyContactRequest, opts ...grpc.CallOption) (*AddEmergencyContactResponse, error) {
+	out := new(AddEmergencyContactResponse)
+	err := c.cc.Invoke(ctx, User_AddEmergencyContact_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) RemoveEmergencyContact(ctx context.Context, in *RemoveEmergencyContactRequest, opts ...grpc.CallOption) (*RemoveEmergencyContactResponse, error) {
+	out := new(RemoveEmergencyContactResponse)
+	err := c.cc.Invoke(ctx, User_RemoveEmergencyContact_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) ListEmergencyContacts(ctx context.Context, in *ListEmergencyContactsRequest, opts ...grpc.CallOption) (*ListEmergencyContactsResponse, error) {
+	out := new(ListEmergencyContactsResponse)
+	err := c.cc.Invoke(ctx, User_ListEmergencyContacts_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) RequestEmergencyAccess(ctx context.Context, in *RequestEmergencyAccessRequest, opts ...grpc.CallOption) (*RequestEmergencyAccessResponse, error) {
+	out := new(RequestEmergencyAccessResponse)
+	err := c.cc.Invoke(ctx, User_RequestEmergencyAccess_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) RejectEmergencyAccess(ctx context.Context, in *RejectEmergencyAccessRequest, opts ...grpc.CallOption) (*RejectEmergencyAccessResponse, error) {
+	out := new(RejectEmergencyAccessResponse)
+	err := c.cc.Invoke(ctx, User_RejectEmergencyAccess_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // UserServer is the server API for User service.
 // All implementations must embed UnimplementedUserServer
 // for forward compatibility
 type UserServer interface {
 	Register(context.Context, *RegiserRequest) (*RegisterResponse, error)
 	Login(context.Context, *LoginRequest) (*LoginResponse, error)
+	SRPRegister(context.Context, *SRPRegisterRequest) (*SRPRegisterResponse, error)
+	SRPLoginStart(context.Context, *SRPLoginStartRequest) (*SRPLoginStartResponse, error)
+	SRPLoginFinish(context.Context, *SRPLoginFinishRequest) (*SRPLoginFinishResponse, error)
+	SetVerifier(context.Context, *SetVerifierRequest) (*SetVerifierResponse, error)
+	CreateAccessToken(context.Context, *CreateAccessTokenRequest) (*CreateAccessTokenResponse, error)
+	ListAccessTokens(context.Context, *ListAccessTokensRequest) (*ListAccessTokensResponse, error)
+	RevokeAccessToken(context.Context, *RevokeAccessTokenRequest) (*RevokeAccessTokenResponse, error)
+	AddEmergencyContact(context.Context, *AddEmergencyContactRequest) (*AddEmergencyContactResponse, error)
+	RemoveEmergencyContact(context.Context, *RemoveEmergencyContactRequest) (*RemoveEmergencyContactResponse, error)
+	ListEmergencyContacts(context.Context, *ListEmergencyContactsRequest) (*ListEmergencyContactsResponse, error)
+	RequestEmergencyAccess(context.Context, *RequestEmergencyAccessRequest) (*RequestEmergencyAccessResponse, error)
+	RejectEmergencyAccess(context.Context, *RejectEmergencyAccessRequest) (*RejectEmergencyAccessResponse, error)
 	mustEmbedUnimplementedUserServer()
 }
 
@@ -78,307 +222,1686 @@ func (UnimplementedUserServer) Register(context.Context, *RegiserRequest) (*Regi
 func (UnimplementedUserServer) Login(context.Context, *LoginRequest) (*LoginResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Login not implemented")
 }
+func (UnimplementedUserServer) SRPRegister(context.Context, *SRPRegisterRequest) (*SRPRegisterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SRPRegister not implemented")
+}
+func (UnimplementedUserServer) SRPLoginStart(context.Context, *SRPLoginStartRequest) (*SRPLoginStartResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SRPLoginStart not implemented")
+}
+func (UnimplementedUserServer) SRPLoginFinish(context.Context, *SRPLoginFinishRequest) (*SRPLoginFinishResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SRPLoginFinish not implemented")
+}
+func (UnimplementedUserServer) SetVerifier(context.Context, *SetVerifierRequest) (*SetVerifierResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetVerifier not implemented")
+}
+func (UnimplementedUserServer) CreateAccessToken(context.Context, *CreateAccessTokenRequest) (*CreateAccessTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAccessToken not implemented")
+}
+func (UnimplementedUserServer) ListAccessTokens(context.Context, *ListAccessTokensRequest) (*ListAccessTokensResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAccessTokens not implemented")
+}
+func (UnimplementedUserServer) RevokeAccessToken(context.Context, *RevokeAccessTokenRequest) (*RevokeAccessTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeAccessToken not implemented")
+}
+func (UnimplementedUserServer) AddEmergencyContact(context.Context, *AddEmergencyContactRequest) (*AddEmergencyContactResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddEmergencyContact not implemented")
+}
+func (UnimplementedUserServer) RemoveEmergencyContact(context.Context, *RemoveEmergencyContactRequest) (*RemoveEmergencyContactResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveEmergencyContact not implemented")
+}
+func (UnimplementedUserServer) ListEmergencyContacts(context.Context, *ListEmergencyContactsRequest) (*ListEmergencyContactsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEmergencyContacts not implemented")
+}
+func (UnimplementedUserServer) RequestEmergencyAccess(context.Context, *RequestEmergencyAccessRequest) (*RequestEmergencyAccessResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestEmergencyAccess not implemented")
+}
+func (UnimplementedUserServer) RejectEmergencyAccess(context.Context, *RejectEmergencyAccessRequest) (*RejectEmergencyAccessResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RejectEmergencyAccess not implemented")
+}
 func (UnimplementedUserServer) mustEmbedUnimplementedUserServer() {}
 
-// UnsafeUserServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to UserServer will
-// result in compilation errors.
-type UnsafeUserServer interface {
-	mustEmbedUnimplementedUserServer()
+// UnsafeUserServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to UserServer will
+// result in compilation errors.
+type UnsafeUserServer interface {
+	mustEmbedUnimplementedUserServer()
+}
+
+func RegisterUserServer(s grpc.ServiceRegistrar, srv UserServer) {
+	s.RegisterService(&User_ServiceDesc, srv)
+}
+
+func _User_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegiserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).Register(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: User_Register_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).Register(ctx, req.(*RegiserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).Login(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: User_Login_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).Login(ctx, req.(*LoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_SRPRegister_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SRPRegisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).SRPRegister(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: User_SRPRegister_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).SRPRegister(ctx, req.(*SRPRegisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_SRPLoginStart_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SRPLoginStartRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).SRPLoginStart(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: User_SRPLoginStart_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).SRPLoginStart(ctx, req.(*SRPLoginStartRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_SRPLoginFinish_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SRPLoginFinishRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).SRPLoginFinish(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: User_SRPLoginFinish_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).SRPLoginFinish(ctx, req.(*SRPLoginFinishRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_SetVerifier_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetVerifierRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).SetVerifier(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: User_SetVerifier_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).SetVerifier(ctx, req.(*SetVerifierRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_CreateAccessToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAccessTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).CreateAccessToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: User_CreateAccessToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).CreateAccessToken(ctx, req.(*CreateAccessTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_ListAccessTokens_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAccessTokensRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).ListAccessTokens(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: User_ListAccessTokens_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).ListAccessTokens(ctx, req.(*ListAccessTokensRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_RevokeAccessToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeAccessTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).RevokeAccessToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: User_RevokeAccessToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).RevokeAccessToken(ctx, req.(*RevokeAccessTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_AddEmergencyContact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddEmergencyContactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).AddEmergencyContact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: User_AddEmergencyContact_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).AddEmergencyContact(ctx, req.(*AddEmergencyContactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_RemoveEmergencyContact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveEmergencyContactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).RemoveEmergencyContact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: User_RemoveEmergencyContact_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).RemoveEmergencyContact(ctx, req.(*RemoveEmergencyContactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_ListEmergencyContacts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEmergencyContactsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).ListEmergencyContacts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: User_ListEmergencyContacts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).ListEmergencyContacts(ctx, req.(*ListEmergencyContactsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_RequestEmergencyAccess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestEmergencyAccessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).RequestEmergencyAccess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: User_RequestEmergencyAccess_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).RequestEmergencyAccess(ctx, req.(*RequestEmergencyAccessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_RejectEmergencyAccess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RejectEmergencyAccessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).RejectEmergencyAccess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: User_RejectEmergencyAccess_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).RejectEmergencyAccess(ctx, req.(*RejectEmergencyAccessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// User_ServiceDesc is the grpc.ServiceDesc for User service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var User_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.User",
+	HandlerType: (*UserServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Register",
+			Handler:    _User_Register_Handler,
+		},
+		{
+			MethodName: "Login",
+			Handler:    _User_Login_Handler,
+		},
+		{
+			MethodName: "SRPRegister",
+			Handler:    _User_SRPRegister_Handler,
+		},
+		{
+			MethodName: "SRPLoginStart",
+			Handler:    _User_SRPLoginStart_Handler,
+		},
+		{
+			MethodName: "SRPLoginFinish",
+			Handler:    _User_SRPLoginFinish_Handler,
+		},
+		{
+			MethodName: "SetVerifier",
+			Handler:    _User_SetVerifier_Handler,
+		},
+		{
+			MethodName: "CreateAccessToken",
+			Handler:    _User_CreateAccessToken_Handler,
+		},
+		{
+			MethodName: "ListAccessTokens",
+			Handler:    _User_ListAccessTokens_Handler,
+		},
+		{
+			MethodName: "RevokeAccessToken",
+			Handler:    _User_RevokeAccessToken_Handler,
+		},
+		{
+			MethodName: "AddEmergencyContact",
+			Handler:    _User_AddEmergencyContact_Handler,
+		},
+		{
+			MethodName: "RemoveEmergencyContact",
+			Handler:    _User_RemoveEmergencyContact_Handler,
+		},
+		{
+			MethodName: "ListEmergencyContacts",
+			Handler:    _User_ListEmergencyContacts_Handler,
+		},
+		{
+			MethodName: "RequestEmergencyAccess",
+			Handler:    _User_RequestEmergencyAccess_Handler,
+		},
+		{
+			MethodName: "RejectEmergencyAccess",
+			Handler:    _User_RejectEmergencyAccess_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "internal/server/core/domain/proto/model.proto",
+}
+
+const (
+	Storage_ReadRecord_FullMethodName          = "/proto.Storage/ReadRecord"
+	Storage_ReadAllRecord_FullMethodName       = "/proto.Storage/ReadAllRecord"
+	Storage_FindRecordByName_FullMethodName    = "/proto.Storage/FindRecordByName"
+	Storage_SearchRecords_FullMethodName       = "/proto.Storage/SearchRecords"
+	Storage_WriteRecord_FullMethodName         = "/proto.Storage/WriteRecord"
+	Storage_DeleteRecord_FullMethodName        = "/proto.Storage/DeleteRecord"
+	Storage_ShareRecord_FullMethodName         = "/proto.Storage/ShareRecord"
+	Storage_RevokeShare_FullMethodName         = "/proto.Storage/RevokeShare"
+	Storage_ListSharedWithMe_FullMethodName    = "/proto.Storage/ListSharedWithMe"
+	Storage_Sync_FullMethodName                = "/proto.Storage/Sync"
+	Storage_ListConflicts_FullMethodName       = "/proto.Storage/ListConflicts"
+	Storage_ResolveConflict_FullMethodName     = "/proto.Storage/ResolveConflict"
+	Storage_Watch_FullMethodName               = "/proto.Storage/Watch"
+	Storage_CreateFolder_FullMethodName        = "/proto.Storage/CreateFolder"
+	Storage_RenameFolder_FullMethodName        = "/proto.Storage/RenameFolder"
+	Storage_DeleteFolder_FullMethodName        = "/proto.Storage/DeleteFolder"
+	Storage_ListFolders_FullMethodName         = "/proto.Storage/ListFolders"
+	Storage_MoveRecord_FullMethodName          = "/proto.Storage/MoveRecord"
+	Storage_TagRecord_FullMethodName           = "/proto.Storage/TagRecord"
+	Storage_UntagRecord_FullMethodName         = "/proto.Storage/UntagRecord"
+	Storage_ListTrash_FullMethodName           = "/proto.Storage/ListTrash"
+	Storage_RestoreRecord_FullMethodName       = "/proto.Storage/RestoreRecord"
+	Storage_EmptyTrash_FullMethodName          = "/proto.Storage/EmptyTrash"
+	Storage_SetRecordExpiry_FullMethodName     = "/proto.Storage/SetRecordExpiry"
+	Storage_ListExpiringRecords_FullMethodName = "/proto.Storage/ListExpiringRecords"
+	Storage_NextOTPCounter_FullMethodName      = "/proto.Storage/NextOTPCounter"
+)
+
+// StorageClient is the client API for Storage service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type StorageClient interface {
+	ReadRecord(ctx context.Context, in *ReadRecordRequest, opts ...grpc.CallOption) (*ReadRecordResponse, error)
+	ReadAllRecord(ctx context.Context, in *ReadAllRecordRequest, opts ...grpc.CallOption) (*ReadAllRecordResponse, error)
+	FindRecordByName(ctx context.Context, in *FindRecordByNameRequest, opts ...grpc.CallOption) (*FindRecordByNameResponse, error)
+	SearchRecords(ctx context.Context, in *SearchRecordsRequest, opts ...grpc.CallOption) (*SearchRecordsResponse, error)
+	WriteRecord(ctx context.Context, opts ...grpc.CallOption) (Storage_WriteRecordClient, error)
+	DeleteRecord(ctx context.Context, in *DeleteRecordRequest, opts ...grpc.CallOption) (*DeleteRecordResponse, error)
+	ShareRecord(ctx context.Context, in *ShareRecordRequest, opts ...grpc.CallOption) (*ShareRecordResponse, error)
+	RevokeShare(ctx context.Context, in *RevokeShareRequest, opts ...grpc.CallOption) (*RevokeShareResponse, error)
+	ListSharedWithMe(ctx context.Context, in *ListSharedWithMeRequest, opts ...grpc.CallOption) (*ListSharedWithMeResponse, error)
+	Sync(ctx context.Context, in *SyncRequest, opts ...grpc.CallOption) (*SyncResponse, error)
+	ListConflicts(ctx context.Context, in *ListConflictsRequest, opts ...grpc.CallOption) (*ListConflictsResponse, error)
+	ResolveConflict(ctx context.Context, in *ResolveConflictRequest, opts ...grpc.CallOption) (*ResolveConflictResponse, error)
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Storage_WatchClient, error)
+	CreateFolder(ctx context.Context, in *CreateFolderRequest, opts ...grpc.CallOption) (*CreateFolderResponse, error)
+	RenameFolder(ctx context.Context, in *RenameFolderRequest, opts ...grpc.CallOption) (*RenameFolderResponse, error)
+	DeleteFolder(ctx context.Context, in *DeleteFolderRequest, opts ...grpc.CallOption) (*DeleteFolderResponse, error)
+	ListFolders(ctx context.Context, in *ListFoldersRequest, opts ...grpc.CallOption) (*ListFoldersResponse, error)
+	MoveRecord(ctx context.Context, in *MoveRecordRequest, opts ...grpc.CallOption) (*MoveRecordResponse, error)
+	TagRecord(ctx context.Context, in *TagRecordRequest, opts ...grpc.CallOption) (*TagRecordResponse, error)
+	UntagRecord(ctx context.Context, in *UntagRecordRequest, opts ...grpc.CallOption) (*UntagRecordResponse, error)
+	ListTrash(ctx context.Context, in *ListTrashRequest, opts ...grpc.CallOption) (*ListTrashResponse, error)
+	RestoreRecord(ctx context.Context, in *RestoreRecordRequest, opts ...grpc.CallOption) (*RestoreRecordResponse, error)
+	EmptyTrash(ctx context.Context, in *EmptyTrashRequest, opts ...grpc.CallOption) (*EmptyTrashResponse, error)
+	SetRecordExpiry(ctx context.Context, in *SetRecordExpiryRequest, opts ...grpc.CallOption) (*SetRecordExpiryResponse, error)
+	ListExpiringRecords(ctx context.Context, in *ListExpiringRecordsRequest, opts ...grpc.CallOption) (*ListExpiringRecordsResponse, error)
+	NextOTPCounter(ctx context.Context, in *NextOTPCounterRequest, opts ...grpc.CallOption) (*NextOTPCounterResponse, error)
+}
+
+type storageClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewStorageClient(cc grpc.ClientConnInterface) StorageClient {
+	return &storageClient{cc}
+}
+
+func (c *storageClient) ReadRecord(ctx context.Context, in *ReadRecordRequest, opts ...grpc.CallOption) (*ReadRecordResponse, error) {
+	out := new(ReadRecordResponse)
+	err := c.cc.Invoke(ctx, Storage_ReadRecord_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) ReadAllRecord(ctx context.Context, in *ReadAllRecordRequest, opts ...grpc.CallOption) (*ReadAllRecordResponse, error) {
+	out := new(ReadAllRecordResponse)
+	err := c.cc.Invoke(ctx, Storage_ReadAllRecord_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) FindRecordByName(ctx context.Context, in *FindRecordByNameRequest, opts ...grpc.CallOption) (*FindRecordByNameResponse, error) {
+	out := new(FindRecordByNameResponse)
+	err := c.cc.Invoke(ctx, Storage_FindRecordByName_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) SearchRecords(ctx context.Context, in *SearchRecordsRequest, opts ...grpc.CallOption) (*SearchRecordsResponse, error) {
+	out := new(SearchRecordsResponse)
+	err := c.cc.Invoke(ctx, Storage_SearchRecords_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) WriteRecord(ctx context.Context, opts ...grpc.CallOption) (Storage_WriteRecordClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Storage_ServiceDesc.Streams[0], Storage_WriteRecord_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &storageWriteRecordClient{stream}
+	return x, nil
+}
+
+type Storage_WriteRecordClient interface {
+	Send(*WriteRecordRequest) error
+	CloseAndRecv() (*WriteRecordResponse, error)
+	grpc.ClientStream
+}
+
+type storageWriteRecordClient struct {
+	grpc.ClientStream
+}
+
+func (x *storageWriteRecordClient) Send(m *WriteRecordRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *storageWriteRecordClient) CloseAndRecv() (*WriteRecordResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(WriteRecordResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *storageClient) DeleteRecord(ctx context.Context, in *DeleteRecordRequest, opts ...grpc.CallOption) (*DeleteRecordResponse, error) {
+	out := new(DeleteRecordResponse)
+	err := c.cc.Invoke(ctx, Storage_DeleteRecord_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) ShareRecord(ctx context.Context, in *ShareRecordRequest, opts ...grpc.CallOption) (*ShareRecordResponse, error) {
+	out := new(ShareRecordResponse)
+	err := c.cc.Invoke(ctx, Storage_ShareRecord_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) RevokeShare(ctx context.Context, in *RevokeShareRequest, opts ...grpc.CallOption) (*RevokeShareResponse, error) {
+	out := new(RevokeShareResponse)
+	err := c.cc.Invoke(ctx, Storage_RevokeShare_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) ListSharedWithMe(ctx context.Context, in *ListSharedWithMeRequest, opts ...grpc.CallOption) (*ListSharedWithMeResponse, error) {
+	out := new(ListSharedWithMeResponse)
+	err := c.cc.Invoke(ctx, Storage_ListSharedWithMe_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) Sync(ctx context.Context, in *SyncRequest, opts ...grpc.CallOption) (*SyncResponse, error) {
+	out := new(SyncResponse)
+	err := c.cc.Invoke(ctx, Storage_Sync_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) ListConflicts(ctx context.Context, in *ListConflictsRequest, opts ...grpc.CallOption) (*ListConflictsResponse, error) {
+	out := new(ListConflictsResponse)
+	err := c.cc.Invoke(ctx, Storage_ListConflicts_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) ResolveConflict(ctx context.Context, in *ResolveConflictRequest, opts ...grpc.CallOption) (*ResolveConflictResponse, error) {
+	out := new(ResolveConflictResponse)
+	err := c.cc.Invoke(ctx, Storage_ResolveConflict_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Storage_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Storage_ServiceDesc.Streams[1], Storage_Watch_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &storageWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Storage_WatchClient interface {
+	Recv() (*WatchEvent, error)
+	grpc.ClientStream
+}
+
+type storageWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *storageWatchClient) Recv() (*WatchEvent, error) {
+	m := new(WatchEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *storageClient) CreateFolder(ctx context.Context, in *CreateFolderRequest, opts ...grpc.CallOption) (*CreateFolderResponse, error) {
+	out := new(CreateFolderResponse)
+	err := c.cc.Invoke(ctx, Storage_CreateFolder_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) RenameFolder(ctx context.Context, in *RenameFolderRequest, opts ...grpc.CallOption) (*RenameFolderResponse, error) {
+	out := new(RenameFolderResponse)
+	err := c.cc.Invoke(ctx, Storage_RenameFolder_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) DeleteFolder(ctx context.Context, in *DeleteFolderRequest, opts ...grpc.CallOption) (*DeleteFolderResponse, error) {
+	out := new(DeleteFolderResponse)
+	err := c.cc.Invoke(ctx, Storage_DeleteFolder_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) ListFolders(ctx context.Context, in *ListFoldersRequest, opts ...grpc.CallOption) (*ListFoldersResponse, error) {
+	out := new(ListFoldersResponse)
+	err := c.cc.Invoke(ctx, Storage_ListFolders_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) MoveRecord(ctx context.Context, in *MoveRecordRequest, opts ...grpc.CallOption) (*MoveRecordResponse, error) {
+	out := new(MoveRecordResponse)
+	err := c.cc.Invoke(ctx, Storage_MoveRecord_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) TagRecord(ctx context.Context, in *TagRecordRequest, opts ...grpc.CallOption) (*TagRecordResponse, error) {
+	out := new(TagRecordResponse)
+	err := c.cc.Invoke(ctx, Storage_TagRecord_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) UntagRecord(ctx context.Context, in *UntagRecordRequest, opts ...grpc.CallOption) (*UntagRecordResponse, error) {
+	out := new(UntagRecordResponse)
+	err := c.cc.Invoke(ctx, Storage_UntagRecord_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) ListTrash(ctx context.Context, in *ListTrashRequest, opts ...grpc.CallOption) (*ListTrashResponse, error) {
+	out := new(ListTrashResponse)
+	err := c.cc.Invoke(ctx, Storage_ListTrash_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) RestoreRecord(ctx context.Context, in *RestoreRecordRequest, opts ...grpc.CallOption) (*RestoreRecordResponse, error) {
+	out := new(RestoreRecordResponse)
+	err := c.cc.Invoke(ctx, Storage_RestoreRecord_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) EmptyTrash(ctx context.Context, in *EmptyTrashRequest, opts ...grpc.CallOption) (*EmptyTrashResponse, error) {
+	out := new(EmptyTrashResponse)
+	err := c.cc.Invoke(ctx, Storage_EmptyTrash_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) SetRecordExpiry(ctx context.Context, in *SetRecordExpiryRequest, opts ...grpc.CallOption) (*SetRecordExpiryResponse, error) {
+	out := new(SetRecordExpiryResponse)
+	err := c.cc.Invoke(ctx, Storage_SetRecordExpiry_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) ListExpiringRecords(ctx context.Context, in *ListExpiringRecordsRequest, opts ...grpc.CallOption) (*ListExpiringRecordsResponse, error) {
+	out := new(ListExpiringRecordsResponse)
+	err := c.cc.Invoke(ctx, Storage_ListExpiringRecords_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) NextOTPCounter(ctx context.Context, in *NextOTPCounterRequest, opts ...grpc.CallOption) (*NextOTPCounterResponse, error) {
+	out := new(NextOTPCounterResponse)
+	err := c.cc.Invoke(ctx, Storage_NextOTPCounter_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StorageServer is the server API for Storage service.
+// All implementations must embed UnimplementedStorageServer
+// for forward compatibility
+type StorageServer interface {
+	ReadRecord(context.Context, *ReadRecordRequest) (*ReadRecordResponse, error)
+	ReadAllRecord(context.Context, *ReadAllRecordRequest) (*ReadAllRecordResponse, error)
+	FindRecordByName(context.Context, *FindRecordByNameRequest) (*FindRecordByNameResponse, error)
+	SearchRecords(context.Context, *SearchRecordsRequest) (*SearchRecordsResponse, error)
+	WriteRecord(Storage_WriteRecordServer) error
+	DeleteRecord(context.Context, *DeleteRecordRequest) (*DeleteRecordResponse, error)
+	ShareRecord(context.Context, *ShareRecordRequest) (*ShareRecordResponse, error)
+	RevokeShare(context.Context, *RevokeShareRequest) (*RevokeShareResponse, error)
+	ListSharedWithMe(context.Context, *ListSharedWithMeRequest) (*ListSharedWithMeResponse, error)
+	Sync(context.Context, *SyncRequest) (*SyncResponse, error)
+	ListConflicts(context.Context, *ListConflictsRequest) (*ListConflictsResponse, error)
+	ResolveConflict(context.Context, *ResolveConflictRequest) (*ResolveConflictResponse, error)
+	Watch(*WatchRequest, Storage_WatchServer) error
+	CreateFolder(context.Context, *CreateFolderRequest) (*CreateFolderResponse, error)
+	RenameFolder(context.Context, *RenameFolderRequest) (*RenameFolderResponse, error)
+	DeleteFolder(context.Context, *DeleteFolderRequest) (*DeleteFolderResponse, error)
+	ListFolders(context.Context, *ListFoldersRequest) (*ListFoldersResponse, error)
+	MoveRecord(context.Context, *MoveRecordRequest) (*MoveRecordResponse, error)
+	TagRecord(context.Context, *TagRecordRequest) (*TagRecordResponse, error)
+	UntagRecord(context.Context, *UntagRecordRequest) (*UntagRecordResponse, error)
+	ListTrash(context.Context, *ListTrashRequest) (*ListTrashResponse, error)
+	RestoreRecord(context.Context, *RestoreRecordRequest) (*RestoreRecordResponse, error)
+	EmptyTrash(context.Context, *EmptyTrashRequest) (*EmptyTrashResponse, error)
+	SetRecordExpiry(context.Context, *SetRecordExpiryRequest) (*SetRecordExpiryResponse, error)
+	ListExpiringRecords(context.Context, *ListExpiringRecordsRequest) (*ListExpiringRecordsResponse, error)
+	NextOTPCounter(context.Context, *NextOTPCounterRequest) (*NextOTPCounterResponse, error)
+	mustEmbedUnimplementedStorageServer()
+}
+
+// UnimplementedStorageServer must be embedded to have forward compatible implementations.
+type UnimplementedStorageServer struct {
+}
+
+func (UnimplementedStorageServer) ReadRecord(context.Context, *ReadRecordRequest) (*ReadRecordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadRecord not implemented")
+}
+func (UnimplementedStorageServer) ReadAllRecord(context.Context, *ReadAllRecordRequest) (*ReadAllRecordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadAllRecord not implemented")
+}
+func (UnimplementedStorageServer) FindRecordByName(context.Context, *FindRecordByNameRequest) (*FindRecordByNameResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindRecordByName not implemented")
+}
+func (UnimplementedStorageServer) SearchRecords(context.Context, *SearchRecordsRequest) (*SearchRecordsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchRecords not implemented")
+}
+func (UnimplementedStorageServer) WriteRecord(Storage_WriteRecordServer) error {
+	return status.Errorf(codes.Unimplemented, "method WriteRecord not implemented")
+}
+func (UnimplementedStorageServer) DeleteRecord(context.Context, *DeleteRecordRequest) (*DeleteRecordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteRecord not implemented")
+}
+func (UnimplementedStorageServer) ShareRecord(context.Context, *ShareRecordRequest) (*ShareRecordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ShareRecord not implemented")
+}
+func (UnimplementedStorageServer) RevokeShare(context.Context, *RevokeShareRequest) (*RevokeShareResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeShare not implemented")
+}
+func (UnimplementedStorageServer) ListSharedWithMe(context.Context, *ListSharedWithMeRequest) (*ListSharedWithMeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSharedWithMe not implemented")
+}
+func (UnimplementedStorageServer) Sync(context.Context, *SyncRequest) (*SyncResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Sync not implemented")
+}
+func (UnimplementedStorageServer) ListConflicts(context.Context, *ListConflictsRequest) (*ListConflictsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListConflicts not implemented")
+}
+func (UnimplementedStorageServer) ResolveConflict(context.Context, *ResolveConflictRequest) (*ResolveConflictResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResolveConflict not implemented")
+}
+func (UnimplementedStorageServer) Watch(*WatchRequest, Storage_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedStorageServer) CreateFolder(context.Context, *CreateFolderRequest) (*CreateFolderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateFolder not implemented")
+}
+func (UnimplementedStorageServer) RenameFolder(context.Context, *RenameFolderRequest) (*RenameFolderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenameFolder not implemented")
+}
+func (UnimplementedStorageServer) DeleteFolder(context.Context, *DeleteFolderRequest) (*DeleteFolderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteFolder not implemented")
+}
+func (UnimplementedStorageServer) ListFolders(context.Context, *ListFoldersRequest) (*ListFoldersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFolders not implemented")
+}
+func (UnimplementedStorageServer) MoveRecord(context.Context, *MoveRecordRequest) (*MoveRecordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MoveRecord not implemented")
+}
+func (UnimplementedStorageServer) TagRecord(context.Context, *TagRecordRequest) (*TagRecordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TagRecord not implemented")
+}
+func (UnimplementedStorageServer) UntagRecord(context.Context, *UntagRecordRequest) (*UntagRecordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UntagRecord not implemented")
+}
+func (UnimplementedStorageServer) ListTrash(context.Context, *ListTrashRequest) (*ListTrashResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTrash not implemented")
+}
+func (UnimplementedStorageServer) RestoreRecord(context.Context, *RestoreRecordRequest) (*RestoreRecordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreRecord not implemented")
+}
+func (UnimplementedStorageServer) EmptyTrash(context.Context, *EmptyTrashRequest) (*EmptyTrashResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EmptyTrash not implemented")
+}
+func (UnimplementedStorageServer) SetRecordExpiry(context.Context, *SetRecordExpiryRequest) (*SetRecordExpiryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetRecordExpiry not implemented")
+}
+func (UnimplementedStorageServer) ListExpiringRecords(context.Context, *ListExpiringRecordsRequest) (*ListExpiringRecordsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListExpiringRecords not implemented")
+}
+func (UnimplementedStorageServer) NextOTPCounter(context.Context, *NextOTPCounterRequest) (*NextOTPCounterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NextOTPCounter not implemented")
+}
+func (UnimplementedStorageServer) mustEmbedUnimplementedStorageServer() {}
+
+// UnsafeStorageServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to StorageServer will
+// result in compilation errors.
+type UnsafeStorageServer interface {
+	mustEmbedUnimplementedStorageServer()
+}
+
+func RegisterStorageServer(s grpc.ServiceRegistrar, srv StorageServer) {
+	s.RegisterService(&Storage_ServiceDesc, srv)
+}
+
+func _Storage_ReadRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadRecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).ReadRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Storage_ReadRecord_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).ReadRecord(ctx, req.(*ReadRecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_ReadAllRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadAllRecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).ReadAllRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Storage_ReadAllRecord_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).ReadAllRecord(ctx, req.(*ReadAllRecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_FindRecordByName_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindRecordByNameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).FindRecordByName(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Storage_FindRecordByName_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).FindRecordByName(ctx, req.(*FindRecordByNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_SearchRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRecordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).SearchRecords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Storage_SearchRecords_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).SearchRecords(ctx, req.(*SearchRecordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_WriteRecord_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(StorageServer).WriteRecord(&storageWriteRecordServer{stream})
+}
+
+type Storage_WriteRecordServer interface {
+	SendAndClose(*WriteRecordResponse) error
+	Recv() (*WriteRecordRequest, error)
+	grpc.ServerStream
+}
+
+type storageWriteRecordServer struct {
+	grpc.ServerStream
+}
+
+func (x *storageWriteRecordServer) SendAndClose(m *WriteRecordResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *storageWriteRecordServer) Recv() (*WriteRecordRequest, error) {
+	m := new(WriteRecordRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Storage_DeleteRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).DeleteRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Storage_DeleteRecord_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).DeleteRecord(ctx, req.(*DeleteRecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_ShareRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShareRecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).ShareRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Storage_ShareRecord_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).ShareRecord(ctx, req.(*ShareRecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_RevokeShare_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeShareRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).RevokeShare(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Storage_RevokeShare_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).RevokeShare(ctx, req.(*RevokeShareRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_ListSharedWithMe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSharedWithMeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).ListSharedWithMe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Storage_ListSharedWithMe_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).ListSharedWithMe(ctx, req.(*ListSharedWithMeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_Sync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SyncRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).Sync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Storage_Sync_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).Sync(ctx, req.(*SyncRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_ListConflicts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListConflictsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).ListConflicts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Storage_ListConflicts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).ListConflicts(ctx, req.(*ListConflictsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_ResolveConflict_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveConflictRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).ResolveConflict(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Storage_ResolveConflict_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).ResolveConflict(ctx, req.(*ResolveConflictRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StorageServer).Watch(m, &storageWatchServer{stream})
+}
+
+type Storage_WatchServer interface {
+	Send(*WatchEvent) error
+	grpc.ServerStream
+}
+
+type storageWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *storageWatchServer) Send(m *WatchEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Storage_CreateFolder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateFolderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).CreateFolder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Storage_CreateFolder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).CreateFolder(ctx, req.(*CreateFolderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_RenameFolder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenameFolderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).RenameFolder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Storage_RenameFolder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).RenameFolder(ctx, req.(*RenameFolderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_DeleteFolder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteFolderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).DeleteFolder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Storage_DeleteFolder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).DeleteFolder(ctx, req.(*DeleteFolderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_ListFolders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFoldersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).ListFolders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Storage_ListFolders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).ListFolders(ctx, req.(*ListFoldersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_MoveRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveRecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).MoveRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Storage_MoveRecord_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).MoveRecord(ctx, req.(*MoveRecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_TagRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TagRecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).TagRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Storage_TagRecord_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).TagRecord(ctx, req.(*TagRecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_UntagRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UntagRecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).UntagRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Storage_UntagRecord_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).UntagRecord(ctx, req.(*UntagRecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_ListTrash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTrashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).ListTrash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Storage_ListTrash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).ListTrash(ctx, req.(*ListTrashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_RestoreRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreRecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).RestoreRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Storage_RestoreRecord_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).RestoreRecord(ctx, req.(*RestoreRecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_EmptyTrash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EmptyTrashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).EmptyTrash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Storage_EmptyTrash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).EmptyTrash(ctx, req.(*EmptyTrashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func RegisterUserServer(s grpc.ServiceRegistrar, srv UserServer) {
-	s.RegisterService(&User_ServiceDesc, srv)
+func _Storage_SetRecordExpiry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetRecordExpiryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).SetRecordExpiry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Storage_SetRecordExpiry_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).SetRecordExpiry(ctx, req.(*SetRecordExpiryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _User_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RegiserRequest)
+func _Storage_ListExpiringRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListExpiringRecordsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(UserServer).Register(ctx, in)
+		return srv.(StorageServer).ListExpiringRecords(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: User_Register_FullMethodName,
+		FullMethod: Storage_ListExpiringRecords_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(UserServer).Register(ctx, req.(*RegiserRequest))
+		return srv.(StorageServer).ListExpiringRecords(ctx, req.(*ListExpiringRecordsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _User_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(LoginRequest)
+func _Storage_NextOTPCounter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NextOTPCounterRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(UserServer).Login(ctx, in)
+		return srv.(StorageServer).NextOTPCounter(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: User_Login_FullMethodName,
+		FullMethod: Storage_NextOTPCounter_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(UserServer).Login(ctx, req.(*LoginRequest))
+		return srv.(StorageServer).NextOTPCounter(ctx, req.(*NextOTPCounterRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-// User_ServiceDesc is the grpc.ServiceDesc for User service.
+// Storage_ServiceDesc is the grpc.ServiceDesc for Storage service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
-var User_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "proto.User",
-	HandlerType: (*UserServer)(nil),
+var Storage_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.Storage",
+	HandlerType: (*StorageServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "Register",
-			Handler:    _User_Register_Handler,
+			MethodName: "ReadRecord",
+			Handler:    _Storage_ReadRecord_Handler,
 		},
 		{
-			MethodName: "Login",
-			Handler:    _User_Login_Handler,
+			MethodName: "ReadAllRecord",
+			Handler:    _Storage_ReadAllRecord_Handler,
+		},
+		{
+			MethodName: "FindRecordByName",
+			Handler:    _Storage_FindRecordByName_Handler,
+		},
+		{
+			MethodName: "SearchRecords",
+			Handler:    _Storage_SearchRecords_Handler,
+		},
+		{
+			MethodName: "DeleteRecord",
+			Handler:    _Storage_DeleteRecord_Handler,
+		},
+		{
+			MethodName: "ShareRecord",
+			Handler:    _Storage_ShareRecord_Handler,
+		},
+		{
+			MethodName: "RevokeShare",
+			Handler:    _Storage_RevokeShare_Handler,
+		},
+		{
+			MethodName: "ListSharedWithMe",
+			Handler:    _Storage_ListSharedWithMe_Handler,
+		},
+		{
+			MethodName: "Sync",
+			Handler:    _Storage_Sync_Handler,
+		},
+		{
+			MethodName: "ListConflicts",
+			Handler:    _Storage_ListConflicts_Handler,
+		},
+		{
+			MethodName: "ResolveConflict",
+			Handler:    _Storage_ResolveConflict_Handler,
+		},
+		{
+			MethodName: "CreateFolder",
+			Handler:    _Storage_CreateFolder_Handler,
+		},
+		{
+			MethodName: "RenameFolder",
+			Handler:    _Storage_RenameFolder_Handler,
+		},
+		{
+			MethodName: "DeleteFolder",
+			Handler:    _Storage_DeleteFolder_Handler,
+		},
+		{
+			MethodName: "ListFolders",
+			Handler:    _Storage_ListFolders_Handler,
+		},
+		{
+			MethodName: "MoveRecord",
+			Handler:    _Storage_MoveRecord_Handler,
+		},
+		{
+			MethodName: "TagRecord",
+			Handler:    _Storage_TagRecord_Handler,
+		},
+		{
+			MethodName: "UntagRecord",
+			Handler:    _Storage_UntagRecord_Handler,
+		},
+		{
+			MethodName: "ListTrash",
+			Handler:    _Storage_ListTrash_Handler,
+		},
+		{
+			MethodName: "RestoreRecord",
+			Handler:    _Storage_RestoreRecord_Handler,
+		},
+		{
+			MethodName: "EmptyTrash",
+			Handler:    _Storage_EmptyTrash_Handler,
+		},
+		{
+			MethodName: "SetRecordExpiry",
+			Handler:    _Storage_SetRecordExpiry_Handler,
+		},
+		{
+			MethodName: "ListExpiringRecords",
+			Handler:    _Storage_ListExpiringRecords_Handler,
+		},
+		{
+			MethodName: "NextOTPCounter",
+			Handler:    _Storage_NextOTPCounter_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WriteRecord",
+			Handler:       _Storage_WriteRecord_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Watch",
+			Handler:       _Storage_Watch_Handler,
+			ServerStreams: true,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "internal/server/core/domain/proto/model.proto",
 }
 
 const (
-	Storage_ReadRecord_FullMethodName    = "/proto.Storage/ReadRecord"
-	Storage_ReadAllRecord_FullMethodName = "/proto.Storage/ReadAllRecord"
-	Storage_WriteRecord_FullMethodName   = "/proto.Storage/WriteRecord"
-	Storage_DeleteRecord_FullMethodName  = "/proto.Storage/DeleteRecord"
+	Vault_CreateOrganization_FullMethodName = "/proto.Vault/CreateOrganization"
+	Vault_CreateVault_FullMethodName        = "/proto.Vault/CreateVault"
+	Vault_AddMember_FullMethodName          = "/proto.Vault/AddMember"
+	Vault_RemoveMember_FullMethodName       = "/proto.Vault/RemoveMember"
+	Vault_ListVaults_FullMethodName         = "/proto.Vault/ListVaults"
 )
 
-// StorageClient is the client API for Storage service.
+// VaultClient is the client API for Vault service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
-type StorageClient interface {
-	ReadRecord(ctx context.Context, in *ReadRecordRequest, opts ...grpc.CallOption) (*ReadRecordResponse, error)
-	ReadAllRecord(ctx context.Context, in *ReadAllRecordRequest, opts ...grpc.CallOption) (*ReadAllRecordResponse, error)
-	WriteRecord(ctx context.Context, opts ...grpc.CallOption) (Storage_WriteRecordClient, error)
-	DeleteRecord(ctx context.Context, in *DeleteRecordRequest, opts ...grpc.CallOption) (*DeleteRecordResponse, error)
+type VaultClient interface {
+	CreateOrganization(ctx context.Context, in *CreateOrganizationRequest, opts ...grpc.CallOption) (*CreateOrganizationResponse, error)
+	CreateVault(ctx context.Context, in *CreateVaultRequest, opts ...grpc.CallOption) (*CreateVaultResponse, error)
+	AddMember(ctx context.Context, in *AddMemberRequest, opts ...grpc.CallOption) (*AddMemberResponse, error)
+	RemoveMember(ctx context.Context, in *RemoveMemberRequest, opts ...grpc.CallOption) (*RemoveMemberResponse, error)
+	ListVaults(ctx context.Context, in *ListVaultsRequest, opts ...grpc.CallOption) (*ListVaultsResponse, error)
 }
 
-type storageClient struct {
+type vaultClient struct {
 	cc grpc.ClientConnInterface
 }
 
-func NewStorageClient(cc grpc.ClientConnInterface) StorageClient {
-	return &storageClient{cc}
+func NewVaultClient(cc grpc.ClientConnInterface) VaultClient {
+	return &vaultClient{cc}
 }
 
-func (c *storageClient) ReadRecord(ctx context.Context, in *ReadRecordRequest, opts ...grpc.CallOption) (*ReadRecordResponse, error) {
-	out := new(ReadRecordResponse)
-	err := c.cc.Invoke(ctx, Storage_ReadRecord_FullMethodName, in, out, opts...)
+func (c *vaultClient) CreateOrganization(ctx context.Context, in *CreateOrganizationRequest, opts ...grpc.CallOption) (*CreateOrganizationResponse, error) {
+	out := new(CreateOrganizationResponse)
+	err := c.cc.Invoke(ctx, Vault_CreateOrganization_FullMethodName, in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *storageClient) ReadAllRecord(ctx context.Context, in *ReadAllRecordRequest, opts ...grpc.CallOption) (*ReadAllRecordResponse, error) {
-	out := new(ReadAllRecordResponse)
-	err := c.cc.Invoke(ctx, Storage_ReadAllRecord_FullMethodName, in, out, opts...)
+func (c *vaultClient) CreateVault(ctx context.Context, in *CreateVaultRequest, opts ...grpc.CallOption) (*CreateVaultResponse, error) {
+	out := new(CreateVaultResponse)
+	err := c.cc.Invoke(ctx, Vault_CreateVault_FullMethodName, in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *storageClient) WriteRecord(ctx context.Context, opts ...grpc.CallOption) (Storage_WriteRecordClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Storage_ServiceDesc.Streams[0], Storage_WriteRecord_FullMethodName, opts...)
+func (c *vaultClient) AddMember(ctx context.Context, in *AddMemberRequest, opts ...grpc.CallOption) (*AddMemberResponse, error) {
+	out := new(AddMemberResponse)
+	err := c.cc.Invoke(ctx, Vault_AddMember_FullMethodName, in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &storageWriteRecordClient{stream}
-	return x, nil
-}
-
-type Storage_WriteRecordClient interface {
-	Send(*WriteRecordRequest) error
-	CloseAndRecv() (*WriteRecordResponse, error)
-	grpc.ClientStream
-}
-
-type storageWriteRecordClient struct {
-	grpc.ClientStream
-}
-
-func (x *storageWriteRecordClient) Send(m *WriteRecordRequest) error {
-	return x.ClientStream.SendMsg(m)
+	return out, nil
 }
 
-func (x *storageWriteRecordClient) CloseAndRecv() (*WriteRecordResponse, error) {
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
-	}
-	m := new(WriteRecordResponse)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
+func (c *vaultClient) RemoveMember(ctx context.Context, in *RemoveMemberRequest, opts ...grpc.CallOption) (*RemoveMemberResponse, error) {
+	out := new(RemoveMemberResponse)
+	err := c.cc.Invoke(ctx, Vault_RemoveMember_FullMethodName, in, out, opts...)
+	if err != nil {
 		return nil, err
 	}
-	return m, nil
+	return out, nil
 }
 
-func (c *storageClient) DeleteRecord(ctx context.Context, in *DeleteRecordRequest, opts ...grpc.CallOption) (*DeleteRecordResponse, error) {
-	out := new(DeleteRecordResponse)
-	err := c.cc.Invoke(ctx, Storage_DeleteRecord_FullMethodName, in, out, opts...)
+func (c *vaultClient) ListVaults(ctx context.Context, in *ListVaultsRequest, opts ...grpc.CallOption) (*ListVaultsResponse, error) {
+	out := new(ListVaultsResponse)
+	err := c.cc.Invoke(ctx, Vault_ListVaults_FullMethodName, in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// StorageServer is the server API for Storage service.
-// All implementations must embed UnimplementedStorageServer
+// VaultServer is the server API for Vault service.
+// All implementations must embed UnimplementedVaultServer
 // for forward compatibility
-type StorageServer interface {
-	ReadRecord(context.Context, *ReadRecordRequest) (*ReadRecordResponse, error)
-	ReadAllRecord(context.Context, *ReadAllRecordRequest) (*ReadAllRecordResponse, error)
-	WriteRecord(Storage_WriteRecordServer) error
-	DeleteRecord(context.Context, *DeleteRecordRequest) (*DeleteRecordResponse, error)
-	mustEmbedUnimplementedStorageServer()
+type VaultServer interface {
+	CreateOrganization(context.Context, *CreateOrganizationRequest) (*CreateOrganizationResponse, error)
+	CreateVault(context.Context, *CreateVaultRequest) (*CreateVaultResponse, error)
+	AddMember(context.Context, *AddMemberRequest) (*AddMemberResponse, error)
+	RemoveMember(context.Context, *RemoveMemberRequest) (*RemoveMemberResponse, error)
+	ListVaults(context.Context, *ListVaultsRequest) (*ListVaultsResponse, error)
+	mustEmbedUnimplementedVaultServer()
 }
 
-// UnimplementedStorageServer must be embedded to have forward compatible implementations.
-type UnimplementedStorageServer struct {
+// UnimplementedVaultServer must be embedded to have forward compatible implementations.
+type UnimplementedVaultServer struct {
 }
 
-func (UnimplementedStorageServer) ReadRecord(context.Context, *ReadRecordRequest) (*ReadRecordResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ReadRecord not implemented")
+func (UnimplementedVaultServer) CreateOrganization(context.Context, *CreateOrganizationRequest) (*CreateOrganizationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateOrganization not implemented")
 }
-func (UnimplementedStorageServer) ReadAllRecord(context.Context, *ReadAllRecordRequest) (*ReadAllRecordResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ReadAllRecord not implemented")
+func (UnimplementedVaultServer) CreateVault(context.Context, *CreateVaultRequest) (*CreateVaultResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateVault not implemented")
 }
-func (UnimplementedStorageServer) WriteRecord(Storage_WriteRecordServer) error {
-	return status.Errorf(codes.Unimplemented, "method WriteRecord not implemented")
+func (UnimplementedVaultServer) AddMember(context.Context, *AddMemberRequest) (*AddMemberResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddMember not implemented")
 }
-func (UnimplementedStorageServer) DeleteRecord(context.Context, *DeleteRecordRequest) (*DeleteRecordResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method DeleteRecord not implemented")
+func (UnimplementedVaultServer) RemoveMember(context.Context, *RemoveMemberRequest) (*RemoveMemberResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveMember not implemented")
 }
-func (UnimplementedStorageServer) mustEmbedUnimplementedStorageServer() {}
+func (UnimplementedVaultServer) ListVaults(context.Context, *ListVaultsRequest) (*ListVaultsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListVaults not implemented")
+}
+func (UnimplementedVaultServer) mustEmbedUnimplementedVaultServer() {}
 
-// UnsafeStorageServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to StorageServer will
+// UnsafeVaultServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to VaultServer will
 // result in compilation errors.
-type UnsafeStorageServer interface {
-	mustEmbedUnimplementedStorageServer()
+type UnsafeVaultServer interface {
+	mustEmbedUnimplementedVaultServer()
 }
 
-func RegisterStorageServer(s grpc.ServiceRegistrar, srv StorageServer) {
-	s.RegisterService(&Storage_ServiceDesc, srv)
+func RegisterVaultServer(s grpc.ServiceRegistrar, srv VaultServer) {
+	s.RegisterService(&Vault_ServiceDesc, srv)
 }
 
-func _Storage_ReadRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ReadRecordRequest)
+func _Vault_CreateOrganization_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateOrganizationRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(StorageServer).ReadRecord(ctx, in)
+		return srv.(VaultServer).CreateOrganization(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: Storage_ReadRecord_FullMethodName,
+		FullMethod: Vault_CreateOrganization_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(StorageServer).ReadRecord(ctx, req.(*ReadRecordRequest))
+		return srv.(VaultServer).CreateOrganization(ctx, req.(*CreateOrganizationRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Storage_ReadAllRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ReadAllRecordRequest)
+func _Vault_CreateVault_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateVaultRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(StorageServer).ReadAllRecord(ctx, in)
+		return srv.(VaultServer).CreateVault(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: Storage_ReadAllRecord_FullMethodName,
+		FullMethod: Vault_CreateVault_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(StorageServer).ReadAllRecord(ctx, req.(*ReadAllRecordRequest))
+		return srv.(VaultServer).CreateVault(ctx, req.(*CreateVaultRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Storage_WriteRecord_Handler(srv interface{}, stream grpc.ServerStream) error {
-	return srv.(StorageServer).WriteRecord(&storageWriteRecordServer{stream})
-}
-
-type Storage_WriteRecordServer interface {
-	SendAndClose(*WriteRecordResponse) error
-	Recv() (*WriteRecordRequest, error)
-	grpc.ServerStream
-}
-
-type storageWriteRecordServer struct {
-	grpc.ServerStream
-}
-
-func (x *storageWriteRecordServer) SendAndClose(m *WriteRecordResponse) error {
-	return x.ServerStream.SendMsg(m)
+func _Vault_AddMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VaultServer).AddMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Vault_AddMember_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VaultServer).AddMember(ctx, req.(*AddMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (x *storageWriteRecordServer) Recv() (*WriteRecordRequest, error) {
-	m := new(WriteRecordRequest)
-	if err := x.ServerStream.RecvMsg(m); err != nil {
+func _Vault_RemoveMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveMemberRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return m, nil
+	if interceptor == nil {
+		return srv.(VaultServer).RemoveMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Vault_RemoveMember_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VaultServer).RemoveMember(ctx, req.(*RemoveMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _Storage_DeleteRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DeleteRecordRequest)
+func _Vault_ListVaults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListVaultsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(StorageServer).DeleteRecord(ctx, in)
+		return srv.(VaultServer).ListVaults(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: Storage_DeleteRecord_FullMethodName,
+		FullMethod: Vault_ListVaults_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(StorageServer).DeleteRecord(ctx, req.(*DeleteRecordRequest))
+		return srv.(VaultServer).ListVaults(ctx, req.(*ListVaultsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-// Storage_ServiceDesc is the grpc.ServiceDesc for Storage service.
+// Vault_ServiceDesc is the grpc.ServiceDesc for Vault service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
-var Storage_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "proto.Storage",
-	HandlerType: (*StorageServer)(nil),
+var Vault_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.Vault",
+	HandlerType: (*VaultServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "ReadRecord",
-			Handler:    _Storage_ReadRecord_Handler,
+			MethodName: "CreateOrganization",
+			Handler:    _Vault_CreateOrganization_Handler,
 		},
 		{
-			MethodName: "ReadAllRecord",
-			Handler:    _Storage_ReadAllRecord_Handler,
+			MethodName: "CreateVault",
+			Handler:    _Vault_CreateVault_Handler,
 		},
 		{
-			MethodName: "DeleteRecord",
-			Handler:    _Storage_DeleteRecord_Handler,
+			MethodName: "AddMember",
+			Handler:    _Vault_AddMember_Handler,
 		},
-	},
-	Streams: []grpc.StreamDesc{
 		{
-			StreamName:    "WriteRecord",
-			Handler:       _Storage_WriteRecord_Handler,
-			ClientStreams: true,
+			MethodName: "RemoveMember",
+			Handler:    _Vault_RemoveMember_Handler,
+		},
+		{
+			MethodName: "ListVaults",
+			Handler:    _Vault_ListVaults_Handler,
 		},
 	},
+	Streams:  []grpc.StreamDesc{},
 	Metadata: "internal/server/core/domain/proto/model.proto",
 }
@@ -10,6 +10,7 @@ import (
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
 	handler "github.com/dedpnd/GophKeeper/internal/server/adapters/handler/grpc"
 	interceptors "github.com/dedpnd/GophKeeper/internal/server/adapters/middleware/grpc"
@@ -18,12 +19,21 @@ import (
 	"github.com/dedpnd/GophKeeper/internal/server/core/services"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/recovery"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/selector"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 )
 
+// eventRetryDelay is the delay before listening to record events again
+// after the connection failed.
+var eventRetryDelay = 5 * time.Second
+
+// trashPurgeInterval is the interval between purges of the records deleted
+// before the retention period.
+var trashPurgeInterval = time.Hour
+
 // RunGRPCserver run gRPC server.
 func RunGRPCserver(
 	lg *zap.Logger,
@@ -32,6 +42,8 @@ func RunGRPCserver(
 	pathKey string,
 	jwtKey string,
 	mk string,
+	legacyAuth bool,
+	trashRetentionDays int,
 	repo *repository.DB,
 ) error {
 	lg.Info("gRPC server start...", zap.String("address", host))
@@ -52,41 +64,60 @@ func RunGRPCserver(
 		logging.WithLogOnEvents(logging.StartCall, logging.FinishCall),
 	}
 
+	// Create services
+	userSvc := services.NewUserService(repo)
+	storageSvc := services.NewStorageService(repo)
+	vaultSvc := services.NewVaultService(repo)
+	eventSvc := services.NewEventService(repo)
+
 	// Create gRPC server
 	s := grpc.NewServer(
 		grpc.Creds(tlsCredentials),
 		grpc.ChainUnaryInterceptor(
 			logging.UnaryServerInterceptor(interceptors.InterceptorLogger(lg), opts...),
+			recovery.UnaryServerInterceptor(recovery.WithRecoveryHandler(interceptors.RecoveryHandler(lg))),
 			selector.UnaryServerInterceptor(
-				auth.UnaryServerInterceptor(interceptors.GetAuthenticator(jwtKey)),
+				auth.UnaryServerInterceptor(interceptors.GetAuthenticator(jwtKey, userSvc)),
 				selector.MatchFunc(interceptors.AuthMatcher),
 			),
+			interceptors.VaultUnaryInterceptor(vaultSvc),
 		),
 		grpc.ChainStreamInterceptor(
 			logging.StreamServerInterceptor(interceptors.InterceptorLogger(lg), opts...),
+			recovery.StreamServerInterceptor(recovery.WithRecoveryHandler(interceptors.RecoveryHandler(lg))),
 			selector.StreamServerInterceptor(
-				auth.StreamServerInterceptor(interceptors.GetAuthenticator(jwtKey)),
+				auth.StreamServerInterceptor(interceptors.GetAuthenticator(jwtKey, userSvc)),
 				selector.MatchFunc(interceptors.AuthMatcher),
 			),
+			interceptors.VaultStreamInterceptor(vaultSvc),
 		),
 	)
 
-	// Create user service
-	userSvc := services.NewUserService(repo)
+	// Register user service
 	proto.RegisterUserServer(s, &handler.UserHandler{
-		Svc:    *userSvc,
-		Logger: lg,
-		JWTkey: jwtKey,
+		Svc:        *userSvc,
+		Logger:     lg,
+		SRP:        handler.NewSRPSessions(),
+		JWTkey:     jwtKey,
+		LegacyAuth: legacyAuth,
 	})
 
-	// Create storage service
-	storageSvc := services.NewStorageService(repo)
+	// Register storage service
 	proto.RegisterStorageServer(s, &handler.StorageHandler{
 		Svc:       *storageSvc,
+		UserSvc:   *userSvc,
+		Events:    eventSvc,
 		Logger:    lg,
 		MasterKey: mk,
 	})
 
+	// Register vault service
+	proto.RegisterVaultServer(s, &handler.VaultHandler{
+		Svc:     *vaultSvc,
+		UserSvc: *userSvc,
+		Logger:  lg,
+	})
+
 	// Graceful server
 	var wg sync.WaitGroup
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
@@ -107,6 +138,12 @@ func RunGRPCserver(
 		<-ctx.Done()
 	}()
 
+	// Listen to record events of all server instances
+	go runEvents(ctx, lg, eventSvc)
+
+	// Purge the trash of all users and vaults
+	go runTrashPurge(ctx, lg, storageSvc, time.Duration(trashRetentionDays)*24*time.Hour)
+
 	// Start gRPC server
 	go func() {
 		if err := s.Serve(listen); err != nil {
@@ -127,6 +164,47 @@ func RunGRPCserver(
 	return nil
 }
 
+// runEvents runs the event service until the context is done. If the
+// connection fails, the service listens again after a delay.
+func runEvents(ctx context.Context, lg *zap.Logger, svc *services.EventService) {
+	for ctx.Err() == nil {
+		err := svc.Run(ctx)
+		if err == nil {
+			continue
+		}
+
+		lg.With(zap.Error(err)).Error("failed listen record events")
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(eventRetryDelay):
+		}
+	}
+}
+
+// runTrashPurge permanently removes the records deleted before the retention
+// period, at the start and then every `trashPurgeInterval` until the context
+// is done.
+func runTrashPurge(ctx context.Context, lg *zap.Logger, svc *services.StorageService, retention time.Duration) {
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		count, err := svc.PurgeTrash(time.Now().Add(-retention))
+		if err != nil {
+			lg.With(zap.Error(err)).Error("failed purge trash")
+		} else if count != 0 {
+			lg.Info("trash purged", zap.Int64("records", count))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // loadTLSCredentials loading cert.
 func loadTLSCredentials(cert string, key string) (credentials.TransportCredentials, error) {
 	// Load server's certificate and private key
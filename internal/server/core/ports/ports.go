@@ -4,20 +4,99 @@
 // storage repositories for `User` and `Storage` domain entities.
 package ports
 
-import "github.com/dedpnd/GophKeeper/internal/server/core/domain"
+import (
+	"context"
+	"time"
+
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+)
 
 // UserRepository represents the interface for user-related data storage.
-// It provides methods for finding a user by login and creating a new user.
+// It provides methods for finding a user by login and creating a new user,
+// as well as managing the user's personal access tokens and emergency contacts.
 type UserRepository interface {
 	FindUserByLogin(login string) (*domain.User, error)
+	FindUserByID(id int) (*domain.User, error)
 	CreateUser(login, hash string) (*domain.User, error)
+	CreateUserWithVerifier(login string, salt, verifier []byte) (*domain.User, error)
+	SetVerifier(id int, salt, verifier []byte) error
+	CreateAccessToken(token domain.AccessToken) (*domain.AccessToken, error)
+	FindAccessTokenByHash(hash string) (*domain.AccessToken, error)
+	ListAccessTokens(owner int) ([]*domain.AccessToken, error)
+	RevokeAccessToken(id int, owner int) (bool, error)
+	SaveEmergencyContact(contact domain.EmergencyContact) (*domain.EmergencyContact, error)
+	FindEmergencyContact(grantor int, grantee int) (*domain.EmergencyContact, error)
+	ListEmergencyContacts(user int) ([]*domain.EmergencyContact, error)
+	UpdateEmergencyStatus(contact domain.EmergencyContact, from string) (bool, error)
+	DeleteEmergencyContact(grantor int, grantee int) error
 }
 
 // StorageRepository represents the interface for storage-related data storage.
-// It provides methods for reading, writing, and deleting storage records.
+// It provides methods for reading, finding by name, searching, writing, and deleting storage records,
+// organizing records in folders and with tags, sharing records with other users,
+// synchronizing changes of records and resolving conflicting versions.
 type StorageRepository interface {
 	ReadRecord(id int, owner int) (*domain.Storage, error)
-	ReadAllRecord(owner int) ([]*domain.Storage, error)
+	ReadAllRecord(owner int, page domain.RecordPage) ([]*domain.Storage, error)
+	FindRecordByName(owner int, name string, prefix bool, page domain.RecordPage) ([]*domain.Storage, error)
+	FindVaultRecordByName(vault int, name string, prefix bool, page domain.RecordPage) ([]*domain.Storage, error)
+	SearchRecord(owner int, filter domain.RecordFilter) ([]*domain.Storage, error)
+	SearchVaultRecord(vault int, filter domain.RecordFilter) ([]*domain.Storage, error)
+	CreateFolder(folder domain.Folder) (*domain.Folder, error)
+	ListFolders(owner int) ([]*domain.Folder, error)
+	RenameFolder(id int, owner int, name string) (bool, error)
+	DeleteFolder(id int, owner int) (bool, error)
+	MoveRecord(id int, owner int, folder int) (bool, error)
+	TagRecord(id int, owner int, tags []string) error
+	UntagRecord(id int, owner int, tags []string) error
 	WriteRecord(doc domain.Storage) error
+	UpdateRecord(doc domain.Storage, revision int64) (bool, error)
 	DeleteRecord(id int, owner int) error
+	ReadAllVaultRecord(vault int, page domain.RecordPage) ([]*domain.Storage, error)
+	ReadVaultRecord(id int, vault int) (*domain.Storage, error)
+	DeleteVaultRecord(id int, vault int) error
+	ListTrash(owner int) ([]*domain.Storage, error)
+	ListVaultTrash(vault int) ([]*domain.Storage, error)
+	RestoreRecord(id int, owner int) (bool, error)
+	RestoreVaultRecord(id int, vault int) (bool, error)
+	EmptyTrash(owner int) (int64, error)
+	EmptyVaultTrash(vault int) (int64, error)
+	PurgeTrash(before time.Time) (int64, error)
+	ListDueRecords(owner int, before time.Time) ([]*domain.Storage, error)
+	ListVaultDueRecords(vault int, before time.Time) ([]*domain.Storage, error)
+	SetRecordExpiry(id int, expiresAt *time.Time, rotateDays int) error
+	NextOTPCounter(id int) (int64, bool, error)
+	ShareRecord(share domain.Share) error
+	RevokeShare(id int, grantee int, owner int) error
+	ListSharedWithMe(grantee int) ([]*domain.Storage, error)
+	SyncRecord(owner int, vault int, cursor int64) ([]*domain.Storage, int64, error)
+	CreateConflict(conflict domain.Conflict) error
+	ListConflicts(recordID int) ([]*domain.Conflict, error)
+	ResolveConflict(doc domain.Storage, revision int64, conflicts []int) (bool, error)
+}
+
+// VaultRepository represents the interface for organization-related data storage.
+// It provides methods for managing organizations, their vaults and members.
+type VaultRepository interface {
+	CreateOrganization(name string, owner int) (*domain.Organization, error)
+	CreateVault(vault domain.Vault) (*domain.Vault, error)
+	FindVault(id int) (*domain.Vault, error)
+	FindMember(orgID int, userID int) (*domain.Member, error)
+	AddMember(member domain.Member) (bool, error)
+	RemoveMember(orgID int, userID int) (bool, error)
+	ListVaults(userID int) ([]*domain.Vault, error)
+}
+
+// EventRepository represents the interface for the notifications about
+// changes of storage records. It provides methods for listening to them and
+// reading the details of the changed records.
+type EventRepository interface {
+	ListenRecordEvents(ctx context.Context) (RecordEventListener, error)
+	ResolveRecordEvent(event domain.RecordEvent) (domain.RecordEvent, error)
+}
+
+// RecordEventListener receives the record events of all server instances.
+type RecordEventListener interface {
+	WaitRecordEvent(ctx context.Context) (*domain.RecordEvent, error)
+	Close(ctx context.Context) error
 }
@@ -0,0 +1,123 @@
+// Package services contains the application services that implement
+// business logic using the repository interfaces defined in the
+// `ports` package. These services serve as an intermediary layer
+// between the domain logic and the data layer, providing methods
+// for operations such as finding, creating, updating, and deleting
+// users and storage records.
+//
+//nolint:wrapcheck // This legal return
+package services
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"github.com/dedpnd/GophKeeper/internal/server/core/ports"
+)
+
+// eventBuffer is the number of events kept for a slow watcher.
+var eventBuffer = 64
+
+// EventService represents a service for record events. It receives the
+// events of all server instances with the `EventRepository` interface and
+// fans them out to the watchers connected to this instance.
+type EventService struct {
+	repo      ports.EventRepository
+	mu        sync.Mutex
+	watchers  map[int]chan domain.RecordEvent
+	next      int
+	ready     chan struct{}
+	readyOnce sync.Once
+}
+
+// NewEventService creates a new instance of `EventService`
+// with the given `EventRepository`.
+func NewEventService(repo ports.EventRepository) *EventService {
+	return &EventService{
+		repo:     repo,
+		watchers: map[int]chan domain.RecordEvent{},
+		ready:    make(chan struct{}),
+	}
+}
+
+// Run listens to the record events and publishes them to the watchers until
+// the context is done or the connection fails. The name, type and grantees of
+// the record are read for every event. Events made while the service is not
+// listening are lost, watchers catch up with `Sync`.
+func (e *EventService) Run(ctx context.Context) error {
+	listener, err := e.repo.ListenRecordEvents(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		// The context may be done already
+		_ = listener.Close(context.Background())
+	}()
+
+	e.readyOnce.Do(func() { close(e.ready) })
+
+	for {
+		event, err := listener.WaitRecordEvent(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		// Notifications carry only the IDs, the details are read here
+		resolved, err := e.repo.ResolveRecordEvent(*event)
+		if err != nil {
+			return err
+		}
+
+		e.publish(resolved)
+	}
+}
+
+// Ready returns a channel which is closed once the service listens to events.
+func (e *EventService) Ready() <-chan struct{} {
+	return e.ready
+}
+
+// Subscribe adds a watcher and returns its ID and the channel of events.
+// The channel is closed if the watcher does not keep up with the events.
+func (e *EventService) Subscribe() (int, <-chan domain.RecordEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.next++
+	ch := make(chan domain.RecordEvent, eventBuffer)
+	e.watchers[e.next] = ch
+
+	return e.next, ch
+}
+
+// Unsubscribe removes the watcher.
+func (e *EventService) Unsubscribe(id int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if ch, ok := e.watchers[id]; ok {
+		close(ch)
+		delete(e.watchers, id)
+	}
+}
+
+// publish sends the event to every watcher. A watcher with a full buffer
+// is removed, so a slow client never blocks the others.
+func (e *EventService) publish(event domain.RecordEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for id, ch := range e.watchers {
+		select {
+		case ch <- event:
+		default:
+			close(ch)
+			delete(e.watchers, id)
+		}
+	}
+}
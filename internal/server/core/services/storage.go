@@ -9,6 +9,8 @@
 package services
 
 import (
+	"time"
+
 	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
 	"github.com/dedpnd/GophKeeper/internal/server/core/ports"
 )
@@ -28,10 +30,10 @@ func NewStorageService(repo ports.StorageRepository) *StorageService {
 	}
 }
 
-// ReadAllRecord retrieves all storage records for the specified owner.
+// ReadAllRecord retrieves a page of storage records for the specified owner.
 // It uses the `ReadAllRecord` method from the `StorageRepository` interface.
-func (s *StorageService) ReadAllRecord(owner int) ([]*domain.Storage, error) {
-	return s.repo.ReadAllRecord(owner)
+func (s *StorageService) ReadAllRecord(owner int, page domain.RecordPage) ([]*domain.Storage, error) {
+	return s.repo.ReadAllRecord(owner, page)
 }
 
 // ReadRecord retrieves a specific storage record by ID and owner.
@@ -40,14 +42,221 @@ func (s *StorageService) ReadRecord(id int, owner int) (*domain.Storage, error)
 	return s.repo.ReadRecord(id, owner)
 }
 
+// FindRecordByName retrieves a page of the records of the owner with the name,
+// or with names starting with it if `prefix` is set.
+// It uses the `FindRecordByName` method from the `StorageRepository` interface.
+func (s *StorageService) FindRecordByName(owner int, name string, prefix bool,
+	page domain.RecordPage) ([]*domain.Storage, error) {
+	return s.repo.FindRecordByName(owner, name, prefix, page)
+}
+
+// FindVaultRecordByName retrieves a page of the records of the team vault with
+// the name, or with names starting with it if `prefix` is set.
+// It uses the `FindVaultRecordByName` method from the `StorageRepository` interface.
+func (s *StorageService) FindVaultRecordByName(vault int, name string, prefix bool,
+	page domain.RecordPage) ([]*domain.Storage, error) {
+	return s.repo.FindVaultRecordByName(vault, name, prefix, page)
+}
+
+// SearchRecord retrieves a page of the records of the owner, including the
+// records shared with the owner, which match the filter.
+// It uses the `SearchRecord` method from the `StorageRepository` interface.
+func (s *StorageService) SearchRecord(owner int, filter domain.RecordFilter) ([]*domain.Storage, error) {
+	return s.repo.SearchRecord(owner, filter)
+}
+
+// SearchVaultRecord retrieves a page of the records of the team vault which
+// match the filter.
+// It uses the `SearchVaultRecord` method from the `StorageRepository` interface.
+func (s *StorageService) SearchVaultRecord(vault int, filter domain.RecordFilter) ([]*domain.Storage, error) {
+	return s.repo.SearchVaultRecord(vault, filter)
+}
+
+// CreateFolder creates a folder of personal records.
+// It uses the `CreateFolder` method from the `StorageRepository` interface.
+func (s *StorageService) CreateFolder(folder domain.Folder) (*domain.Folder, error) {
+	return s.repo.CreateFolder(folder)
+}
+
+// ListFolders retrieves all folders of the owner.
+// It uses the `ListFolders` method from the `StorageRepository` interface.
+func (s *StorageService) ListFolders(owner int) ([]*domain.Folder, error) {
+	return s.repo.ListFolders(owner)
+}
+
+// RenameFolder renames a folder of the owner.
+// It uses the `RenameFolder` method from the `StorageRepository` interface.
+func (s *StorageService) RenameFolder(id int, owner int, name string) (bool, error) {
+	return s.repo.RenameFolder(id, owner, name)
+}
+
+// DeleteFolder deletes a folder of the owner with its subfolders.
+// It uses the `DeleteFolder` method from the `StorageRepository` interface.
+func (s *StorageService) DeleteFolder(id int, owner int) (bool, error) {
+	return s.repo.DeleteFolder(id, owner)
+}
+
+// MoveRecord moves a personal record of the owner to a folder.
+// It uses the `MoveRecord` method from the `StorageRepository` interface.
+func (s *StorageService) MoveRecord(id int, owner int, folder int) (bool, error) {
+	return s.repo.MoveRecord(id, owner, folder)
+}
+
+// TagRecord labels a personal record of the owner with tags.
+// It uses the `TagRecord` method from the `StorageRepository` interface.
+func (s *StorageService) TagRecord(id int, owner int, tags []string) error {
+	return s.repo.TagRecord(id, owner, tags)
+}
+
+// UntagRecord removes tags from a personal record of the owner.
+// It uses the `UntagRecord` method from the `StorageRepository` interface.
+func (s *StorageService) UntagRecord(id int, owner int, tags []string) error {
+	return s.repo.UntagRecord(id, owner, tags)
+}
+
 // WriteRecord adds a new storage record.
 // It uses the `WriteRecord` method from the `StorageRepository` interface.
 func (s *StorageService) WriteRecord(doc domain.Storage) error {
 	return s.repo.WriteRecord(doc)
 }
 
+// UpdateRecord replaces the value of an existing storage record if it still
+// has the revision, false means the record was changed by another write.
+// It uses the `UpdateRecord` method from the `StorageRepository` interface.
+func (s *StorageService) UpdateRecord(doc domain.Storage, revision int64) (bool, error) {
+	return s.repo.UpdateRecord(doc, revision)
+}
+
 // DeleteRecord removes a storage record by ID and owner.
 // It uses the `DeleteRecord` method from the `StorageRepository` interface.
 func (s *StorageService) DeleteRecord(id int, owner int) error {
 	return s.repo.DeleteRecord(id, owner)
 }
+
+// ReadAllVaultRecord retrieves a page of storage records of a team vault.
+// It uses the `ReadAllVaultRecord` method from the `StorageRepository` interface.
+func (s *StorageService) ReadAllVaultRecord(vault int, page domain.RecordPage) ([]*domain.Storage, error) {
+	return s.repo.ReadAllVaultRecord(vault, page)
+}
+
+// ReadVaultRecord retrieves a specific storage record of a team vault.
+// It uses the `ReadVaultRecord` method from the `StorageRepository` interface.
+func (s *StorageService) ReadVaultRecord(id int, vault int) (*domain.Storage, error) {
+	return s.repo.ReadVaultRecord(id, vault)
+}
+
+// DeleteVaultRecord removes a storage record of a team vault.
+// It uses the `DeleteVaultRecord` method from the `StorageRepository` interface.
+func (s *StorageService) DeleteVaultRecord(id int, vault int) error {
+	return s.repo.DeleteVaultRecord(id, vault)
+}
+
+// ListTrash retrieves the deleted personal records of the owner.
+// It uses the `ListTrash` method from the `StorageRepository` interface.
+func (s *StorageService) ListTrash(owner int) ([]*domain.Storage, error) {
+	return s.repo.ListTrash(owner)
+}
+
+// ListVaultTrash retrieves the deleted records of a team vault.
+// It uses the `ListVaultTrash` method from the `StorageRepository` interface.
+func (s *StorageService) ListVaultTrash(vault int) ([]*domain.Storage, error) {
+	return s.repo.ListVaultTrash(vault)
+}
+
+// RestoreRecord moves a personal record of the owner back from the trash.
+// It uses the `RestoreRecord` method from the `StorageRepository` interface.
+func (s *StorageService) RestoreRecord(id int, owner int) (bool, error) {
+	return s.repo.RestoreRecord(id, owner)
+}
+
+// RestoreVaultRecord moves a record of a team vault back from the trash.
+// It uses the `RestoreVaultRecord` method from the `StorageRepository` interface.
+func (s *StorageService) RestoreVaultRecord(id int, vault int) (bool, error) {
+	return s.repo.RestoreVaultRecord(id, vault)
+}
+
+// EmptyTrash permanently removes the values of the deleted personal records of the owner.
+// It uses the `EmptyTrash` method from the `StorageRepository` interface.
+func (s *StorageService) EmptyTrash(owner int) (int64, error) {
+	return s.repo.EmptyTrash(owner)
+}
+
+// EmptyVaultTrash permanently removes the values of the deleted records of a team vault.
+// It uses the `EmptyVaultTrash` method from the `StorageRepository` interface.
+func (s *StorageService) EmptyVaultTrash(vault int) (int64, error) {
+	return s.repo.EmptyVaultTrash(vault)
+}
+
+// PurgeTrash permanently removes the values of the records deleted before the time.
+// It uses the `PurgeTrash` method from the `StorageRepository` interface.
+func (s *StorageService) PurgeTrash(before time.Time) (int64, error) {
+	return s.repo.PurgeTrash(before)
+}
+
+// ListDueRecords retrieves the personal and shared records of the owner due before the time.
+// It uses the `ListDueRecords` method from the `StorageRepository` interface.
+func (s *StorageService) ListDueRecords(owner int, before time.Time) ([]*domain.Storage, error) {
+	return s.repo.ListDueRecords(owner, before)
+}
+
+// ListVaultDueRecords retrieves the records of a team vault due before the time.
+// It uses the `ListVaultDueRecords` method from the `StorageRepository` interface.
+func (s *StorageService) ListVaultDueRecords(vault int, before time.Time) ([]*domain.Storage, error) {
+	return s.repo.ListVaultDueRecords(vault, before)
+}
+
+// SetRecordExpiry replaces the expiry date and the rotation interval of a storage record.
+// It uses the `SetRecordExpiry` method from the `StorageRepository` interface.
+func (s *StorageService) SetRecordExpiry(id int, expiresAt *time.Time, rotateDays int) error {
+	return s.repo.SetRecordExpiry(id, expiresAt, rotateDays)
+}
+
+// NextOTPCounter takes the next HOTP counter of an OTP record.
+// It uses the `NextOTPCounter` method from the `StorageRepository` interface.
+func (s *StorageService) NextOTPCounter(id int) (int64, bool, error) {
+	return s.repo.NextOTPCounter(id)
+}
+
+// ShareRecord grants another user access to a storage record.
+// It uses the `ShareRecord` method from the `StorageRepository` interface.
+func (s *StorageService) ShareRecord(share domain.Share) error {
+	return s.repo.ShareRecord(share)
+}
+
+// RevokeShare removes the access of another user to a storage record.
+// It uses the `RevokeShare` method from the `StorageRepository` interface.
+func (s *StorageService) RevokeShare(id int, grantee int, owner int) error {
+	return s.repo.RevokeShare(id, grantee, owner)
+}
+
+// ListSharedWithMe retrieves the storage records shared with the grantee.
+// It uses the `ListSharedWithMe` method from the `StorageRepository` interface.
+func (s *StorageService) ListSharedWithMe(grantee int) ([]*domain.Storage, error) {
+	return s.repo.ListSharedWithMe(grantee)
+}
+
+// SyncRecord retrieves the records changed after the cursor, including tombstones,
+// and the cursor of the next call.
+// It uses the `SyncRecord` method from the `StorageRepository` interface.
+func (s *StorageService) SyncRecord(owner int, vault int, cursor int64) ([]*domain.Storage, int64, error) {
+	return s.repo.SyncRecord(owner, vault, cursor)
+}
+
+// CreateConflict stores a version of the record written concurrently with the current one.
+// It uses the `CreateConflict` method from the `StorageRepository` interface.
+func (s *StorageService) CreateConflict(conflict domain.Conflict) error {
+	return s.repo.CreateConflict(conflict)
+}
+
+// ListConflicts retrieves the conflicting versions of the record.
+// It uses the `ListConflicts` method from the `StorageRepository` interface.
+func (s *StorageService) ListConflicts(recordID int) ([]*domain.Conflict, error) {
+	return s.repo.ListConflicts(recordID)
+}
+
+// ResolveConflict replaces the record with the picked version and removes the resolved conflicting
+// versions, false means the record was changed by another write.
+// It uses the `ResolveConflict` method from the `StorageRepository` interface.
+func (s *StorageService) ResolveConflict(doc domain.Storage, revision int64, conflicts []int) (bool, error) {
+	return s.repo.ResolveConflict(doc, revision, conflicts)
+}
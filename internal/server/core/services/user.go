@@ -38,3 +38,76 @@ func (u *UserService) FindUserByLogin(login string) (*domain.User, error) {
 func (u *UserService) CreateUser(login, hash string) (*domain.User, error) {
 	return u.repo.CreateUser(login, hash)
 }
+
+// CreateUserWithVerifier creates a new user with the given SRP salt and verifier.
+// It uses the `CreateUserWithVerifier` method from the `UserRepository` interface.
+func (u *UserService) CreateUserWithVerifier(login string, salt, verifier []byte) (*domain.User, error) {
+	return u.repo.CreateUserWithVerifier(login, salt, verifier)
+}
+
+// SetVerifier replaces the user's credentials with the SRP salt and verifier.
+// It uses the `SetVerifier` method from the `UserRepository` interface.
+func (u *UserService) SetVerifier(id int, salt, verifier []byte) error {
+	return u.repo.SetVerifier(id, salt, verifier)
+}
+
+// FindUserByID retrieves a user by their ID.
+// It uses the `FindUserByID` method from the `UserRepository` interface.
+func (u *UserService) FindUserByID(id int) (*domain.User, error) {
+	return u.repo.FindUserByID(id)
+}
+
+// CreateAccessToken stores a new personal access token.
+// It uses the `CreateAccessToken` method from the `UserRepository` interface.
+func (u *UserService) CreateAccessToken(token domain.AccessToken) (*domain.AccessToken, error) {
+	return u.repo.CreateAccessToken(token)
+}
+
+// FindAccessTokenByHash retrieves a personal access token by its hash.
+// It uses the `FindAccessTokenByHash` method from the `UserRepository` interface.
+func (u *UserService) FindAccessTokenByHash(hash string) (*domain.AccessToken, error) {
+	return u.repo.FindAccessTokenByHash(hash)
+}
+
+// ListAccessTokens retrieves all personal access tokens of the owner.
+// It uses the `ListAccessTokens` method from the `UserRepository` interface.
+func (u *UserService) ListAccessTokens(owner int) ([]*domain.AccessToken, error) {
+	return u.repo.ListAccessTokens(owner)
+}
+
+// RevokeAccessToken revokes a personal access token of the owner, false
+// means the owner has no token with the ID.
+// It uses the `RevokeAccessToken` method from the `UserRepository` interface.
+func (u *UserService) RevokeAccessToken(id int, owner int) (bool, error) {
+	return u.repo.RevokeAccessToken(id, owner)
+}
+
+// SaveEmergencyContact nominates the grantee as an emergency contact of the grantor.
+// It uses the `SaveEmergencyContact` method from the `UserRepository` interface.
+func (u *UserService) SaveEmergencyContact(contact domain.EmergencyContact) (*domain.EmergencyContact, error) {
+	return u.repo.SaveEmergencyContact(contact)
+}
+
+// FindEmergencyContact retrieves the nomination of the grantee by the grantor.
+// It uses the `FindEmergencyContact` method from the `UserRepository` interface.
+func (u *UserService) FindEmergencyContact(grantor int, grantee int) (*domain.EmergencyContact, error) {
+	return u.repo.FindEmergencyContact(grantor, grantee)
+}
+
+// ListEmergencyContacts retrieves the emergency contacts of the user in both directions.
+// It uses the `ListEmergencyContacts` method from the `UserRepository` interface.
+func (u *UserService) ListEmergencyContacts(user int) ([]*domain.EmergencyContact, error) {
+	return u.repo.ListEmergencyContacts(user)
+}
+
+// UpdateEmergencyStatus moves the emergency contact from the given status to a new one.
+// It uses the `UpdateEmergencyStatus` method from the `UserRepository` interface.
+func (u *UserService) UpdateEmergencyStatus(contact domain.EmergencyContact, from string) (bool, error) {
+	return u.repo.UpdateEmergencyStatus(contact, from)
+}
+
+// DeleteEmergencyContact removes the nomination of the grantee by the grantor.
+// It uses the `DeleteEmergencyContact` method from the `UserRepository` interface.
+func (u *UserService) DeleteEmergencyContact(grantor int, grantee int) error {
+	return u.repo.DeleteEmergencyContact(grantor, grantee)
+}
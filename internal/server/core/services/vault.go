@@ -0,0 +1,80 @@
+// Package services contains the application services that implement
+// business logic using the repository interfaces defined in the
+// `ports` package. These services serve as an intermediary layer
+// between the domain logic and the data layer, providing methods
+// for operations such as finding, creating, updating, and deleting
+// users and storage records.
+//
+//nolint:wrapcheck // This legal return
+package services
+
+import (
+	"slices"
+
+	"github.com/dedpnd/GophKeeper/internal/server/core/domain"
+	"github.com/dedpnd/GophKeeper/internal/server/core/ports"
+)
+
+// VaultService represents a service for organization-related operations.
+// It uses the `VaultRepository` interface to interact with the data layer
+// and checks the permissions of organization roles.
+type VaultService struct {
+	repo ports.VaultRepository
+}
+
+// NewVaultService creates a new instance of `VaultService`
+// with the given `VaultRepository`.
+func NewVaultService(repo ports.VaultRepository) *VaultService {
+	return &VaultService{
+		repo: repo,
+	}
+}
+
+// CreateOrganization creates a new organization owned by the user.
+// It uses the `CreateOrganization` method from the `VaultRepository` interface.
+func (v *VaultService) CreateOrganization(name string, owner int) (*domain.Organization, error) {
+	return v.repo.CreateOrganization(name, owner)
+}
+
+// CreateVault adds a new vault to an organization.
+// It uses the `CreateVault` method from the `VaultRepository` interface.
+func (v *VaultService) CreateVault(vault domain.Vault) (*domain.Vault, error) {
+	return v.repo.CreateVault(vault)
+}
+
+// FindVault retrieves a vault by its ID.
+// It uses the `FindVault` method from the `VaultRepository` interface.
+func (v *VaultService) FindVault(id int) (*domain.Vault, error) {
+	return v.repo.FindVault(id)
+}
+
+// FindMember retrieves the membership of the user in the organization.
+// It uses the `FindMember` method from the `VaultRepository` interface.
+func (v *VaultService) FindMember(orgID int, userID int) (*domain.Member, error) {
+	return v.repo.FindMember(orgID, userID)
+}
+
+// AddMember adds the user to the organization or changes the user's role,
+// false means the change would demote the last owner.
+// It uses the `AddMember` method from the `VaultRepository` interface.
+func (v *VaultService) AddMember(member domain.Member) (bool, error) {
+	return v.repo.AddMember(member)
+}
+
+// RemoveMember removes the user from the organization, false means the user
+// is the last owner.
+// It uses the `RemoveMember` method from the `VaultRepository` interface.
+func (v *VaultService) RemoveMember(orgID int, userID int) (bool, error) {
+	return v.repo.RemoveMember(orgID, userID)
+}
+
+// ListVaults retrieves the vaults available to the user.
+// It uses the `ListVaults` method from the `VaultRepository` interface.
+func (v *VaultService) ListVaults(userID int) ([]*domain.Vault, error) {
+	return v.repo.ListVaults(userID)
+}
+
+// RoleAllows reports whether the organization role allows the action.
+func RoleAllows(role string, action string) bool {
+	return slices.Contains(domain.RolePermissions[role], action)
+}
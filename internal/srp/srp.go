@@ -0,0 +1,261 @@
+// Package srp implements the SRP-6a password-authenticated key exchange
+// (RFC 5054) used by the agent and the server. The password never leaves
+// the agent: at registration the agent sends a salt and a verifier, at login
+// both sides prove knowledge of the shared session key to each other.
+//
+// The 2048-bit group from RFC 5054 and SHA-256 are used. The private value x
+// is derived from the password with Argon2id, so that a leaked verifier is
+// expensive to brute force.
+package srp
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// nHex is the 2048-bit safe prime from RFC 5054, appendix A.
+var nHex = "AC6BDB41324A9A9BF166DE5E1389582FAF72B6651987EE07FC3192943DB56050A37329CBB4" +
+	"A099ED8193E0757767A13DD52312AB4B03310DCD7F48A9DA04FD50E8083969EDB767B0CF60" +
+	"95179A163AB3661A05FBD5FAAAE82918A9962F0B93B855F97993EC975EEAA80D740ADBF4FF" +
+	"747359D041D5C33EA71D281E446B14773BCA97B43A23FB801676BD207A436C6481F1D2B907" +
+	"8717461A5B9D32E688F87748544523B524B0D57D5EA77A2775D2ECFA032CFBDBF52FB37861" +
+	"60279004E57AE6AF874E7303CE53299CCC041C7BC308D82A5698F3A8D0C38271AE35F8E9DB" +
+	"FBB694B5C803D89F7AE435DE236D525F54759B65E372FCD68EF20FA7111F9E4AFF73"
+
+var (
+	groupN = mustHex(nHex)
+	groupG = big.NewInt(2)
+	groupK = hashInts(groupN, groupG)
+)
+
+var (
+	sizeSalt    = 16
+	sizePrivate = 32
+
+	argonTime    uint32 = 1
+	argonMemory  uint32 = 64 * 1024
+	argonThreads uint8  = 4
+	argonKeyLen  uint32 = 32
+)
+
+// ErrInvalidProof is returned when the peer failed to prove the knowledge of the session key.
+var ErrInvalidProof = errors.New("invalid srp proof")
+
+// ErrInvalidPublic is returned when the peer sent an unsafe public value.
+var ErrInvalidPublic = errors.New("invalid srp public value")
+
+// NewVerifier generates a random salt and the verifier for the login and
+// password. Only these values are sent to the server at registration.
+func NewVerifier(login, password string) ([]byte, []byte, error) {
+	salt := make([]byte, sizeSalt)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, nil, fmt.Errorf("failed generate salt: %w", err)
+	}
+
+	x := privateKey(salt, login, password)
+	v := new(big.Int).Exp(groupG, x, groupN)
+
+	return salt, pad(v), nil
+}
+
+// Client holds the state of the agent side of one login attempt.
+type Client struct {
+	login    string
+	password string
+	a        *big.Int
+	pubA     *big.Int
+	key      []byte
+	proof    []byte
+}
+
+// NewClient starts a login attempt for the login and password.
+func NewClient(login, password string) (*Client, error) {
+	a, err := randomInt()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		login:    login,
+		password: password,
+		a:        a,
+		pubA:     new(big.Int).Exp(groupG, a, groupN),
+	}, nil
+}
+
+// Public returns the public value A which is sent to the server.
+func (c *Client) Public() []byte {
+	return pad(c.pubA)
+}
+
+// Proof computes the session key from the salt and the server public value B
+// and returns the client proof M1 which is sent to the server.
+func (c *Client) Proof(salt, serverPublic []byte) ([]byte, error) {
+	pubB, err := parsePublic(serverPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	u := hashInts(c.pubA, pubB)
+	if u.Sign() == 0 {
+		return nil, ErrInvalidPublic
+	}
+
+	x := privateKey(salt, c.login, c.password)
+
+	// S = (B - k * g^x) ^ (a + u * x) % N
+	gx := new(big.Int).Exp(groupG, x, groupN)
+	base := new(big.Int).Sub(pubB, new(big.Int).Mul(groupK, gx))
+	base.Mod(base, groupN)
+	exp := new(big.Int).Add(c.a, new(big.Int).Mul(u, x))
+	s := new(big.Int).Exp(base, exp, groupN)
+
+	c.key = hash(pad(s))
+	c.proof = hash(pad(c.pubA), pad(pubB), c.key)
+
+	return c.proof, nil
+}
+
+// Verify checks the server proof M2, so that the agent knows it talks to
+// a server which holds the verifier.
+func (c *Client) Verify(serverProof []byte) error {
+	if c.proof == nil {
+		return ErrInvalidProof
+	}
+
+	exp := hash(pad(c.pubA), c.proof, c.key)
+	if subtle.ConstantTimeCompare(exp, serverProof) != 1 {
+		return ErrInvalidProof
+	}
+
+	return nil
+}
+
+// Server holds the state of the server side of one login attempt.
+type Server struct {
+	pubA  *big.Int
+	pubB  *big.Int
+	key   []byte
+	proof []byte
+}
+
+// NewServer starts a login attempt for the stored verifier and the client public value A.
+func NewServer(verifier, clientPublic []byte) (*Server, error) {
+	pubA, err := parsePublic(clientPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := randomInt()
+	if err != nil {
+		return nil, err
+	}
+
+	v := new(big.Int).SetBytes(verifier)
+
+	// B = (k * v + g^b) % N
+	pubB := new(big.Int).Mul(groupK, v)
+	pubB.Add(pubB, new(big.Int).Exp(groupG, b, groupN))
+	pubB.Mod(pubB, groupN)
+
+	u := hashInts(pubA, pubB)
+	if u.Sign() == 0 {
+		return nil, ErrInvalidPublic
+	}
+
+	// S = (A * v^u) ^ b % N
+	base := new(big.Int).Mul(pubA, new(big.Int).Exp(v, u, groupN))
+	base.Mod(base, groupN)
+	s := new(big.Int).Exp(base, b, groupN)
+
+	key := hash(pad(s))
+
+	return &Server{
+		pubA:  pubA,
+		pubB:  pubB,
+		key:   key,
+		proof: hash(pad(pubA), pad(pubB), key),
+	}, nil
+}
+
+// Public returns the public value B which is sent to the agent.
+func (s *Server) Public() []byte {
+	return pad(s.pubB)
+}
+
+// Verify checks the client proof M1 and returns the server proof M2.
+func (s *Server) Verify(clientProof []byte) ([]byte, error) {
+	if subtle.ConstantTimeCompare(s.proof, clientProof) != 1 {
+		return nil, ErrInvalidProof
+	}
+
+	return hash(pad(s.pubA), s.proof, s.key), nil
+}
+
+// parsePublic parses a public value of the peer. Values which are zero
+// modulo N are unsafe, values not less than N cannot be padded to the length
+// of N, so both are rejected.
+func parsePublic(b []byte) (*big.Int, error) {
+	if len(b) > len(groupN.Bytes()) {
+		return nil, ErrInvalidPublic
+	}
+
+	v := new(big.Int).SetBytes(b)
+	if v.Sign() == 0 || v.Cmp(groupN) >= 0 {
+		return nil, ErrInvalidPublic
+	}
+
+	return v, nil
+}
+
+// privateKey derives x = H(salt | Argon2id(login:password, salt)).
+func privateKey(salt []byte, login, password string) *big.Int {
+	stretched := argon2.IDKey([]byte(login+":"+password), salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+	return new(big.Int).SetBytes(hash(salt, stretched))
+}
+
+// randomInt returns a random private value.
+func randomInt() (*big.Int, error) {
+	b := make([]byte, sizePrivate)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed generate private value: %w", err)
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
+
+// pad returns the value padded to the length of N.
+func pad(v *big.Int) []byte {
+	b := make([]byte, len(groupN.Bytes()))
+	return v.FillBytes(b)
+}
+
+// hash returns SHA-256 of the concatenated values.
+func hash(values ...[]byte) []byte {
+	h := sha256.New()
+	for _, v := range values {
+		h.Write(v)
+	}
+
+	return h.Sum(nil)
+}
+
+// hashInts returns H(PAD(a) | PAD(b)) as an integer.
+func hashInts(a, b *big.Int) *big.Int {
+	return new(big.Int).SetBytes(hash(pad(a), pad(b)))
+}
+
+func mustHex(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("srp: invalid group prime")
+	}
+
+	return v
+}
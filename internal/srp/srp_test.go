@@ -0,0 +1,83 @@
+package srp
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogin(t *testing.T) {
+	salt, verifier, err := NewVerifier("test", "secret")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		password string
+		err      error
+	}{
+		{name: "Correct password must login", password: "secret"},
+		{name: "Wrong password must be rejected", password: "wrong", err: ErrInvalidProof},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient("test", tt.password)
+			assert.NoError(t, err)
+
+			server, err := NewServer(verifier, client.Public())
+			assert.NoError(t, err)
+
+			m1, err := client.Proof(salt, server.Public())
+			assert.NoError(t, err)
+
+			m2, err := server.Verify(m1)
+			assert.ErrorIs(t, err, tt.err)
+			if tt.err != nil {
+				return
+			}
+
+			assert.NoError(t, client.Verify(m2))
+		})
+	}
+}
+
+func TestInvalidPublic(t *testing.T) {
+	salt, verifier, err := NewVerifier("test", "secret")
+	assert.NoError(t, err)
+
+	size := len(groupN.Bytes())
+	oversized := make([]byte, size+1)
+	oversized[0] = 1
+
+	tests := []struct {
+		name   string
+		public []byte
+	}{
+		{name: "Empty value", public: nil},
+		{name: "Zero value", public: make([]byte, size)},
+		{name: "Value equal to N", public: groupN.Bytes()},
+		{name: "Value greater than N", public: new(big.Int).Add(groupN, big.NewInt(1)).Bytes()},
+		{name: "Multiple of N", public: new(big.Int).Lsh(groupN, 1).Bytes()},
+		{name: "Value longer than N", public: oversized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" must be rejected by server", func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				_, err := NewServer(verifier, tt.public)
+				assert.ErrorIs(t, err, ErrInvalidPublic)
+			})
+		})
+
+		t.Run(tt.name+" must be rejected by client", func(t *testing.T) {
+			client, err := NewClient("test", "secret")
+			assert.NoError(t, err)
+
+			assert.NotPanics(t, func() {
+				_, err := client.Proof(salt, tt.public)
+				assert.ErrorIs(t, err, ErrInvalidPublic)
+			})
+		})
+	}
+}